@@ -4,6 +4,8 @@ type Kompose struct {
 	suppressWarnings bool
 	verbose          bool
 	errorOnWarning   bool
+	// loader is the input format of the files, detected from the files when empty
+	loader string
 }
 
 func NewClient(opts ...Opt) (*Kompose, error) {
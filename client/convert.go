@@ -5,6 +5,8 @@ import (
 
 	"github.com/kubernetes/kompose/pkg/app"
 	"github.com/kubernetes/kompose/pkg/kobject"
+	"github.com/kubernetes/kompose/pkg/transformer"
+	"github.com/kubernetes/kompose/pkg/validation"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -15,40 +17,59 @@ func (k *Kompose) Convert(options ConvertOptions) ([]runtime.Object, error) {
 		return nil, err
 	}
 	kobjectConvertOptions := kobject.ConvertOptions{
-		ToStdout:                    options.ToStdout,
-		CreateChart:                 k.createChart(options),
-		GenerateYaml:                true,
-		GenerateJSON:                options.GenerateJson,
-		Replicas:                    *options.Replicas,
-		InputFiles:                  options.InputFiles,
-		OutFile:                     options.OutFile,
-		Provider:                    k.getProvider(options),
-		CreateD:                     k.createDeployment(options),
-		CreateDS:                    k.createDaemonSet(options),
-		CreateRC:                    k.createReplicationController(options),
-		Build:                       *options.Build,
-		BuildRepo:                   k.buildRepo(options),
-		BuildBranch:                 k.buildBranch(options),
-		PushImage:                   options.PushImage,
-		PushImageRegistry:           options.PushImageRegistry,
-		CreateDeploymentConfig:      k.createDeploymentConfig(options),
-		EmptyVols:                   false,
-		Volumes:                     *options.VolumeType,
-		PVCRequestSize:              options.PvcRequestSize,
-		InsecureRepository:          k.insecureRepository(options),
-		IsDeploymentFlag:            k.createDeployment(options),
-		IsDaemonSetFlag:             k.createDaemonSet(options),
-		IsReplicationControllerFlag: k.createReplicationController(options),
-		Controller:                  k.getController(options),
-		IsReplicaSetFlag:            *options.Replicas != 0,
-		IsDeploymentConfigFlag:      k.createDeploymentConfig(options),
-		YAMLIndent:                  2,
-		WithKomposeAnnotation:       *options.WithKomposeAnnotations,
-		MultipleContainerMode:       k.multiContainerMode(options),
-		ServiceGroupMode:            k.serviceGroupMode(options),
-		ServiceGroupName:            k.serviceGroupName(options),
-		SecretsAsFiles:              k.secretsAsFiles(options),
-		GenerateNetworkPolicies:     options.GenerateNetworkPolicies,
+		ToStdout:                     options.ToStdout,
+		CreateChart:                  k.createChart(options),
+		GenerateYaml:                 true,
+		GenerateJSON:                 options.GenerateJson,
+		Replicas:                     *options.Replicas,
+		InputFiles:                   options.InputFiles,
+		InputFormat:                  k.loader,
+		OutFile:                      options.OutFile,
+		Provider:                     k.getProvider(options),
+		CreateD:                      k.createDeployment(options),
+		CreateDS:                     k.createDaemonSet(options),
+		CreateRC:                     k.createReplicationController(options),
+		Build:                        *options.Build,
+		BuildRepo:                    k.buildRepo(options),
+		BuildBranch:                  k.buildBranch(options),
+		PushImage:                    options.PushImage,
+		PushImageRegistry:            options.PushImageRegistry,
+		CreateDeploymentConfig:       k.createDeploymentConfig(options),
+		EmptyVols:                    false,
+		Volumes:                      *options.VolumeType,
+		PVCRequestSize:               options.PvcRequestSize,
+		InsecureRepository:           k.insecureRepository(options),
+		IsDeploymentFlag:             k.createDeployment(options),
+		IsDaemonSetFlag:              k.createDaemonSet(options),
+		IsReplicationControllerFlag:  k.createReplicationController(options),
+		Controller:                   k.getController(options),
+		IsReplicaSetFlag:             *options.Replicas != 0,
+		IsDeploymentConfigFlag:       k.createDeploymentConfig(options),
+		YAMLIndent:                   2,
+		WithKomposeAnnotation:        *options.WithKomposeAnnotations,
+		MultipleContainerMode:        k.multiContainerMode(options),
+		ServiceGroupMode:             k.serviceGroupMode(options),
+		ServiceGroupName:             k.serviceGroupName(options),
+		SecretsAsFiles:               k.secretsAsFiles(options),
+		GenerateNetworkPolicies:      options.GenerateNetworkPolicies,
+		GeneratePodDisruptionBudgets: options.GeneratePodDisruptionBudgets,
+		ExposeMode:                   k.exposeMode(options),
+		Gateway:                      k.gateway(options),
+		ChartName:                    k.chartMetadata(options).ChartName,
+		ChartVersion:                 k.chartMetadata(options).ChartVersion,
+		AppVersion:                   k.chartMetadata(options).AppVersion,
+		ChartDescription:             k.chartMetadata(options).ChartDescription,
+		ChartPackage:                 k.chartMetadata(options).ChartPackage,
+		NumberedFiles:                options.NumberedFiles,
+		StableOutput:                 options.StableOutput,
+		Layout:                       string(options.Layout),
+		Validate:                     options.Validate,
+		MinKubernetesVersion:         k.minKubernetesVersion(options),
+		Merge:                        options.Merge,
+		Patches:                      options.Patches,
+		Plugins:                      options.Plugins,
+		Labels:                       options.Labels,
+		Annotations:                  options.Annotations,
 	}
 	err = app.ValidateComposeFile(&kobjectConvertOptions)
 	if err != nil {
@@ -92,6 +113,13 @@ func (k *Kompose) setDefaultValues(options ConvertOptions) ConvertOptions {
 				ServiceGroupMode:   options.Provider.(Kubernetes).ServiceGroupMode,
 				ServiceGroupName:   options.Provider.(Kubernetes).ServiceGroupName,
 				SecretsAsFiles:     options.Provider.(Kubernetes).SecretsAsFiles,
+				ExposeMode:         options.Provider.(Kubernetes).ExposeMode,
+				Gateway:            options.Provider.(Kubernetes).Gateway,
+				ChartName:          options.Provider.(Kubernetes).ChartName,
+				ChartVersion:       options.Provider.(Kubernetes).ChartVersion,
+				AppVersion:         options.Provider.(Kubernetes).AppVersion,
+				ChartDescription:   options.Provider.(Kubernetes).ChartDescription,
+				ChartPackage:       options.Provider.(Kubernetes).ChartPackage,
 			}
 		}
 		if kubernetesProvider.ServiceGroupMode == nil {
@@ -102,6 +130,13 @@ func (k *Kompose) setDefaultValues(options ConvertOptions) ConvertOptions {
 				ServiceGroupMode:   &kubernetesServiceGroupModeDefaultValue,
 				ServiceGroupName:   options.Provider.(Kubernetes).ServiceGroupName,
 				SecretsAsFiles:     options.Provider.(Kubernetes).SecretsAsFiles,
+				ExposeMode:         options.Provider.(Kubernetes).ExposeMode,
+				Gateway:            options.Provider.(Kubernetes).Gateway,
+				ChartName:          options.Provider.(Kubernetes).ChartName,
+				ChartVersion:       options.Provider.(Kubernetes).ChartVersion,
+				AppVersion:         options.Provider.(Kubernetes).AppVersion,
+				ChartDescription:   options.Provider.(Kubernetes).ChartDescription,
+				ChartPackage:       options.Provider.(Kubernetes).ChartPackage,
 			}
 		}
 	}
@@ -123,11 +158,39 @@ func (k *Kompose) validateOptions(options ConvertOptions) error {
 		)
 	}
 
+	if options.NumberedFiles && options.ToStdout {
+		return fmt.Errorf("numbered files only apply to files written to a directory and cannot be printed to stdout")
+	}
+
+	if options.Validate {
+		if _, err := validation.ParseKubernetesVersion(k.minKubernetesVersion(options)); err != nil {
+			return err
+		}
+	}
+
+	if options.Layout != FLAT && options.Layout != PER_SERVICE && options.Layout != "" {
+		return fmt.Errorf(
+			"unexpected Value for Layout field. Possible values are: %v, %v, ''", string(FLAT), string(PER_SERVICE),
+		)
+	}
+
+	if options.Layout == PER_SERVICE && options.ToStdout {
+		return fmt.Errorf("the per-service layout writes a directory and cannot be printed to stdout")
+	}
+
+	if options.Merge && (options.ToStdout || options.OutFile == "") {
+		return fmt.Errorf("merge keeps the changes made to the manifests of OutFile and cannot be printed to stdout")
+	}
+
+	if err := transformer.ValidateCommonMetadata(options.Labels, options.Annotations); err != nil {
+		return err
+	}
+
 	if kubernetesProvider, ok := options.Provider.(Kubernetes); ok {
 		kubernetesController := kubernetesProvider.Controller
-		if *kubernetesController != string(DEPLOYMENT) && *kubernetesController != string(DAEMONSET) && *kubernetesController != string(REPLICATION_CONTROLLER) {
+		if *kubernetesController != string(DEPLOYMENT) && *kubernetesController != string(DAEMONSET) && *kubernetesController != string(REPLICATION_CONTROLLER) && *kubernetesController != string(ROLLOUT) {
 			return fmt.Errorf(
-				"unexpected Value for Kubernetes Controller field. Possible values are: %v, %v, and %v", string(DEPLOYMENT), string(DAEMONSET), string(REPLICATION_CONTROLLER),
+				"unexpected Value for Kubernetes Controller field. Possible values are: %v, %v, %v, and %v", string(DEPLOYMENT), string(DAEMONSET), string(REPLICATION_CONTROLLER), string(ROLLOUT),
 			)
 		}
 
@@ -138,14 +201,43 @@ func (k *Kompose) validateOptions(options ConvertOptions) error {
 			)
 		}
 
+		if kubernetesProvider.ExposeMode != INGRESS && kubernetesProvider.ExposeMode != GATEWAY && kubernetesProvider.ExposeMode != "" {
+			return fmt.Errorf(
+				"unexpected Value for Kubernetes Expose Mode field. Possible values are: %v, %v, ''", string(INGRESS), string(GATEWAY),
+			)
+		}
+
 		if *build == string(BUILD_CONFIG) {
 			return fmt.Errorf("the build value %v is only supported for Openshift provider", string(BUILD_CONFIG))
 		}
+
+		if kubernetesProvider.Chart && options.NumberedFiles {
+			return fmt.Errorf("numbered files cannot be used with a Helm chart, Helm installs the templates in its own order")
+		}
+
+		if kubernetesProvider.Chart && options.Layout == PER_SERVICE {
+			return fmt.Errorf("the per-service layout cannot be used with a Helm chart")
+		}
+
+		if kubernetesProvider.Chart && options.Merge {
+			return fmt.Errorf("merge cannot be used with a Helm chart")
+		}
+	}
+
+	if _, ok := options.Provider.(Knative); ok && *build == string(BUILD_CONFIG) {
+		return fmt.Errorf("the build value %v is only supported for Openshift provider", string(BUILD_CONFIG))
 	}
 
 	return nil
 }
 
+func (k *Kompose) minKubernetesVersion(options ConvertOptions) string {
+	if options.MinKubernetesVersion == "" {
+		return validation.SchemaKubernetesVersion
+	}
+	return options.MinKubernetesVersion
+}
+
 func (k *Kompose) createDeployment(options ConvertOptions) bool {
 	if kubernetesProvider, ok := options.Provider.(Kubernetes); ok {
 		return *kubernetesProvider.Controller == string(DEPLOYMENT)
@@ -174,6 +266,13 @@ func (k *Kompose) createChart(options ConvertOptions) bool {
 	return false
 }
 
+func (k *Kompose) chartMetadata(options ConvertOptions) Kubernetes {
+	if kubernetesProvider, ok := options.Provider.(Kubernetes); ok && kubernetesProvider.Chart {
+		return kubernetesProvider
+	}
+	return Kubernetes{}
+}
+
 func (k *Kompose) multiContainerMode(options ConvertOptions) bool {
 	if kubernetesProvider, ok := options.Provider.(Kubernetes); ok {
 		return kubernetesProvider.MultiContainerMode
@@ -195,6 +294,20 @@ func (k *Kompose) serviceGroupName(options ConvertOptions) string {
 	return ""
 }
 
+func (k *Kompose) exposeMode(options ConvertOptions) string {
+	if kubernetesProvider, ok := options.Provider.(Kubernetes); ok {
+		return string(kubernetesProvider.ExposeMode)
+	}
+	return ""
+}
+
+func (k *Kompose) gateway(options ConvertOptions) string {
+	if kubernetesProvider, ok := options.Provider.(Kubernetes); ok {
+		return kubernetesProvider.Gateway
+	}
+	return ""
+}
+
 func (k *Kompose) secretsAsFiles(options ConvertOptions) bool {
 	if kubernetesProvider, ok := options.Provider.(Kubernetes); ok {
 		return kubernetesProvider.SecretsAsFiles
@@ -234,6 +347,9 @@ func (k *Kompose) getProvider(options ConvertOptions) string {
 	if _, ok := options.Provider.(Openshift); ok {
 		return "openshift"
 	}
+	if _, ok := options.Provider.(Knative); ok {
+		return "knative"
+	}
 	if _, ok := options.Provider.(Kubernetes); ok {
 		return "kubernetes"
 	}
@@ -37,7 +37,7 @@ func TestConvertError(t *testing.T) {
 					Controller: &randomKubernetesControllerValue,
 				},
 			},
-			errorMessage: fmt.Sprintf("unexpected Value for Kubernetes Controller field. Possible values are: %v, %v, and %v", string(DEPLOYMENT), string(DAEMONSET), string(REPLICATION_CONTROLLER)),
+			errorMessage: fmt.Sprintf("unexpected Value for Kubernetes Controller field. Possible values are: %v, %v, %v, and %v", string(DEPLOYMENT), string(DAEMONSET), string(REPLICATION_CONTROLLER), string(ROLLOUT)),
 		},
 		{
 			options: ConvertOptions{
@@ -69,7 +69,7 @@ func TestConvertWithDefaultOptions(t *testing.T) {
 	client, err := NewClient(WithErrorOnWarning())
 	assert.Check(t, is.Equal(err, nil))
 	objects, err := client.Convert(ConvertOptions{
-		OutFile: "./testdata/generated/",
+		OutFile: t.TempDir() + "/",
 		InputFiles: []string{
 			"./testdata/docker-compose.yaml",
 		},
@@ -1,5 +1,7 @@
 package client
 
+import "github.com/kubernetes/kompose/pkg/loader"
+
 // Opt is a configuration option to initialize a client
 type Opt func(*Kompose) error
 
@@ -23,3 +25,15 @@ func WithErrorOnWarning() Opt {
 		return nil
 	}
 }
+
+// WithLoader reads the input files with the loader registered under name with loader.Register,
+// instead of the one detected from the files
+func WithLoader(name string) Opt {
+	return func(k *Kompose) error {
+		if _, err := loader.GetLoader(name); err != nil {
+			return err
+		}
+		k.loader = name
+		return nil
+	}
+}
@@ -59,3 +59,12 @@ func TestNewClientWithOpts(t *testing.T) {
 		assert.Check(t, is.Equal(client.suppressWarnings, tc.expectedSuppressWarnings))
 	}
 }
+
+func TestNewClientWithLoader(t *testing.T) {
+	client, err := NewClient(WithLoader("kubernetes"))
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(client.loader, "kubernetes"))
+
+	_, err = NewClient(WithLoader("descriptor"))
+	assert.Check(t, is.ErrorContains(err, "input file format descriptor is not supported"))
+}
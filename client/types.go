@@ -14,6 +14,14 @@ const (
 	DEPLOYMENT             KubernetesController = "deployment"
 	DAEMONSET              KubernetesController = "daemonSet"
 	REPLICATION_CONTROLLER KubernetesController = "replicationController"
+	ROLLOUT                KubernetesController = "rollout"
+)
+
+type Layout string
+
+const (
+	FLAT        Layout = "flat"
+	PER_SERVICE Layout = "per-service"
 )
 
 type ServiceGroupMode string
@@ -23,6 +31,13 @@ const (
 	VOLUME ServiceGroupMode = "volume"
 )
 
+type ExposeMode string
+
+const (
+	INGRESS ExposeMode = "ingress"
+	GATEWAY ExposeMode = "gateway"
+)
+
 type VolumeType string
 
 const (
@@ -45,7 +60,18 @@ type ConvertOptions struct {
 	WithKomposeAnnotations *bool
 	InputFiles             []string
 	Provider
-	GenerateNetworkPolicies bool
+	GenerateNetworkPolicies      bool
+	GeneratePodDisruptionBudgets bool
+	NumberedFiles                bool
+	StableOutput                 bool
+	Layout                       Layout
+	Validate                     bool
+	MinKubernetesVersion         string
+	Merge                        bool
+	Patches                      []string
+	Plugins                      []string
+	Labels                       map[string]string
+	Annotations                  map[string]string
 }
 
 type Provider interface{}
@@ -58,6 +84,13 @@ type Kubernetes struct {
 	ServiceGroupMode   *string
 	ServiceGroupName   string
 	SecretsAsFiles     bool
+	ExposeMode         ExposeMode
+	Gateway            string
+	ChartName          string
+	ChartVersion       string
+	AppVersion         string
+	ChartDescription   string
+	ChartPackage       bool
 }
 
 type Openshift struct {
@@ -67,3 +100,7 @@ type Openshift struct {
 	BuildRepo          string
 	BuildBranch        string
 }
+
+type Knative struct {
+	Provider
+}
@@ -17,10 +17,13 @@ limitations under the License.
 package cmd
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/kubernetes/kompose/pkg/app"
 	"github.com/kubernetes/kompose/pkg/kobject"
+	"github.com/kubernetes/kompose/pkg/loader"
+	"github.com/kubernetes/kompose/pkg/validation"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -52,6 +55,26 @@ var (
 	ConvertOpt                   kobject.ConvertOptions
 	ConvertYAMLIndent            int
 	GenerateNetworkPolicies      bool
+	GeneratePodDisruptionBudgets bool
+	ConvertExposeMode            string
+	ConvertGateway               string
+	ConvertOutputFormat          string
+	ConvertChartName             string
+	ConvertChartVersion          string
+	ConvertAppVersion            string
+	ConvertChartDescription      string
+	ConvertChartPackage          bool
+	ConvertNumberedFiles         bool
+	ConvertLayout                string
+	ConvertValidate              bool
+	ConvertMinKubernetesVersion  string
+	ConvertMerge                 bool
+	ConvertStableOutput          bool
+	ConvertInputFormat           string
+	ConvertPatches               []string
+	ConvertPlugins               []string
+	ConvertLabels                []string
+	ConvertAnnotations           []string
 
 	UpBuild string
 
@@ -76,128 +99,207 @@ var (
 )
 
 var convertCmd = &cobra.Command{
-	Use:   "convert",
-	Short: "Convert a Docker Compose file",
-	PreRun: func(cmd *cobra.Command, args []string) {
-
-		// Check that build-config wasn't passed in with --provider=kubernetes
-		if GlobalProvider == "kubernetes" && UpBuild == "build-config" {
-			log.Fatalf("build-config is not a valid --build parameter with provider Kubernetes")
-		}
-
-		// Create the Convert Options.
-		ConvertOpt = kobject.ConvertOptions{
-			ToStdout:                    ConvertStdout,
-			CreateChart:                 ConvertChart,
-			GenerateYaml:                ConvertYaml,
-			GenerateJSON:                ConvertJSON,
-			Replicas:                    ConvertReplicas,
-			InputFiles:                  GlobalFiles,
-			OutFile:                     ConvertOut,
-			Provider:                    GlobalProvider,
-			CreateD:                     ConvertDeployment,
-			CreateDS:                    ConvertDaemonSet,
-			CreateRC:                    ConvertReplicationController,
-			Build:                       ConvertBuild,
-			BuildRepo:                   ConvertBuildRepo,
-			BuildBranch:                 ConvertBuildBranch,
-			PushImage:                   ConvertPushImage,
-			PushImageRegistry:           ConvertPushImageRegistry,
-			CreateDeploymentConfig:      ConvertDeploymentConfig,
-			EmptyVols:                   ConvertEmptyVols,
-			Volumes:                     ConvertVolumes,
-			PVCRequestSize:              ConvertPVCRequestSize,
-			InsecureRepository:          ConvertInsecureRepo,
-			IsDeploymentFlag:            cmd.Flags().Lookup("deployment").Changed,
-			IsDaemonSetFlag:             cmd.Flags().Lookup("daemon-set").Changed,
-			IsReplicationControllerFlag: cmd.Flags().Lookup("replication-controller").Changed,
-			Controller:                  strings.ToLower(ConvertController),
-			IsReplicaSetFlag:            cmd.Flags().Lookup("replicas").Changed,
-			IsDeploymentConfigFlag:      cmd.Flags().Lookup("deployment-config").Changed,
-			YAMLIndent:                  ConvertYAMLIndent,
-			WithKomposeAnnotation:       WithKomposeAnnotation,
-			MultipleContainerMode:       MultipleContainerMode,
-			ServiceGroupMode:            ServiceGroupMode,
-			ServiceGroupName:            ServiceGroupName,
-			SecretsAsFiles:              SecretsAsFiles,
-			GenerateNetworkPolicies:     GenerateNetworkPolicies,
-			BuildCommand:                BuildCommand,
-			PushCommand:                 PushCommand,
-			Namespace:                   ConvertNamespace,
-		}
-
-		if ServiceGroupMode == "" && MultipleContainerMode {
-			ConvertOpt.ServiceGroupMode = "label"
-		}
-
-		app.ValidateFlags(args, cmd, &ConvertOpt)
-		app.ValidateComposeFile(&ConvertOpt)
-	},
+	Use:    "convert",
+	Short:  "Convert a Docker Compose file",
+	PreRun: convertPreRun,
 	Run: func(cmd *cobra.Command, args []string) {
 
 		app.Convert(ConvertOpt)
 	},
 }
 
-func init() {
-	// Automatically grab environment variables
-	viper.AutomaticEnv()
+// convertPreRun creates the convert options from the flags and validates them
+func convertPreRun(cmd *cobra.Command, args []string) {
+	// Create the Convert Options.
+	ConvertOpt = kobject.ConvertOptions{
+		ToStdout:                     ConvertStdout,
+		CreateChart:                  ConvertChart,
+		GenerateYaml:                 ConvertYaml,
+		GenerateJSON:                 ConvertJSON,
+		Replicas:                     ConvertReplicas,
+		InputFiles:                   GlobalFiles,
+		InputFormat:                  ConvertInputFormat,
+		OutFile:                      ConvertOut,
+		Provider:                     GlobalProvider,
+		CreateD:                      ConvertDeployment,
+		CreateDS:                     ConvertDaemonSet,
+		CreateRC:                     ConvertReplicationController,
+		Build:                        ConvertBuild,
+		BuildRepo:                    ConvertBuildRepo,
+		BuildBranch:                  ConvertBuildBranch,
+		PushImage:                    ConvertPushImage,
+		PushImageRegistry:            ConvertPushImageRegistry,
+		CreateDeploymentConfig:       ConvertDeploymentConfig,
+		EmptyVols:                    ConvertEmptyVols,
+		Volumes:                      ConvertVolumes,
+		PVCRequestSize:               ConvertPVCRequestSize,
+		InsecureRepository:           ConvertInsecureRepo,
+		IsDeploymentFlag:             cmd.Flags().Lookup("deployment").Changed,
+		IsDaemonSetFlag:              cmd.Flags().Lookup("daemon-set").Changed,
+		IsReplicationControllerFlag:  cmd.Flags().Lookup("replication-controller").Changed,
+		Controller:                   strings.ToLower(ConvertController),
+		IsReplicaSetFlag:             cmd.Flags().Lookup("replicas").Changed,
+		IsDeploymentConfigFlag:       cmd.Flags().Lookup("deployment-config").Changed,
+		YAMLIndent:                   ConvertYAMLIndent,
+		WithKomposeAnnotation:        WithKomposeAnnotation,
+		StableOutput:                 ConvertStableOutput,
+		MultipleContainerMode:        MultipleContainerMode,
+		ServiceGroupMode:             ServiceGroupMode,
+		ServiceGroupName:             ServiceGroupName,
+		SecretsAsFiles:               SecretsAsFiles,
+		GenerateNetworkPolicies:      GenerateNetworkPolicies,
+		GeneratePodDisruptionBudgets: GeneratePodDisruptionBudgets,
+		ExposeMode:                   strings.ToLower(ConvertExposeMode),
+		Gateway:                      ConvertGateway,
+		OutputFormat:                 strings.ToLower(ConvertOutputFormat),
+		ChartName:                    ConvertChartName,
+		ChartVersion:                 ConvertChartVersion,
+		AppVersion:                   ConvertAppVersion,
+		ChartDescription:             ConvertChartDescription,
+		ChartPackage:                 ConvertChartPackage,
+		NumberedFiles:                ConvertNumberedFiles,
+		Layout:                       ConvertLayout,
+		Validate:                     ConvertValidate,
+		MinKubernetesVersion:         ConvertMinKubernetesVersion,
+		Merge:                        ConvertMerge,
+		Patches:                      ConvertPatches,
+		Plugins:                      ConvertPlugins,
+		Labels:                       parseKeyValues("label", ConvertLabels),
+		Annotations:                  parseKeyValues("annotation", ConvertAnnotations),
+		BuildCommand:                 BuildCommand,
+		PushCommand:                  PushCommand,
+		Namespace:                    ConvertNamespace,
+	}
+
+	if ServiceGroupMode == "" && MultipleContainerMode {
+		ConvertOpt.ServiceGroupMode = "label"
+	}
+
+	app.ValidateFlags(args, cmd, &ConvertOpt)
+	app.ValidateComposeFile(&ConvertOpt)
+}
 
+// parseKeyValues returns the key=value pairs of a repeatable flag
+func parseKeyValues(flag string, pairs []string) map[string]string {
+	if len(pairs) == 0 {
+		return nil
+	}
+	values := map[string]string{}
+	for _, pair := range pairs {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok || key == "" {
+			log.Fatalf("Error: --%s %s is not a key=value pair", flag, pair)
+		}
+		values[key] = value
+	}
+	return values
+}
+
+// addConvertFlags adds the flags of the conversion to cmd
+func addConvertFlags(cmd *cobra.Command) {
+	addObjectFlags(cmd)
+	addManifestFlags(cmd)
+	addOutputFlags(cmd)
+}
+
+// addObjectFlags adds the flags changing the converted objects to cmd
+func addObjectFlags(cmd *cobra.Command) {
 	// Kubernetes only
-	convertCmd.Flags().BoolVarP(&ConvertChart, "chart", "c", false, "Create a Helm chart for converted objects")
-	convertCmd.Flags().BoolVar(&ConvertDaemonSet, "daemon-set", false, "Generate a Kubernetes daemonset object (deprecated, use --controller instead)")
-	convertCmd.Flags().BoolVarP(&ConvertDeployment, "deployment", "d", false, "Generate a Kubernetes deployment object (deprecated, use --controller instead)")
-	convertCmd.Flags().BoolVar(&ConvertReplicationController, "replication-controller", false, "Generate a Kubernetes replication controller object (deprecated, use --controller instead)")
-	convertCmd.Flags().StringVar(&ConvertController, "controller", "", `Set the output controller ("deployment"|"daemonSet"|"replicationController")`)
-	convertCmd.Flags().MarkDeprecated("daemon-set", "use --controller")
-	convertCmd.Flags().MarkDeprecated("deployment", "use --controller")
-	convertCmd.Flags().MarkDeprecated("replication-controller", "use --controller")
-	convertCmd.Flags().MarkHidden("chart")
-	convertCmd.Flags().MarkHidden("daemon-set")
-	convertCmd.Flags().MarkHidden("replication-controller")
-	convertCmd.Flags().MarkHidden("deployment")
-	convertCmd.Flags().BoolVar(&MultipleContainerMode, "multiple-container-mode", false, "Create multiple containers grouped by 'kompose.service.group' label")
-	convertCmd.Flags().StringVar(&ServiceGroupMode, "service-group-mode", "", "Group multiple service to create single workload by `label`(`kompose.service.group`) or `volume`(shared volumes)")
-	convertCmd.Flags().StringVar(&ServiceGroupName, "service-group-name", "", "Using with --service-group-mode=volume to specific a final service name for the group")
-	convertCmd.Flags().MarkDeprecated("multiple-container-mode", "use --service-group-mode=label")
-	convertCmd.Flags().BoolVar(&SecretsAsFiles, "secrets-as-files", false, "Always convert docker-compose secrets into files instead of symlinked directories.")
+	cmd.Flags().BoolVar(&ConvertDaemonSet, "daemon-set", false, "Generate a Kubernetes daemonset object (deprecated, use --controller instead)")
+	cmd.Flags().BoolVarP(&ConvertDeployment, "deployment", "d", false, "Generate a Kubernetes deployment object (deprecated, use --controller instead)")
+	cmd.Flags().BoolVar(&ConvertReplicationController, "replication-controller", false, "Generate a Kubernetes replication controller object (deprecated, use --controller instead)")
+	cmd.Flags().StringVar(&ConvertController, "controller", "", `Set the output controller ("deployment"|"daemonSet"|"replicationController"|"rollout")`)
+	cmd.Flags().MarkDeprecated("daemon-set", "use --controller")
+	cmd.Flags().MarkDeprecated("deployment", "use --controller")
+	cmd.Flags().MarkDeprecated("replication-controller", "use --controller")
+	cmd.Flags().MarkHidden("daemon-set")
+	cmd.Flags().MarkHidden("replication-controller")
+	cmd.Flags().MarkHidden("deployment")
+	cmd.Flags().BoolVar(&MultipleContainerMode, "multiple-container-mode", false, "Create multiple containers grouped by 'kompose.service.group' label")
+	cmd.Flags().StringVar(&ServiceGroupMode, "service-group-mode", "", "Group multiple service to create single workload by `label`(`kompose.service.group`) or `volume`(shared volumes)")
+	cmd.Flags().StringVar(&ServiceGroupName, "service-group-name", "", "Using with --service-group-mode=volume to specific a final service name for the group")
+	cmd.Flags().MarkDeprecated("multiple-container-mode", "use --service-group-mode=label")
+	cmd.Flags().BoolVar(&SecretsAsFiles, "secrets-as-files", false, "Always convert docker-compose secrets into files instead of symlinked directories.")
+	cmd.Flags().StringVar(&ConvertExposeMode, "expose-mode", "ingress", `Set how services with the kompose.service.expose label are exposed ("ingress"|"gateway")`)
+	cmd.Flags().StringVar(&ConvertGateway, "gateway", "", "Parent Gateway ([namespace/]name) of the HTTPRoutes generated with --expose-mode=gateway")
 
 	// OpenShift only
-	convertCmd.Flags().BoolVar(&ConvertDeploymentConfig, "deployment-config", true, "Generate an OpenShift deploymentconfig object")
-	convertCmd.Flags().BoolVar(&ConvertInsecureRepo, "insecure-repository", false, "Use an insecure Docker repository for OpenShift ImageStream")
-	convertCmd.Flags().StringVar(&ConvertBuildRepo, "build-repo", "", "Specify source repository for buildconfig (default remote origin)")
-	convertCmd.Flags().StringVar(&ConvertBuildBranch, "build-branch", "", "Specify repository branch to use for buildconfig (default master)")
-	convertCmd.Flags().MarkDeprecated("deployment-config", "use --controller")
-	convertCmd.Flags().MarkHidden("deployment-config")
-	convertCmd.Flags().MarkHidden("insecure-repository")
-	convertCmd.Flags().MarkHidden("build-repo")
-	convertCmd.Flags().MarkHidden("build-branch")
+	cmd.Flags().BoolVar(&ConvertDeploymentConfig, "deployment-config", true, "Generate an OpenShift deploymentconfig object")
+	cmd.Flags().BoolVar(&ConvertInsecureRepo, "insecure-repository", false, "Use an insecure Docker repository for OpenShift ImageStream")
+	cmd.Flags().StringVar(&ConvertBuildRepo, "build-repo", "", "Specify source repository for buildconfig (default remote origin)")
+	cmd.Flags().StringVar(&ConvertBuildBranch, "build-branch", "", "Specify repository branch to use for buildconfig (default master)")
+	cmd.Flags().MarkDeprecated("deployment-config", "use --controller")
+	cmd.Flags().MarkHidden("deployment-config")
+	cmd.Flags().MarkHidden("insecure-repository")
+	cmd.Flags().MarkHidden("build-repo")
+	cmd.Flags().MarkHidden("build-branch")
 
 	// Standard between the two
-	convertCmd.Flags().StringVar(&ConvertBuild, "build", "none", `Set the type of build ("local"|"build-config"(OpenShift only)|"none")`)
-	convertCmd.Flags().BoolVar(&ConvertPushImage, "push-image", false, "If we should push the docker image we built")
-	convertCmd.Flags().StringVar(&BuildCommand, "build-command", "", `Set the command used to build the container image. override the docker build command.Should be used in conjuction with --push-command flag.`)
-	convertCmd.Flags().StringVar(&PushCommand, "push-command", "", `Set the command used to push the container image. override the docker push command. Should be used in conjuction with --build-command flag.`)
-	convertCmd.Flags().StringVar(&ConvertPushImageRegistry, "push-image-registry", "", "Specify registry for pushing image, which will override registry from image name.")
-	convertCmd.Flags().BoolVarP(&ConvertYaml, "yaml", "y", false, "Generate resource files into YAML format")
-	convertCmd.Flags().MarkDeprecated("yaml", "YAML is the default format now.")
-	convertCmd.Flags().MarkShorthandDeprecated("y", "YAML is the default format now.")
-	convertCmd.Flags().BoolVarP(&ConvertJSON, "json", "j", false, "Generate resource files into JSON format")
-	convertCmd.Flags().BoolVar(&ConvertStdout, "stdout", false, "Print converted objects to stdout")
-	convertCmd.Flags().StringVarP(&ConvertOut, "out", "o", "", "Specify a file name or directory to save objects to (if path does not exist, a file will be created)")
-	convertCmd.Flags().IntVar(&ConvertReplicas, "replicas", 1, "Specify the number of replicas in the generated resource spec")
-	convertCmd.Flags().StringVar(&ConvertVolumes, "volumes", "persistentVolumeClaim", `Volumes to be generated ("persistentVolumeClaim"|"emptyDir"|"hostPath" | "configMap")`)
-	convertCmd.Flags().StringVar(&ConvertPVCRequestSize, "pvc-request-size", "", `Specify the size of pvc storage requests in the generated resource spec`)
-	convertCmd.Flags().StringVarP(&ConvertNamespace, "namespace", "n", "", `Specify the namespace of the generated resources`)
-	convertCmd.Flags().BoolVar(&GenerateNetworkPolicies, "generate-network-policies", false, "Specify whether to generate network policies or not.")
-
-	convertCmd.Flags().BoolVar(&WithKomposeAnnotation, "with-kompose-annotation", true, "Add kompose annotations to generated resource")
+	cmd.Flags().StringVar(&ConvertInputFormat, "input-format", "", fmt.Sprintf(`Set the format of the input files ("%s"), detected from the files when not set`, strings.Join(loader.Formats(), `"|"`)))
+	cmd.Flags().StringVar(&ConvertBuild, "build", "none", `Set the type of build ("local"|"build-config"(OpenShift only)|"none")`)
+	cmd.Flags().BoolVar(&ConvertPushImage, "push-image", false, "If we should push the docker image we built")
+	cmd.Flags().StringVar(&BuildCommand, "build-command", "", `Set the command used to build the container image. override the docker build command.Should be used in conjuction with --push-command flag.`)
+	cmd.Flags().StringVar(&PushCommand, "push-command", "", `Set the command used to push the container image. override the docker push command. Should be used in conjuction with --build-command flag.`)
+	cmd.Flags().StringVar(&ConvertPushImageRegistry, "push-image-registry", "", "Specify registry for pushing image, which will override registry from image name.")
+	cmd.Flags().IntVar(&ConvertReplicas, "replicas", 1, "Specify the number of replicas in the generated resource spec")
+	cmd.Flags().StringVar(&ConvertVolumes, "volumes", "persistentVolumeClaim", `Volumes to be generated ("persistentVolumeClaim"|"emptyDir"|"hostPath" | "configMap")`)
+	cmd.Flags().StringVar(&ConvertPVCRequestSize, "pvc-request-size", "", `Specify the size of pvc storage requests in the generated resource spec`)
+	addNamespaceFlag(cmd)
+	cmd.Flags().BoolVar(&GenerateNetworkPolicies, "generate-network-policies", false, "Specify whether to generate network policies or not.")
+	cmd.Flags().BoolVar(&GeneratePodDisruptionBudgets, "generate-pod-disruption-budgets", false, "Generate a pod disruption budget for every service with more than one replica")
+	cmd.Flags().StringArrayVar(&ConvertPatches, "patch", []string{}, "Apply the strategic merge and JSON 6902 patches of a file, or of the YAML and JSON files of a directory, to the converted objects matching their target")
+	cmd.Flags().StringArrayVar(&ConvertPlugins, "plugin", []string{}, "Pipe the converted objects through an executable, it reads them with the kompose object as JSON on stdin and writes the objects to keep on stdout")
+	cmd.Flags().StringArrayVar(&ConvertLabels, "label", []string{}, "Add a key=value label to every generated object and pod template")
+	cmd.Flags().StringArrayVar(&ConvertAnnotations, "annotation", []string{}, "Add a key=value annotation to every generated object and pod template")
+	cmd.Flags().BoolVar(&WithKomposeAnnotation, "with-kompose-annotation", true, "Add kompose annotations to generated resource")
+	cmd.Flags().BoolVar(&ConvertStableOutput, "stable-output", false, "Leave out the kompose.cmd annotation so that converting the same compose files always writes the same bytes")
 
 	// Deprecated commands
-	convertCmd.Flags().BoolVar(&ConvertEmptyVols, "emptyvols", false, "Use Empty Volumes. Do not generate PVCs")
-	convertCmd.Flags().MarkDeprecated("emptyvols", "emptyvols has been marked as deprecated. Use --volumes emptyDir")
+	cmd.Flags().BoolVar(&ConvertEmptyVols, "emptyvols", false, "Use Empty Volumes. Do not generate PVCs")
+	cmd.Flags().MarkDeprecated("emptyvols", "emptyvols has been marked as deprecated. Use --volumes emptyDir")
+}
+
+// addNamespaceFlag adds the --namespace flag to cmd
+func addNamespaceFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&ConvertNamespace, "namespace", "n", "", `Specify the namespace of the generated resources`)
+}
+
+// addManifestFlags adds the flags of the manifests written to --out to cmd
+func addManifestFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&ConvertOut, "out", "o", "", "Specify a file name or directory to save objects to (if path does not exist, a file will be created), or a .tar, .tar.gz, .tgz or .zip archive")
+	cmd.Flags().IntVar(&ConvertYAMLIndent, "indent", 2, "Spaces length to indent generated yaml files")
+}
+
+// addOutputFlags adds the flags of the files kompose convert writes to cmd
+func addOutputFlags(cmd *cobra.Command) {
+	// Kubernetes only
+	cmd.Flags().BoolVarP(&ConvertChart, "chart", "c", false, "Create a Helm chart for converted objects")
+	cmd.Flags().StringVar(&ConvertChartName, "chart-name", "", "Set the name of the Helm chart (default is the name of the chart directory)")
+	cmd.Flags().StringVar(&ConvertChartVersion, "chart-version", "", "Set the version of the Helm chart (default 0.0.1)")
+	cmd.Flags().StringVar(&ConvertAppVersion, "app-version", "", "Set the version of the application deployed by the Helm chart")
+	cmd.Flags().StringVar(&ConvertChartDescription, "chart-description", "", "Set the description of the Helm chart")
+	cmd.Flags().BoolVar(&ConvertChartPackage, "chart-package", false, "Package the Helm chart as a <name>-<version>.tgz archive written to --out")
+	cmd.Flags().MarkHidden("chart")
+
+	// Standard between the two
+	cmd.Flags().BoolVarP(&ConvertYaml, "yaml", "y", false, "Generate resource files into YAML format")
+	cmd.Flags().MarkDeprecated("yaml", "YAML is the default format now.")
+	cmd.Flags().MarkShorthandDeprecated("y", "YAML is the default format now.")
+	cmd.Flags().BoolVarP(&ConvertJSON, "json", "j", false, "Generate resource files into JSON format")
+	cmd.Flags().BoolVar(&ConvertStdout, "stdout", false, "Print converted objects to stdout")
+	cmd.Flags().StringVar(&ConvertOutputFormat, "output-format", "", `Set the layout of the generated files ("kustomize": a base from the first compose file and an overlay per following file, "template": a single OpenShift Template with parameters, "yaml-list": a single v1 List document with --stdout or an --out file)`)
+	cmd.Flags().BoolVar(&ConvertValidate, "validate", false, "Check the fields, names and required values of the generated objects before writing them")
+	cmd.Flags().StringVar(&ConvertMinKubernetesVersion, "min-kube-version", validation.SchemaKubernetesVersion, "Oldest version of Kubernetes that must have introduced the API versions of the objects, checked with --validate")
+	cmd.Flags().StringVar(&ConvertLayout, "layout", "flat", `Set the layout of the files written to a directory ("flat": all the files in the directory, "per-service": a directory with a kustomization.yaml per service, and _shared for the objects used by several services)`)
+	cmd.Flags().BoolVar(&ConvertMerge, "merge", false, "Keep the changes made by hand to the manifests of --out since the last conversion, recorded in .kompose-state.json, and only update the fields kompose generates")
+	cmd.Flags().BoolVar(&ConvertNumberedFiles, "numbered-files", false, "Prefix the files written to a directory with their install order, e.g. 01-db-persistentvolumeclaim.yaml")
+}
+
+func init() {
+	// Automatically grab environment variables
+	viper.AutomaticEnv()
 
-	convertCmd.Flags().IntVar(&ConvertYAMLIndent, "indent", 2, "Spaces length to indent generated yaml files")
+	addConvertFlags(convertCmd)
 
 	// In order to 'separate' both OpenShift and Kubernetes only flags. A custom help page is created
 	customHelp := `Usage:{{if .Runnable}}
@@ -215,9 +317,16 @@ Available Commands:{{range .Commands}}{{if .IsAvailableCommand}}
 
 Kubernetes Flags:
   -c, --chart                    Create a Helm chart for converted objects
-      --controller               Set the output controller ("deployment"|"daemonSet"|"replicationController")
+      --chart-name               Set the name of the Helm chart (default is the name of the chart directory)
+      --chart-version            Set the version of the Helm chart (default 0.0.1)
+      --app-version              Set the version of the application deployed by the Helm chart
+      --chart-description        Set the description of the Helm chart
+      --chart-package            Package the Helm chart as a <name>-<version>.tgz archive written to --out
+      --controller               Set the output controller ("deployment"|"daemonSet"|"replicationController"|"rollout")
       --service-group-mode       Group multiple service to create single workload by "label"("kompose.service.group") or "volume"(shared volumes)
       --service-group-name       Using with --service-group-mode=volume to specific a final service name for the group
+      --expose-mode              Set how services with the kompose.service.expose label are exposed ("ingress"|"gateway")
+      --gateway                  Parent Gateway ([namespace/]name) of the HTTPRoutes generated with --expose-mode=gateway
 
 OpenShift Flags:
       --build-branch             Specify repository branch to use for buildconfig (default is current branch name)
@@ -0,0 +1,47 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"os"
+
+	"github.com/kubernetes/kompose/pkg/app"
+	"github.com/spf13/cobra"
+)
+
+// diffCmd represents the diff command
+var diffCmd = &cobra.Command{
+	Use:     "diff",
+	Short:   "Compare the converted objects with the manifests of --out",
+	Long:    "Convert the Docker Compose file in memory and print a unified diff of every object that differs from the manifests of the --out file or directory. Exits with status 1 when they differ.",
+	Example: "  kompose diff -f compose.yaml -o ./k8s",
+	PreRun: func(cmd *cobra.Command, args []string) {
+		convertPreRun(cmd, args)
+		app.ValidateDiffFlags(ConvertOpt)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		if app.Diff(ConvertOpt) {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	addObjectFlags(diffCmd)
+	addManifestFlags(diffCmd)
+	RootCmd.AddCommand(diffCmd)
+}
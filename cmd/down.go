@@ -0,0 +1,51 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"github.com/kubernetes/kompose/pkg/app"
+	"github.com/kubernetes/kompose/pkg/cluster"
+	"github.com/kubernetes/kompose/pkg/kobject"
+	"github.com/spf13/cobra"
+)
+
+// downCmd represents the down command
+var downCmd = &cobra.Command{
+	Use:     "down",
+	Short:   "Delete instantiated services/deployments from a cluster",
+	Long:    "Delete the objects labeled with " + cluster.ProjectLabel + " set to the name of the compose project from the cluster of the current context of the kubeconfig.",
+	Example: "  kompose down -f compose.yaml",
+	PreRun: func(cmd *cobra.Command, args []string) {
+		// The objects to delete are selected by the name of the project, they aren't converted
+		ConvertOpt = kobject.ConvertOptions{
+			InputFiles: GlobalFiles,
+			Provider:   GlobalProvider,
+			Namespace:  ConvertNamespace,
+			Server:     ClusterServer,
+		}
+		app.ValidateDownFlags(args, &ConvertOpt)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		app.Down(ConvertOpt)
+	},
+}
+
+func init() {
+	addNamespaceFlag(downCmd)
+	downCmd.Flags().StringVar(&ClusterServer, "server", "", "Address of the Kubernetes API server (default is the server of the current context of the kubeconfig)")
+	RootCmd.AddCommand(downCmd)
+}
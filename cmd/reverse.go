@@ -0,0 +1,55 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"github.com/kubernetes/kompose/pkg/app"
+	"github.com/kubernetes/kompose/pkg/kobject"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// ReverseOut is the compose file kompose reverse writes, stdout when it is empty
+	ReverseOut    string
+	ReverseIndent int
+	ReverseOpt    kobject.ConvertOptions
+)
+
+// reverseCmd represents the reverse command
+var reverseCmd = &cobra.Command{
+	Use:     "reverse",
+	Short:   "Convert Kubernetes manifests to a Docker Compose file",
+	Long:    "Load the Deployments, StatefulSets and DaemonSets of Kubernetes manifests, with the Services, ConfigMaps, Secrets and PersistentVolumeClaims they use, and write them as the services of a Docker Compose file. What can't be written in the compose file is reported with a warning.",
+	Example: "  kompose reverse -f ./k8s -o compose.yaml",
+	PreRun: func(cmd *cobra.Command, args []string) {
+		ReverseOpt = kobject.ConvertOptions{
+			InputFiles: GlobalFiles,
+			OutFile:    ReverseOut,
+			YAMLIndent: ReverseIndent,
+		}
+		app.ValidateReverseFlags(ReverseOpt)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		app.Reverse(ReverseOpt)
+	},
+}
+
+func init() {
+	reverseCmd.Flags().StringVarP(&ReverseOut, "out", "o", "", "Specify the compose file to write (default is stdout)")
+	reverseCmd.Flags().IntVar(&ReverseIndent, "indent", 2, "Spaces length to indent the compose file")
+	RootCmd.AddCommand(reverseCmd)
+}
@@ -17,8 +17,10 @@ limitations under the License.
 package cmd
 
 import (
+	"fmt"
 	"strings"
 
+	"github.com/kubernetes/kompose/pkg/transformer"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
@@ -75,10 +77,10 @@ var RootCmd = &cobra.Command{
 			log.AddHook(hook)
 		}
 
-		// Error out of the user has not chosen Kubernetes or OpenShift
-		provider := strings.ToLower(GlobalProvider)
-		if provider != "kubernetes" && provider != "openshift" {
-			log.Fatalf("%s is an unsupported provider. Supported providers are: 'kubernetes', 'openshift'.", GlobalProvider)
+		// Error out if the user has not chosen a registered provider
+		GlobalProvider = strings.ToLower(GlobalProvider)
+		if _, err := transformer.GetProvider(GlobalProvider); err != nil {
+			log.Fatal(err)
 		}
 	},
 }
@@ -94,5 +96,14 @@ func init() {
 	RootCmd.PersistentFlags().BoolVar(&GlobalSuppressWarnings, "suppress-warnings", false, "Suppress all warnings")
 	RootCmd.PersistentFlags().BoolVar(&GlobalErrorOnWarning, "error-on-warning", false, "Treat any warning as an error")
 	RootCmd.PersistentFlags().StringArrayVarP(&GlobalFiles, "file", "f", []string{}, "Specify an alternative compose file")
-	RootCmd.PersistentFlags().StringVar(&GlobalProvider, "provider", "kubernetes", "Specify a provider. Kubernetes or OpenShift.")
+	RootCmd.PersistentFlags().StringVar(&GlobalProvider, "provider", "kubernetes", fmt.Sprintf("Specify a provider (%s)", strings.Join(providerNames(), "|")))
+}
+
+// providerNames returns the names of the registered providers
+func providerNames() []string {
+	var names []string
+	for _, p := range transformer.Providers() {
+		names = append(names, p.Name)
+	}
+	return names
 }
@@ -0,0 +1,58 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"time"
+
+	"github.com/kubernetes/kompose/pkg/app"
+	"github.com/kubernetes/kompose/pkg/cluster"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// ClusterServer is the address of the cluster of kompose up and kompose down, instead of the one of the kubeconfig
+	ClusterServer string
+
+	UpWait        bool
+	UpWaitTimeout time.Duration
+)
+
+// upCmd represents the up command
+var upCmd = &cobra.Command{
+	Use:     "up",
+	Short:   "Deploy your Dockerized application to a cluster",
+	Long:    "Convert the Docker Compose file and apply the objects to the cluster of the current context of the kubeconfig with server-side apply, in the order they are installed. The objects are labeled with " + cluster.ProjectLabel + ", the name of the compose project.",
+	Example: "  kompose up -f compose.yaml --wait",
+	PreRun: func(cmd *cobra.Command, args []string) {
+		convertPreRun(cmd, args)
+		ConvertOpt.Server = ClusterServer
+		ConvertOpt.Wait = UpWait
+		ConvertOpt.WaitTimeout = UpWaitTimeout
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		app.Up(ConvertOpt)
+	},
+}
+
+func init() {
+	addObjectFlags(upCmd)
+	upCmd.Flags().StringVar(&ClusterServer, "server", "", "Address of the Kubernetes API server (default is the server of the current context of the kubeconfig)")
+	upCmd.Flags().BoolVar(&UpWait, "wait", false, "Wait for the rollouts of the Deployments, StatefulSets and DaemonSets")
+	upCmd.Flags().DurationVar(&UpWaitTimeout, "wait-timeout", cluster.DefaultWaitTimeout, "Maximum time to wait for each rollout with --wait")
+	RootCmd.AddCommand(upCmd)
+}
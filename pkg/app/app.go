@@ -17,7 +17,9 @@ limitations under the License.
 package app
 
 import (
+	"context"
 	"fmt"
+	"sort"
 	"strings"
 
 	log "github.com/sirupsen/logrus"
@@ -25,12 +27,20 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 
 	"os"
+	"os/exec"
+	"path/filepath"
 
+	"github.com/kubernetes/kompose/pkg/cluster"
 	"github.com/kubernetes/kompose/pkg/kobject"
 	"github.com/kubernetes/kompose/pkg/loader"
+	"github.com/kubernetes/kompose/pkg/loader/compose"
 	"github.com/kubernetes/kompose/pkg/transformer"
+	// The providers register themselves with the transformer package
+	_ "github.com/kubernetes/kompose/pkg/transformer/knative"
 	"github.com/kubernetes/kompose/pkg/transformer/kubernetes"
 	"github.com/kubernetes/kompose/pkg/transformer/openshift"
+	"github.com/kubernetes/kompose/pkg/utils/archive"
+	"github.com/kubernetes/kompose/pkg/validation"
 )
 
 var (
@@ -52,8 +62,6 @@ const (
 	DefaultProvider = ProviderKubernetes
 )
 
-var inputFormat = "compose"
-
 // ValidateFlags validates all command line flags
 func ValidateFlags(args []string, cmd *cobra.Command, opt *kobject.ConvertOptions) {
 	if opt.OutFile == "-" {
@@ -62,54 +70,23 @@ func ValidateFlags(args []string, cmd *cobra.Command, opt *kobject.ConvertOption
 	}
 
 	// Get the provider
-	provider := cmd.Flags().Lookup("provider").Value.String()
-	log.Debugf("Checking validation of provider: %s", provider)
-
-	// OpenShift specific flags
-	deploymentConfig := cmd.Flags().Lookup("deployment-config").Changed
-	buildRepo := cmd.Flags().Lookup("build-repo").Changed
-	buildBranch := cmd.Flags().Lookup("build-branch").Changed
-
-	// Kubernetes specific flags
-	chart := cmd.Flags().Lookup("chart").Changed
-	daemonSet := cmd.Flags().Lookup("daemon-set").Changed
-	replicationController := cmd.Flags().Lookup("replication-controller").Changed
-	deployment := cmd.Flags().Lookup("deployment").Changed
-
-	// Get the controller
-	controller := opt.Controller
-	log.Debugf("Checking validation of controller: %s", controller)
+	provider, err := transformer.GetProvider(opt.Provider)
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Debugf("Checking validation of provider: %s", provider.Name)
 
-	// Check validations against provider flags
-	switch {
-	case provider == ProviderOpenshift:
-		if chart {
-			log.Fatalf("--chart, -c is a Kubernetes only flag")
-		}
-		if daemonSet {
-			log.Fatalf("--daemon-set is a Kubernetes only flag")
-		}
-		if replicationController {
-			log.Fatalf("--replication-controller is a Kubernetes only flag")
-		}
-		if deployment {
-			log.Fatalf("--deployment, -d is a Kubernetes only flag")
-		}
-		if controller == "daemonset" || controller == "replicationcontroller" || controller == "deployment" {
-			log.Fatalf("--controller= daemonset, replicationcontroller or deployment is a Kubernetes only flag")
-		}
-	case provider == ProviderKubernetes:
-		if deploymentConfig {
-			log.Fatalf("--deployment-config is an OpenShift only flag")
-		}
-		if buildRepo {
-			log.Fatalf("--build-repo is an Openshift only flag")
-		}
-		if buildBranch {
-			log.Fatalf("--build-branch is an Openshift only flag")
+	// The flags of the other providers
+	for _, other := range transformer.Providers() {
+		for _, name := range other.Flags {
+			if flag := cmd.Flags().Lookup(name); flag != nil && flag.Changed && !contains(provider.Flags, name) {
+				log.Fatalf("--%s is not supported by provider %s, it only applies to %s", name, provider.Name, strings.Join(flagProviders(name), " and "))
+			}
 		}
-		if controller == "deploymentconfig" {
-			log.Fatalf("--controller=deploymentConfig is an OpenShift only flag")
+	}
+	if provider.Validate != nil {
+		if err := provider.Validate(*opt); err != nil {
+			log.Fatalf("Error: %v", err)
 		}
 	}
 
@@ -122,6 +99,14 @@ func ValidateFlags(args []string, cmd *cobra.Command, opt *kobject.ConvertOption
 		log.Fatalf("Error: chart cannot be generated when --stdout is specified")
 	}
 
+	if opt.CreateChart && opt.GenerateJSON {
+		log.Fatalf("Error: chart templates can only be generated in YAML format")
+	}
+
+	if !opt.CreateChart && (opt.ChartName != "" || opt.ChartVersion != "" || opt.AppVersion != "" || opt.ChartDescription != "" || opt.ChartPackage) {
+		log.Fatalf("Error: --chart-name, --chart-version, --app-version, --chart-description and --chart-package require --chart")
+	}
+
 	if opt.Replicas < 0 {
 		log.Fatalf("Error: --replicas cannot be negative")
 	}
@@ -130,10 +115,126 @@ func ValidateFlags(args []string, cmd *cobra.Command, opt *kobject.ConvertOption
 		log.Fatal("Unknown Argument(s): ", strings.Join(args, ","))
 	}
 
+	if opt.InputFormat != "" {
+		if _, err := loader.GetLoader(opt.InputFormat); err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+	}
+
 	if opt.GenerateJSON && opt.GenerateYaml {
 		log.Fatalf("YAML and JSON format cannot be provided at the same time")
 	}
 
+	if opt.OutputFormat != "" && !contains(provider.OutputFormats, opt.OutputFormat) {
+		if providers := outputFormatProviders(opt.OutputFormat); len(providers) != 0 {
+			log.Fatalf("Error: --output-format=%s is not supported by provider %s, it only applies to %s", opt.OutputFormat, provider.Name, strings.Join(providers, " and "))
+		}
+		log.Fatalf("Unknown output format: %s, possible values are: '%s'", opt.OutputFormat, strings.Join(outputFormats(), "' '"))
+	}
+
+	if opt.OutputFormat == kubernetes.OutputFormatYAMLList {
+		if opt.GenerateJSON {
+			log.Fatalf("Error: --output-format=yaml-list writes YAML, the JSON output already is a List")
+		}
+		if opt.CreateChart {
+			log.Fatalf("Error: --output-format=yaml-list cannot be used with --chart")
+		}
+		if opt.NumberedFiles {
+			log.Fatalf("Error: --output-format=yaml-list writes a single file and cannot be used with --numbered-files")
+		}
+	}
+
+	if archive.IsArchive(opt.OutFile) {
+		if opt.ChartPackage {
+			log.Fatalf("Error: --chart-package writes the chart package to the --out directory and cannot be used with an archive")
+		}
+		if opt.OutputFormat == kubernetes.OutputFormatKustomize || opt.OutputFormat == kubernetes.OutputFormatYAMLList {
+			log.Fatalf("Error: --output-format=%s cannot be written to an archive", opt.OutputFormat)
+		}
+	}
+
+	if opt.NumberedFiles {
+		if opt.ToStdout {
+			log.Fatalf("Error: --numbered-files only applies to files written to a directory and cannot be used with --stdout")
+		}
+		if opt.CreateChart {
+			log.Fatalf("Error: --numbered-files cannot be used with --chart, Helm installs the templates in its own order")
+		}
+		if opt.OutputFormat == kubernetes.OutputFormatKustomize {
+			log.Fatalf("Error: --numbered-files cannot be used with --output-format=kustomize, kustomize orders the resources itself")
+		}
+	}
+
+	if opt.Validate {
+		if _, err := validation.ParseKubernetesVersion(opt.MinKubernetesVersion); err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+	}
+
+	if _, err := transformer.ReadPatches(opt.Patches); err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+
+	if err := transformer.ValidateCommonMetadata(opt.Labels, opt.Annotations); err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+
+	for _, plugin := range opt.Plugins {
+		if _, err := exec.LookPath(plugin); err != nil {
+			log.Fatalf("Error: plugin %s: %v", plugin, err)
+		}
+	}
+
+	if opt.Layout != "" && opt.Layout != kubernetes.LayoutFlat && opt.Layout != kubernetes.LayoutPerService {
+		log.Fatalf("Unknown layout: %s, possible values are: '%s' '%s'", opt.Layout, kubernetes.LayoutFlat, kubernetes.LayoutPerService)
+	}
+
+	if opt.Layout == kubernetes.LayoutPerService {
+		if opt.ToStdout {
+			log.Fatalf("Error: --layout=per-service writes a directory and cannot be used with --stdout")
+		}
+		if opt.CreateChart {
+			log.Fatalf("Error: --layout=per-service cannot be used with --chart")
+		}
+		if opt.OutputFormat != "" {
+			log.Fatalf("Error: --layout=per-service cannot be used with --output-format=%s", opt.OutputFormat)
+		}
+		if isOutFile(opt.OutFile) && !archive.IsArchive(opt.OutFile) {
+			log.Fatalf("Error: --layout=per-service writes a directory, --out %s is a file", opt.OutFile)
+		}
+	}
+
+	if opt.Merge {
+		if opt.OutFile == "" || opt.ToStdout {
+			log.Fatalf("Error: --merge merges the objects with the manifests of --out, --out is required")
+		}
+		if archive.IsArchive(opt.OutFile) {
+			log.Fatalf("Error: --merge cannot be used with an archive")
+		}
+		if opt.CreateChart {
+			log.Fatalf("Error: --merge cannot be used with --chart")
+		}
+		if opt.OutputFormat == kubernetes.OutputFormatKustomize {
+			log.Fatalf("Error: --merge cannot be used with --output-format=%s", opt.OutputFormat)
+		}
+	}
+
+	if opt.OutputFormat == kubernetes.OutputFormatKustomize {
+		if opt.ToStdout {
+			log.Fatalf("Error: --output-format=kustomize writes a directory and cannot be used with --stdout")
+		}
+		if opt.CreateChart {
+			log.Fatalf("Error: --output-format=kustomize cannot be used with --chart")
+		}
+		if opt.GenerateJSON {
+			log.Fatalf("Error: --output-format=kustomize only supports YAML output")
+		}
+	}
+
+	if opt.ExposeMode != kubernetes.ExposeModeIngress && opt.ExposeMode != kubernetes.ExposeModeGateway {
+		log.Fatalf("Unknown expose mode: %s, possible values are: '%s' '%s'", opt.ExposeMode, kubernetes.ExposeModeIngress, kubernetes.ExposeModeGateway)
+	}
+
 	if _, ok := kubernetes.ValidVolumeSet[opt.Volumes]; !ok {
 		validVolumesTypes := make([]string, 0)
 		for validVolumeType := range kubernetes.ValidVolumeSet {
@@ -162,97 +263,361 @@ func ValidateComposeFile(opt *kobject.ConvertOptions) error {
 	return nil
 }
 
-func validateControllers(opt *kobject.ConvertOptions) {
-	singleOutput := len(opt.OutFile) != 0 || opt.OutFile == "-" || opt.ToStdout
-	if opt.Provider == ProviderKubernetes {
-		// create deployment by default if no controller has been set
-		if !opt.CreateD && !opt.CreateDS && !opt.CreateRC && opt.Controller == "" {
-			opt.CreateD = true
-		}
-		if singleOutput {
-			count := 0
-			if opt.CreateD {
-				count++
-			}
-			if opt.CreateDS {
-				count++
-			}
-			if opt.CreateRC {
-				count++
-			}
-			if count > 1 {
-				log.Fatalf("Error: only one kind of Kubernetes resource can be generated when --out or --stdout is specified")
-			}
+// flagProviders returns the providers declaring the flag
+func flagProviders(name string) []string {
+	var names []string
+	for _, p := range transformer.Providers() {
+		if contains(p.Flags, name) {
+			names = append(names, p.Name)
 		}
-	} else if opt.Provider == ProviderOpenshift {
-		// create deploymentconfig by default if no controller has been set
-		if !opt.CreateDeploymentConfig {
-			opt.CreateDeploymentConfig = true
+	}
+	return names
+}
+
+// outputFormatProviders returns the names of the providers writing the output format
+func outputFormatProviders(format string) []string {
+	var names []string
+	for _, p := range transformer.Providers() {
+		if contains(p.OutputFormats, format) {
+			names = append(names, p.Name)
 		}
-		if singleOutput {
-			count := 0
-			if opt.CreateDeploymentConfig {
-				count++
-			}
-			// Add more controllers here once they are available in OpenShift
-			// if opt.foo {count++}
+	}
+	return names
+}
 
-			if count > 1 {
-				log.Fatalf("Error: only one kind of OpenShift resource can be generated when --out or --stdout is specified")
+// outputFormats returns the output formats of all the providers
+func outputFormats() []string {
+	var formats []string
+	for _, p := range transformer.Providers() {
+		for _, format := range p.OutputFormats {
+			if !contains(formats, format) {
+				formats = append(formats, format)
 			}
 		}
 	}
+	return formats
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// isOutFile returns true if --out is an existing file, or names a YAML or JSON file
+func isOutFile(out string) bool {
+	if info, err := os.Stat(out); err == nil {
+		return !info.IsDir()
+	}
+	switch strings.ToLower(filepath.Ext(out)) {
+	case ".yaml", ".yml", ".json":
+		return true
+	}
+	return false
+}
+
+// setProviderDefaults sets the options the provider derives from the others, e.g. the controller generated by default
+func setProviderDefaults(opt *kobject.ConvertOptions) {
+	provider, err := transformer.GetProvider(opt.Provider)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if provider.SetDefaults != nil {
+		if err := provider.SetDefaults(opt); err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+	}
 }
 
 // Convert transforms docker compose or dab file to k8s objects
 func Convert(opt kobject.ConvertOptions) ([]runtime.Object, error) {
-	validateControllers(&opt)
+	setProviderDefaults(&opt)
 
 	// loader parses input from file into komposeObject.
-	l, err := loader.GetLoader(inputFormat)
+	l, err := getLoader(opt)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Unset variables become required parameters of the Template
+	if c, ok := l.(*compose.Compose); ok && opt.OutputFormat == openshift.OutputFormatTemplate {
+		c.KeepUnresolvedVariables = true
+	}
+
+	// Get a transformer that maps komposeObject to provider's primitives
+	t := getTransformer(opt)
+
+	if opt.OutputFormat == kubernetes.OutputFormatKustomize {
+		return convertKustomize(l, t, opt)
+	}
+
+	objects := loadAndTransform(l, t, opt.InputFiles, opt)
+
+	// Print output
+	err = kubernetes.PrintList(objects, opt)
+	if err != nil {
+		log.Fatalf(err.Error())
+	}
+	return objects, err
+}
+
+// ValidateDiffFlags validates the flags of kompose diff, the manifests to compare are the --out file or directory
+func ValidateDiffFlags(opt kobject.ConvertOptions) {
+	if opt.OutFile == "" || opt.ToStdout {
+		log.Fatalf("Error: kompose diff compares the converted objects with the manifests of --out, --out is required")
+	}
+	if _, err := os.Stat(opt.OutFile); err != nil {
+		log.Fatalf("Error: %s does not exist, run kompose convert first", opt.OutFile)
+	}
+	if archive.IsArchive(opt.OutFile) {
+		log.Fatalf("Error: kompose diff cannot compare the manifests of an archive")
+	}
+}
+
+// Diff converts the compose files in memory and writes the differences with the manifests of --out to stdout.
+// It returns true if the manifests differ from the converted objects.
+func Diff(opt kobject.ConvertOptions) bool {
+	setProviderDefaults(&opt)
+
+	l, err := getLoader(opt)
+	if err != nil {
+		log.Fatal(err)
+	}
+	t := getTransformer(opt)
+	objects := loadAndTransform(l, t, opt.InputFiles, opt)
+
+	differ, err := kubernetes.DiffList(objects, opt, os.Stdout)
+	if err != nil {
+		log.Fatalf(err.Error())
+	}
+	return differ
+}
+
+// ValidateDownFlags validates the flags of kompose down, only the name of the project is loaded from the compose files
+func ValidateDownFlags(args []string, opt *kobject.ConvertOptions) {
+	if _, err := transformer.GetProvider(opt.Provider); err != nil {
+		log.Fatal(err)
+	}
+	if len(args) != 0 {
+		log.Fatal("Unknown Argument(s): ", strings.Join(args, ","))
+	}
+	if err := ValidateComposeFile(opt); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// Up converts the compose files and applies the objects to the cluster of the kubeconfig, labeled with the
+// compose project, then waits for their rollout with opt.Wait
+func Up(opt kobject.ConvertOptions) {
+	setProviderDefaults(&opt)
+
+	l, err := getLoader(opt)
 	if err != nil {
 		log.Fatal(err)
 	}
+	komposeObject := load(l, opt.InputFiles, opt)
+	objects := transform(getTransformer(opt), komposeObject, opt)
+
+	client := newClusterClient(opt)
+	client.AssignNamespace(objects)
+	ctx := context.Background()
+	if err := client.Apply(ctx, objects, komposeObject.ProjectName); err != nil {
+		log.Fatalf("Error while deploying application: %v", err)
+	}
+	if opt.Wait {
+		if err := client.Wait(ctx, objects, opt.WaitTimeout); err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+	}
+	log.Infof("Successfully created the objects of the project %q in namespace %q", komposeObject.ProjectName, client.Namespace)
+}
 
-	komposeObject := kobject.KomposeObject{
-		ServiceConfigs: make(map[string]kobject.ServiceConfig),
+// Down deletes the objects of the compose project from the cluster of the kubeconfig
+func Down(opt kobject.ConvertOptions) {
+	l, err := getLoader(opt)
+	if err != nil {
+		log.Fatal(err)
 	}
-	komposeObject, err = l.LoadFile(opt.InputFiles)
+	komposeObject := load(l, opt.InputFiles, opt)
+
+	client := newClusterClient(opt)
+	if err := client.Delete(context.Background(), komposeObject.ProjectName); err != nil {
+		log.Fatalf("Error while deleting application: %v", err)
+	}
+}
+
+// newClusterClient returns the client of the cluster of the kubeconfig, or of --server, in the namespace of --namespace
+func newClusterClient(opt kobject.ConvertOptions) *cluster.Client {
+	client, err := cluster.NewClient(opt.Server)
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	if opt.Namespace != "" {
+		client.Namespace = opt.Namespace
+	}
+	return client
+}
+
+// ValidateReverseFlags validates the flags of kompose reverse, the manifests are the files of --file
+func ValidateReverseFlags(opt kobject.ConvertOptions) {
+	if len(opt.InputFiles) == 0 {
+		log.Fatalf("Error: kompose reverse needs the manifests to convert, set them with --file")
+	}
+	for _, file := range opt.InputFiles {
+		if _, err := os.Stat(file); err != nil {
+			log.Fatalf("Error: %s does not exist", file)
+		}
+	}
+}
+
+// Reverse loads the workloads of the Kubernetes manifests of opt.InputFiles as services and writes their
+// compose file to opt.OutFile, or to stdout
+func Reverse(opt kobject.ConvertOptions) {
+	l, err := loader.GetLoader("kubernetes")
+	if err != nil {
+		log.Fatal(err)
+	}
+	komposeObject, err := l.LoadFile(opt.InputFiles)
+	if err != nil {
+		log.Fatalf(err.Error())
+	}
+	if len(komposeObject.ServiceConfigs) == 0 {
+		log.Fatalf("Error: no Deployment, StatefulSet or DaemonSet was found in the manifests")
+	}
+
+	indent := opt.YAMLIndent
+	if indent == 0 {
+		indent = 2
+	}
+	data, err := compose.Marshal(komposeObject, indent)
 	if err != nil {
 		log.Fatalf(err.Error())
 	}
+	if opt.OutFile == "" {
+		os.Stdout.Write(data)
+		return
+	}
+	if err := os.WriteFile(opt.OutFile, data, 0644); err != nil {
+		log.Fatalf("Error: failed to write %s: %v", opt.OutFile, err)
+	}
+	log.Infof("Compose file %q created", opt.OutFile)
+}
+
+// loadAndTransform loads the given compose files and maps them to provider's primitives
+func loadAndTransform(l loader.Loader, t transformer.Transformer, files []string, opt kobject.ConvertOptions) []runtime.Object {
+	return transform(t, load(l, files, opt), opt)
+}
+
+// load loads the given compose files
+func load(l loader.Loader, files []string, opt kobject.ConvertOptions) kobject.KomposeObject {
+	komposeObject, err := l.LoadFile(files)
+	if err != nil {
+		log.Fatalf(err.Error())
+	}
+	if komposeObject.LoadedFrom == "" {
+		komposeObject.LoadedFrom = l.Name()
+	}
 
 	komposeObject.Namespace = opt.Namespace
+	return komposeObject
+}
 
-	// Get a transformer that maps komposeObject to provider's primitives
-	t := getTransformer(opt)
+// getLoader returns the loader of opt.InputFormat, or of the format detected from the input files
+func getLoader(opt kobject.ConvertOptions) (loader.Loader, error) {
+	format := opt.InputFormat
+	if format == "" {
+		format = loader.Detect(opt.InputFiles)
+		log.Debugf("Detected the input format %s", format)
+	}
+	return loader.GetLoader(format)
+}
 
+// transform maps the loaded compose files to provider's primitives
+func transform(t transformer.Transformer, komposeObject kobject.KomposeObject, opt kobject.ConvertOptions) []runtime.Object {
 	// Do the transformation
 	objects, err := t.Transform(komposeObject, opt)
+	if err != nil {
+		log.Fatalf(err.Error())
+	}
 
+	if len(opt.Patches) != 0 {
+		objects, err = transformer.ApplyPatches(objects, opt.Patches)
+		if err != nil {
+			log.Fatalf(err.Error())
+		}
+	}
+
+	if len(opt.Plugins) != 0 {
+		objects, err = transformer.RunPlugins(objects, komposeObject, opt.Plugins)
+		if err != nil {
+			log.Fatalf(err.Error())
+		}
+	}
+
+	if opt.Validate {
+		validateObjects(objects, opt.MinKubernetesVersion)
+	}
+	return objects
+}
+
+// validateObjects reports the validation failures of the objects by service, nothing is written when an object is invalid
+func validateObjects(objects []runtime.Object, minKubernetesVersion string) {
+	failures, err := validation.Validate(objects, minKubernetesVersion)
 	if err != nil {
 		log.Fatalf(err.Error())
 	}
+	if len(failures) == 0 {
+		log.Debugf("The objects are valid, and served from Kubernetes %s", minKubernetesVersion)
+		return
+	}
 
-	// Print output
-	err = kubernetes.PrintList(objects, opt)
+	failuresByService := map[string][]validation.Error{}
+	var services []string
+	for _, failure := range failures {
+		if _, ok := failuresByService[failure.Service]; !ok {
+			services = append(services, failure.Service)
+		}
+		failuresByService[failure.Service] = append(failuresByService[failure.Service], failure)
+	}
+	sort.Strings(services)
+	for _, service := range services {
+		log.Errorf("Service %q is not valid:", service)
+		for _, failure := range failuresByService[service] {
+			log.Errorf("  %v", failure)
+		}
+	}
+	log.Fatalf("Error: %d validation failures, no file was written", len(failures))
+}
+
+// convertKustomize converts the first compose file to a kustomize base, and the first file merged
+// with each of the following ones to an overlay holding the differences with the base
+func convertKustomize(l loader.Loader, t transformer.Transformer, opt kobject.ConvertOptions) ([]runtime.Object, error) {
+	base := loadAndTransform(l, t, opt.InputFiles[:1], opt)
+
+	var overlays []kubernetes.KustomizeOverlay
+	for _, file := range opt.InputFiles[1:] {
+		overlays = append(overlays, kubernetes.KustomizeOverlay{
+			Name:    kubernetes.KustomizeOverlayName(file),
+			Objects: loadAndTransform(l, t, []string{opt.InputFiles[0], file}, opt),
+		})
+	}
+
+	err := kubernetes.PrintKustomize(base, overlays, opt)
 	if err != nil {
 		log.Fatalf(err.Error())
 	}
-	return objects, err
+	return base, err
 }
 
 // Convenience method to return the appropriate Transformer based on
 // what provider we are using.
 func getTransformer(opt kobject.ConvertOptions) transformer.Transformer {
-	var t transformer.Transformer
-	if opt.Provider == DefaultProvider {
-		// Create/Init new Kubernetes object with CLI opts
-		t = &kubernetes.Kubernetes{Opt: opt}
-	} else {
-		// Create/Init new OpenShift object that is initialized with a newly
-		// created Kubernetes object. Openshift inherits from Kubernetes
-		t = &openshift.OpenShift{Kubernetes: kubernetes.Kubernetes{Opt: opt}}
-	}
-	return t
+	provider, err := transformer.GetProvider(opt.Provider)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return provider.New(opt)
 }
@@ -0,0 +1,216 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/kubernetes/kompose/pkg/kobject"
+	"github.com/kubernetes/kompose/pkg/loader/compose"
+	"github.com/kubernetes/kompose/pkg/transformer/kubernetes"
+	log "github.com/sirupsen/logrus"
+)
+
+var composeFilePattern = regexp.MustCompile(`^(docker-)?compose.*\.ya?ml$`)
+
+var workloadPattern = regexp.MustCompile(`(?m)^kind: (Deployment|DeploymentConfig|StatefulSet|DaemonSet)$`)
+
+// invalidFixtures are the fixtures kompose can't convert on their own
+var invalidFixtures = map[string]string{
+	"controller/compose-controller-label.yml":                              "compose v1 format",
+	"domain/docker-compose.yaml":                                           "numeric version",
+	"envvars-separators/docker-compose.yml":                                "compose v1 format",
+	"etherpad/docker-compose-no-image.yml":                                 "service without image",
+	"etherpad/docker-compose.yml":                                          "requires the variables of etherpad/envs",
+	"gitlab/docker-compose.yml":                                            "requires the variables of gitlab/envs",
+	"image-pull-secret/compose-files/docker-compose-image-pull-secret.yml": "compose v1 format",
+	"volume-mounts/named-volume/docker-compose.yml":                        "undefined named volume",
+	"volume-mounts/named-volume/docker-compose-v3.yml":                     "undefined named volume",
+	"yaml-and-yml/docker-compose.yaml":                                     "compose v1 format",
+	"yaml-and-yml/yml/docker-compose.yml":                                  "compose v1 format",
+}
+
+// fatal is raised instead of exiting when kompose fails to convert a fixture
+type fatal struct{}
+
+// convertFixture converts the compose file to a single file and returns its content,
+// ok is false if kompose can't convert it
+func convertFixture(t *testing.T, file string, opt kobject.ConvertOptions) (output []byte, ok bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			if _, isFatal := r.(fatal); !isFatal {
+				panic(r)
+			}
+			ok = false
+		}
+	}()
+
+	opt.InputFiles = []string{file}
+	opt.OutFile = filepath.Join(t.TempDir(), "output.yaml")
+	objects := loadAndTransform(&compose.Compose{}, getTransformer(opt), opt.InputFiles, opt)
+	if err := kubernetes.PrintList(objects, opt); err != nil {
+		t.Fatalf("failed to print %s: %v", file, err)
+	}
+	output, err := os.ReadFile(opt.OutFile)
+	if err != nil {
+		t.Fatalf("failed to read the output of %s: %v", file, err)
+	}
+	return output, true
+}
+
+// TestConvertIsDeterministic converts every fixture repeatedly and checks all the conversions write the same bytes
+func TestConvertIsDeterministic(t *testing.T) {
+	logger := log.StandardLogger()
+	exitFunc, out := logger.ExitFunc, logger.Out
+	logger.ExitFunc = func(int) { panic(fatal{}) }
+	logger.SetOutput(io.Discard)
+	defer func() {
+		logger.ExitFunc = exitFunc
+		logger.SetOutput(out)
+	}()
+
+	fixtures := "../../script/test/fixtures"
+	var files []string
+	err := filepath.Walk(fixtures, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(fixtures, path)
+		// The unused fixtures aren't maintained
+		if info.IsDir() && rel == "unused" {
+			return filepath.SkipDir
+		}
+		if _, invalid := invalidFixtures[filepath.ToSlash(rel)]; !invalid && !info.IsDir() && composeFilePattern.MatchString(info.Name()) {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to list the fixtures: %v", err)
+	}
+
+	testCases := map[string]struct {
+		provider         string
+		serviceGroupMode string
+	}{
+		"kubernetes":               {ProviderKubernetes, ""},
+		"kubernetes volume groups": {ProviderKubernetes, "volume"},
+		"openshift":                {ProviderOpenshift, ""},
+	}
+
+	for name, test := range testCases {
+		opt := kobject.ConvertOptions{
+			Provider:               test.provider,
+			Build:                  "none",
+			Replicas:               1,
+			Volumes:                "persistentVolumeClaim",
+			ExposeMode:             kubernetes.ExposeModeIngress,
+			YAMLIndent:             2,
+			CreateDeploymentConfig: test.provider == ProviderOpenshift,
+			ServiceGroupMode:       test.serviceGroupMode,
+			WithKomposeAnnotation:  true,
+			StableOutput:           true,
+		}
+		setProviderDefaults(&opt)
+		workloads := 0
+		for _, file := range files {
+			first, ok := convertFixture(t, file, opt)
+			if !ok {
+				t.Errorf("Case '%v': failed to convert %s", name, file)
+				continue
+			}
+			if workloadPattern.Match(first) {
+				workloads++
+			}
+			// Maps are iterated in a random order, convert a few more times to catch an unsorted iteration
+			for i := 0; i < 3; i++ {
+				if output, _ := convertFixture(t, file, opt); !bytes.Equal(first, output) {
+					t.Errorf("Case '%v': converting %s twice gives different outputs:\n%s\n---\n%s", name, file, first, output)
+					break
+				}
+			}
+		}
+		if workloads == 0 {
+			t.Errorf("Case '%v': no fixture was converted to a workload", name)
+		}
+	}
+}
+
+// TestStableOutput checks --stable-output leaves out the kompose.cmd annotation, which changes with the invocation
+func TestStableOutput(t *testing.T) {
+	testCases := map[string]struct {
+		stableOutput bool
+		cmd          bool
+	}{
+		"Stable output":  {true, false},
+		"Default output": {false, true},
+	}
+
+	for name, test := range testCases {
+		opt := kobject.ConvertOptions{
+			Provider:              ProviderKubernetes,
+			Build:                 "none",
+			Replicas:              1,
+			YAMLIndent:            2,
+			WithKomposeAnnotation: true,
+			StableOutput:          test.stableOutput,
+		}
+		setProviderDefaults(&opt)
+		output, ok := convertFixture(t, "../../script/test/fixtures/redis-example/docker-compose.yml", opt)
+		if !ok {
+			t.Fatalf("Case '%v': failed to convert the fixture", name)
+		}
+		if cmd := bytes.Contains(output, []byte("kompose.cmd:")); cmd != test.cmd {
+			t.Errorf("Case '%v' for TestStableOutput fail, Expected kompose.cmd annotation %v, got %v:\n%s", name, test.cmd, cmd, output)
+		}
+		if !bytes.Contains(output, []byte("kompose.version:")) {
+			t.Errorf("Case '%v' for TestStableOutput fail, Expected the kompose.version annotation:\n%s", name, output)
+		}
+	}
+}
+
+func TestIsOutFile(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "objects")
+	if err := os.WriteFile(file, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := map[string]struct {
+		out    string
+		isFile bool
+	}{
+		"Unset":                {"", false},
+		"Existing directory":   {dir, false},
+		"New directory":        {filepath.Join(dir, "out"), false},
+		"Existing file":        {file, true},
+		"New YAML file":        {filepath.Join(dir, "out.yaml"), true},
+		"New JSON file":        {filepath.Join(dir, "out.JSON"), true},
+		"Directory with a dot": {filepath.Join(dir, "v1.2"), false},
+	}
+
+	for name, test := range testCases {
+		if isFile := isOutFile(test.out); isFile != test.isFile {
+			t.Errorf("Case '%v' for TestIsOutFile fail, Expected %v, got %v", name, test.isFile, isFile)
+		}
+	}
+}
@@ -0,0 +1,332 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package cluster applies the converted objects to a cluster and deletes them, for kompose up and kompose down
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kubernetes/kompose/pkg/transformer"
+	"github.com/kubernetes/kompose/pkg/transformer/kubernetes"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+const (
+	// ProjectLabel is the label of the objects applied by kompose up, set to the name of the compose project
+	ProjectLabel = "io.kompose.project"
+
+	// FieldManager is the manager of the fields kompose up applies
+	FieldManager = "kompose"
+
+	// DefaultWaitTimeout is the default time kompose up waits for the rollouts
+	DefaultWaitTimeout = 5 * time.Minute
+)
+
+// pollInterval is the interval between two checks of the rollouts
+var pollInterval = 2 * time.Second
+
+// Client applies and deletes objects of any kind with the dynamic client, the resources of the kinds are found
+// with the discovery API of the cluster
+type Client struct {
+	dynamic   dynamic.Interface
+	discovery discovery.DiscoveryInterface
+	mapper    meta.RESTMapper
+
+	// Namespace is the namespace of the objects without one
+	Namespace string
+}
+
+// NewClient returns a client of the cluster of the current context of the kubeconfig, server replaces the address of the cluster
+func NewClient(server string) (*Client, error) {
+	overrides := &clientcmd.ConfigOverrides{}
+	overrides.ClusterInfo.Server = server
+	clientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(clientcmd.NewDefaultClientConfigLoadingRules(), overrides)
+	config, err := clientConfig.ClientConfig()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load the kubeconfig")
+	}
+	client, err := NewClientForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	if namespace, _, err := clientConfig.Namespace(); err == nil && namespace != "" {
+		client.Namespace = namespace
+	}
+	return client, nil
+}
+
+// NewClientForConfig returns a client of the cluster of config
+func NewClientForConfig(config *rest.Config) (*Client, error) {
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create the client")
+	}
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(config)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create the discovery client")
+	}
+	cached := memory.NewMemCacheClient(discoveryClient)
+	return &Client{
+		dynamic:   dynamicClient,
+		discovery: cached,
+		mapper:    restmapper.NewDeferredDiscoveryRESTMapper(cached),
+		Namespace: metav1.NamespaceDefault,
+	}, nil
+}
+
+// AssignNamespace moves the namespaced objects to the namespace of the client, where Delete finds them: the
+// transformers put the objects converted without --namespace in the default namespace
+func (c *Client) AssignNamespace(objects []runtime.Object) {
+	transformer.AssignNamespaceToObjects(&objects, c.Namespace)
+}
+
+// Apply applies the objects in their order with server-side apply, labeled with the project
+func (c *Client) Apply(ctx context.Context, objects []runtime.Object, project string) error {
+	if project == "" {
+		return errors.New("the compose project has no name, its objects can't be labeled")
+	}
+	for _, obj := range objects {
+		object, err := toUnstructured(obj)
+		if err != nil {
+			return err
+		}
+		// The status is written by the controllers, the creation timestamp by the API server
+		delete(object.Object, "status")
+		unstructured.RemoveNestedField(object.Object, "metadata", "creationTimestamp")
+		labels := object.GetLabels()
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		labels[ProjectLabel] = project
+		object.SetLabels(labels)
+
+		resource, err := c.resource(object)
+		if err != nil {
+			return err
+		}
+		data, err := json.Marshal(object)
+		if err != nil {
+			return errors.Wrapf(err, "failed to marshal %s %q", object.GetKind(), object.GetName())
+		}
+		force := true
+		if _, err := resource.Patch(ctx, object.GetName(), types.ApplyPatchType, data, metav1.PatchOptions{FieldManager: FieldManager, Force: &force}); err != nil {
+			return errors.Wrapf(err, "failed to apply %s %q", object.GetKind(), object.GetName())
+		}
+		log.Infof("%s %q applied", object.GetKind(), object.GetName())
+	}
+	return nil
+}
+
+// Wait waits until the rollouts of the Deployments, StatefulSets and DaemonSets of the objects are complete
+func (c *Client) Wait(ctx context.Context, objects []runtime.Object, timeout time.Duration) error {
+	for _, obj := range objects {
+		object, err := toUnstructured(obj)
+		if err != nil {
+			return err
+		}
+		switch object.GetKind() {
+		case "Deployment", "StatefulSet", "DaemonSet":
+		default:
+			continue
+		}
+		resource, err := c.resource(object)
+		if err != nil {
+			return err
+		}
+
+		log.Infof("Waiting for the rollout of %s %q", object.GetKind(), object.GetName())
+		err = wait.PollUntilContextTimeout(ctx, pollInterval, timeout, true, func(ctx context.Context) (bool, error) {
+			current, err := resource.Get(ctx, object.GetName(), metav1.GetOptions{})
+			if err != nil {
+				return false, err
+			}
+			return rolledOut(current), nil
+		})
+		if err != nil {
+			return errors.Wrapf(err, "the rollout of %s %q is not complete", object.GetKind(), object.GetName())
+		}
+	}
+	return nil
+}
+
+// rolledOut returns true when the controller has updated all the replicas of the workload and they are available
+func rolledOut(object *unstructured.Unstructured) bool {
+	field := func(fields ...string) int64 {
+		value, _, _ := unstructured.NestedInt64(object.Object, fields...)
+		return value
+	}
+	if field("status", "observedGeneration") < object.GetGeneration() {
+		return false
+	}
+	switch object.GetKind() {
+	case "DaemonSet":
+		desired := field("status", "desiredNumberScheduled")
+		return field("status", "updatedNumberScheduled") == desired && field("status", "numberAvailable") == desired
+	case "StatefulSet":
+		replicas := specReplicas(object)
+		return field("status", "updatedReplicas") == replicas && field("status", "readyReplicas") == replicas
+	default:
+		replicas := specReplicas(object)
+		return field("status", "updatedReplicas") == replicas && field("status", "availableReplicas") == replicas
+	}
+}
+
+// specReplicas returns the replicas of a workload, 1 when they aren't set
+func specReplicas(object *unstructured.Unstructured) int64 {
+	replicas, found, _ := unstructured.NestedInt64(object.Object, "spec", "replicas")
+	if !found {
+		return 1
+	}
+	return replicas
+}
+
+// Delete deletes the objects labeled with the project, from the namespace of the client for the namespaced kinds,
+// in the reverse order of their installation
+func (c *Client) Delete(ctx context.Context, project string) error {
+	// An empty project would select every object with an empty project label
+	if project == "" {
+		return errors.New("the compose project has no name, its objects can't be selected")
+	}
+	resourceLists, err := c.discovery.ServerPreferredResources()
+	if err != nil {
+		if !discovery.IsGroupDiscoveryFailedError(err) {
+			return errors.Wrap(err, "failed to discover the resources of the cluster")
+		}
+		log.Warnf("Some resources of the cluster can't be discovered, their objects are not deleted: %v", err)
+	}
+
+	var objects []unstructured.Unstructured
+	selector := metav1.ListOptions{LabelSelector: ProjectLabel + "=" + project}
+	for _, resourceList := range resourceLists {
+		gv, err := schema.ParseGroupVersion(resourceList.GroupVersion)
+		if err != nil {
+			continue
+		}
+		for _, apiResource := range resourceList.APIResources {
+			// Subresources, and resources that can't be listed or deleted, are skipped
+			if strings.Contains(apiResource.Name, "/") || !hasVerbs(apiResource.Verbs, "list", "delete") {
+				continue
+			}
+			namespaceable := c.dynamic.Resource(gv.WithResource(apiResource.Name))
+			var resource dynamic.ResourceInterface = namespaceable
+			if apiResource.Namespaced {
+				resource = namespaceable.Namespace(c.Namespace)
+			}
+			list, err := resource.List(ctx, selector)
+			if err != nil {
+				if apierrors.IsNotFound(err) || apierrors.IsMethodNotSupported(err) {
+					continue
+				}
+				return errors.Wrapf(err, "failed to list the %s", apiResource.Name)
+			}
+			for _, item := range list.Items {
+				if item.GetKind() == "" {
+					item.SetGroupVersionKind(gv.WithKind(apiResource.Kind))
+				}
+				objects = append(objects, item)
+			}
+		}
+	}
+	if len(objects) == 0 {
+		log.Infof("No object of the project %q was found", project)
+		return nil
+	}
+
+	sort.SliceStable(objects, func(i, j int) bool {
+		return kubernetes.InstallRank(&objects[i]) > kubernetes.InstallRank(&objects[j])
+	})
+	propagation := metav1.DeletePropagationBackground
+	for i := range objects {
+		object := &objects[i]
+		resource, err := c.resource(object)
+		if err != nil {
+			return err
+		}
+		err = resource.Delete(ctx, object.GetName(), metav1.DeleteOptions{PropagationPolicy: &propagation})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "failed to delete %s %q", object.GetKind(), object.GetName())
+		}
+		log.Infof("%s %q deleted", object.GetKind(), object.GetName())
+	}
+	return nil
+}
+
+// resource returns the client of the resource of the object, in the namespace of the object or of the client
+func (c *Client) resource(object *unstructured.Unstructured) (dynamic.ResourceInterface, error) {
+	gvk := object.GroupVersionKind()
+	mapping, err := c.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if meta.IsNoMatchError(err) {
+		return nil, errors.Errorf("the cluster doesn't serve %s %s", gvk.GroupVersion(), gvk.Kind)
+	} else if err != nil {
+		return nil, errors.Wrapf(err, "failed to find the resource of %s %s", gvk.GroupVersion(), gvk.Kind)
+	}
+	if mapping.Scope.Name() != meta.RESTScopeNameNamespace {
+		return c.dynamic.Resource(mapping.Resource), nil
+	}
+	if object.GetNamespace() == "" {
+		object.SetNamespace(c.Namespace)
+	}
+	return c.dynamic.Resource(mapping.Resource).Namespace(object.GetNamespace()), nil
+}
+
+// toUnstructured returns a copy of the object as an unstructured object
+func toUnstructured(obj runtime.Object) (*unstructured.Unstructured, error) {
+	if object, ok := obj.(*unstructured.Unstructured); ok {
+		return object.DeepCopy(), nil
+	}
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to convert %T", obj)
+	}
+	return &unstructured.Unstructured{Object: content}, nil
+}
+
+// hasVerbs returns true if all the verbs are in verbs
+func hasVerbs(verbs metav1.Verbs, required ...string) bool {
+	for _, verb := range required {
+		found := false
+		for _, v := range verbs {
+			if v == verb {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,229 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kubernetes/kompose/pkg/kobject"
+	"github.com/kubernetes/kompose/pkg/transformer/kubernetes"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/rest"
+)
+
+// fakeAPIServer serves the discovery API and stores the objects applied with server-side apply,
+// the Deployments are rolled out as soon as they are applied
+type fakeAPIServer struct {
+	mutex   sync.Mutex
+	objects map[string]map[string]interface{}
+	// requests are the methods and paths of the requests on the objects, in their order
+	requests []string
+}
+
+var discoveryDocuments = map[string]string{
+	"/api":  `{"kind":"APIVersions","versions":["v1"]}`,
+	"/apis": `{"kind":"APIGroupList","apiVersion":"v1","groups":[{"name":"apps","versions":[{"groupVersion":"apps/v1","version":"v1"}],"preferredVersion":{"groupVersion":"apps/v1","version":"v1"}}]}`,
+	"/api/v1": `{"kind":"APIResourceList","groupVersion":"v1","resources":[
+		{"name":"namespaces","namespaced":false,"kind":"Namespace","verbs":["get","list","patch","delete"]},
+		{"name":"services","namespaced":true,"kind":"Service","verbs":["get","list","patch","delete"]},
+		{"name":"services/status","namespaced":true,"kind":"Service","verbs":["get","patch"]},
+		{"name":"events","namespaced":true,"kind":"Event","verbs":["get","list"]}]}`,
+	"/apis/apps/v1": `{"kind":"APIResourceList","groupVersion":"apps/v1","resources":[
+		{"name":"deployments","namespaced":true,"kind":"Deployment","verbs":["get","list","patch","delete"]}]}`,
+}
+
+func (s *fakeAPIServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	if body, ok := discoveryDocuments[r.URL.Path]; ok {
+		io.WriteString(w, body)
+		return
+	}
+	if r.Method != http.MethodGet {
+		s.requests = append(s.requests, r.Method+" "+r.URL.Path)
+	}
+
+	switch r.Method {
+	case http.MethodPatch:
+		if r.Header.Get("Content-Type") != "application/apply-patch+yaml" || r.URL.Query().Get("fieldManager") != FieldManager {
+			http.Error(w, "not a server-side apply", http.StatusBadRequest)
+			return
+		}
+		object := map[string]interface{}{}
+		if err := json.NewDecoder(r.Body).Decode(&object); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		metadata := object["metadata"].(map[string]interface{})
+		metadata["generation"] = 1
+		if object["kind"] == "Deployment" {
+			object["status"] = map[string]interface{}{"observedGeneration": 1, "updatedReplicas": 1, "availableReplicas": 1}
+		}
+		s.objects[r.URL.Path] = object
+		json.NewEncoder(w).Encode(object)
+	case http.MethodDelete:
+		delete(s.objects, r.URL.Path)
+		io.WriteString(w, `{"kind":"Status","apiVersion":"v1","status":"Success"}`)
+	case http.MethodGet:
+		if object, ok := s.objects[r.URL.Path]; ok {
+			json.NewEncoder(w).Encode(object)
+			return
+		}
+		// A list of the objects matching the label selector
+		selector := strings.SplitN(r.URL.Query().Get("labelSelector"), "=", 2)
+		var items []interface{}
+		for path, object := range s.objects {
+			labels, _ := object["metadata"].(map[string]interface{})["labels"].(map[string]interface{})
+			name := strings.TrimPrefix(path, r.URL.Path+"/")
+			if name != path && !strings.Contains(name, "/") && len(selector) == 2 && labels[selector[0]] == selector[1] {
+				items = append(items, object)
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"kind": "List", "apiVersion": "v1", "metadata": map[string]interface{}{}, "items": items})
+	}
+}
+
+func TestUpAndDown(t *testing.T) {
+	server := &fakeAPIServer{objects: map[string]map[string]interface{}{}}
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+	pollInterval = 10 * time.Millisecond
+
+	client, err := NewClientForConfig(&rest.Config{Host: httpServer.URL})
+	if err != nil {
+		t.Fatalf("NewClientForConfig failed: %v", err)
+	}
+	client.Namespace = "test"
+
+	// The objects are converted like kompose up does, without --namespace
+	komposeObject := kobject.KomposeObject{ServiceConfigs: map[string]kobject.ServiceConfig{"web": {
+		Name:          "web",
+		ContainerName: "web",
+		Image:         "nginx",
+		Port:          []kobject.Ports{{HostPort: 80, ContainerPort: 80, Protocol: string(corev1.ProtocolTCP)}},
+	}}}
+	objects, err := (&kubernetes.Kubernetes{}).Transform(komposeObject, kobject.ConvertOptions{CreateD: true, Replicas: 1})
+	if err != nil {
+		t.Fatalf("Transform failed: %v", err)
+	}
+	client.AssignNamespace(objects)
+	other := &corev1.Service{
+		TypeMeta:   metav1.TypeMeta{Kind: "Service", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: "other"},
+	}
+
+	ctx := context.Background()
+	if err := client.Apply(ctx, objects, "app"); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if err := client.Apply(ctx, []runtime.Object{other}, "other"); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if err := client.Wait(ctx, objects, time.Second); err != nil {
+		t.Fatalf("Wait failed: %v", err)
+	}
+	deployment := server.objects["/apis/apps/v1/namespaces/test/deployments/web"]
+	if deployment == nil {
+		t.Fatalf("Expected the Deployment to be applied in the namespace of the client, got %v", server.requests)
+	}
+	if label := deployment["metadata"].(map[string]interface{})["labels"].(map[string]interface{})[ProjectLabel]; label != "app" {
+		t.Errorf("Expected the project label app, got %v", label)
+	}
+	if _, ok := deployment["metadata"].(map[string]interface{})["creationTimestamp"]; ok {
+		t.Errorf("Expected the creation timestamp to be left out of the applied Deployment")
+	}
+
+	if err := client.Delete(ctx, "app"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	expected := []string{
+		"PATCH /api/v1/namespaces/test/services/web",
+		"PATCH /apis/apps/v1/namespaces/test/deployments/web",
+		"PATCH /api/v1/namespaces/test/services/other",
+		// The objects are deleted in the reverse order of their installation
+		"DELETE /apis/apps/v1/namespaces/test/deployments/web",
+		"DELETE /api/v1/namespaces/test/services/web",
+	}
+	if !reflect.DeepEqual(server.requests, expected) {
+		t.Errorf("Expected the requests %v, got %v", expected, server.requests)
+	}
+	if _, ok := server.objects["/api/v1/namespaces/test/services/other"]; !ok {
+		t.Errorf("Expected the Service of the other project to be kept")
+	}
+}
+
+func TestEmptyProject(t *testing.T) {
+	server := &fakeAPIServer{objects: map[string]map[string]interface{}{}}
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+
+	client, err := NewClientForConfig(&rest.Config{Host: httpServer.URL})
+	if err != nil {
+		t.Fatalf("NewClientForConfig failed: %v", err)
+	}
+	service := &corev1.Service{
+		TypeMeta:   metav1.TypeMeta{Kind: "Service", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: "web"},
+	}
+
+	ctx := context.Background()
+	if err := client.Apply(ctx, []runtime.Object{service}, ""); err == nil {
+		t.Errorf("Expected Apply to fail without a project")
+	}
+	if err := client.Delete(ctx, ""); err == nil {
+		t.Errorf("Expected Delete to fail without a project")
+	}
+	if len(server.requests) != 0 {
+		t.Errorf("Expected no request to the cluster, got %v", server.requests)
+	}
+}
+
+func TestRolledOut(t *testing.T) {
+	testCases := map[string]struct {
+		object   string
+		expected bool
+	}{
+		"deployment rolled out":   {`{"kind":"Deployment","metadata":{"generation":2},"spec":{"replicas":2},"status":{"observedGeneration":2,"updatedReplicas":2,"availableReplicas":2}}`, true},
+		"deployment old replicas": {`{"kind":"Deployment","metadata":{"generation":2},"spec":{"replicas":2},"status":{"observedGeneration":2,"updatedReplicas":1,"availableReplicas":2}}`, false},
+		"deployment not observed": {`{"kind":"Deployment","metadata":{"generation":2},"status":{"observedGeneration":1,"updatedReplicas":1,"availableReplicas":1}}`, false},
+		"statefulset not ready":   {`{"kind":"StatefulSet","metadata":{"generation":1},"status":{"observedGeneration":1,"updatedReplicas":1,"readyReplicas":0}}`, false},
+		"daemonset rolled out":    {`{"kind":"DaemonSet","metadata":{"generation":1},"status":{"observedGeneration":1,"desiredNumberScheduled":3,"updatedNumberScheduled":3,"numberAvailable":3}}`, true},
+	}
+
+	for name, test := range testCases {
+		object := &unstructured.Unstructured{}
+		if err := object.UnmarshalJSON([]byte(test.object)); err != nil {
+			t.Fatal(err)
+		}
+		if rolledOut(object) != test.expected {
+			t.Errorf("Case '%v' for TestRolledOut fail, Expected %v, got %v", name, test.expected, !test.expected)
+		}
+	}
+}
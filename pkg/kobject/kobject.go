@@ -41,6 +41,12 @@ type KomposeObject struct {
 
 	// Namespace is the namespace where all the generated objects would be assigned to
 	Namespace string
+
+	// ProjectName is the name of the compose project, set with name or from the directory of the compose file
+	ProjectName string
+
+	// UnresolvedVariables are the compose variables that were not set at load time and were kept as ${NAME}
+	UnresolvedVariables []string
 }
 
 // ConvertOptions holds all options that controls transformation process
@@ -65,6 +71,7 @@ type ConvertOptions struct {
 	InsecureRepository          bool
 	Replicas                    int
 	InputFiles                  []string
+	InputFormat                 string
 	OutFile                     string
 	Provider                    string
 	Namespace                   string
@@ -79,17 +86,62 @@ type ConvertOptions struct {
 	BuildCommand string
 	PushCommand  string
 
+	// Server is the address of the cluster of kompose up and kompose down, instead of the one of the kubeconfig
 	Server string
 
+	// Wait waits for the rollouts of the objects applied by kompose up, at most WaitTimeout
+	Wait        bool
+	WaitTimeout time.Duration
+
 	YAMLIndent int
 
 	WithKomposeAnnotation bool
 
+	// StableOutput leaves out the annotations that change with the invocation, so that converting
+	// the same compose files always writes the same bytes
+	StableOutput bool
+
 	MultipleContainerMode   bool
 	ServiceGroupMode        string
 	ServiceGroupName        string
 	SecretsAsFiles          bool
 	GenerateNetworkPolicies bool
+
+	GeneratePodDisruptionBudgets bool
+
+	ExposeMode string
+	Gateway    string
+
+	OutputFormat string
+
+	// NumberedFiles prefixes the files written to a directory with their position in the install order
+	NumberedFiles bool
+
+	// Layout is the layout of the files written to a directory, "flat" or "per-service"
+	Layout string
+
+	// Validate checks the generated objects against the structure of their API types before writing them, and that
+	// their API versions are served by MinKubernetesVersion
+	Validate             bool
+	MinKubernetesVersion string
+
+	// Merge keeps the changes made by hand to the manifests of OutFile since the last conversion
+	Merge bool
+
+	// Patches are the files and directories of the patches applied to the converted objects
+	Patches []string
+	// Plugins are the executables the converted objects are piped through, after the patches
+	Plugins []string
+
+	// Labels and Annotations are added to every generated object and pod template
+	Labels      map[string]string
+	Annotations map[string]string
+
+	ChartName        string
+	ChartVersion     string
+	AppVersion       string
+	ChartDescription string
+	ChartPackage     bool
 }
 
 // IsPodController indicate if the user want to use a controller
@@ -144,6 +196,8 @@ type ServiceConfig struct {
 	BuildLabels                   map[string]string  `compose:"build-labels"`
 	ExposeServiceTLS              string             `compose:"kompose.service.expose.tls-secret"`
 	ExposeServiceIngressClassName string             `compose:"kompose.service.expose.ingress-class-name"`
+	ExposeServiceMode             string             `compose:"kompose.service.expose.mode"`
+	ExposeServiceGateway          string             `compose:"kompose.service.expose.gateway"`
 	ImagePullSecret               string             `compose:"kompose.image-pull-secret"`
 	Stdin                         bool               `compose:"stdin_open"`
 	Tty                           bool               `compose:"tty"`
@@ -151,6 +205,13 @@ type ServiceConfig struct {
 	MemReservation                types.UnitBytes    `compose:""`
 	DeployMode                    string             `compose:""`
 	VolumeMountSubPath            string             `compose:"kompose.volume.subpath"`
+	CronJobSchedule               string             `compose:"kompose.cronjob.schedule"`
+	CronJobConcurrencyPolicy      string             `compose:"kompose.cronjob.concurrency-policy"`
+	CronJobBackoffLimit           *int32             `compose:"kompose.cronjob.backoff-limit"`
+	HPAMinReplicas                int32              `compose:"kompose.hpa.replicas.min"`
+	HPAMaxReplicas                int32              `compose:"kompose.hpa.replicas.max"`
+	HPACPU                        int32              `compose:"kompose.hpa.cpu"`
+	HPAMemory                     int32              `compose:"kompose.hpa.memory"`
 	// DeployLabels mapping to kubernetes labels
 	DeployLabels       map[string]string  `compose:""`
 	DeployUpdateConfig types.UpdateConfig `compose:""`
@@ -169,6 +230,7 @@ type ServiceConfig struct {
 	ConfigsMetaData types.Configs `compose:""`
 
 	WithKomposeAnnotation bool `compose:""`
+	StableOutput          bool `compose:""`
 	InGroup               bool
 }
 
@@ -299,7 +361,8 @@ func (s *ServiceConfig) GetOSUpdateStrategy() *deployapi.RollingDeploymentStrate
 			v := intstr.FromInt(cast.ToInt(*config.Parallelism))
 			r.MaxUnavailable = &v
 		}
-		*r.MaxSurge = intstr.FromInt(0)
+		v := intstr.FromInt(0)
+		r.MaxSurge = &v
 		r.UpdatePeriodSeconds = &interval
 		return &r
 	}
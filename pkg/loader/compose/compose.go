@@ -20,11 +20,15 @@ import (
 	"fmt"
 	"os"
 	"reflect"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/compose-spec/compose-go/cli"
+	"github.com/compose-spec/compose-go/loader"
+	"github.com/compose-spec/compose-go/template"
 	"github.com/compose-spec/compose-go/types"
 	"github.com/fatih/structs"
 	"github.com/google/shlex"
@@ -33,6 +37,7 @@ import (
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cast"
+	"gopkg.in/yaml.v3"
 	api "k8s.io/api/core/v1"
 )
 
@@ -41,6 +46,35 @@ var StdinData []byte
 
 // Compose is docker compose file loader, implements Loader interface
 type Compose struct {
+	// KeepUnresolvedVariables leaves the variables that are not set in the environment as ${NAME}
+	// instead of replacing them with an empty string, their names are recorded in
+	// KomposeObject.UnresolvedVariables
+	KeepUnresolvedVariables bool
+}
+
+// unresolvedVariablePattern matches the variable references that have no default value:
+// $NAME, ${NAME} and the required forms ${NAME?err} and ${NAME:?err}
+var unresolvedVariablePattern = regexp.MustCompile(`^\$(?:([_a-zA-Z][_a-zA-Z0-9]*)|\{([_a-zA-Z][_a-zA-Z0-9]*)(?::?\?[^}]*)?\})$`)
+
+// keepUnresolvedVariables returns a load option substituting the unset variables that have no default
+// value with ${NAME}, and recording their names in unresolved
+func keepUnresolvedVariables(unresolved map[string]bool) func(*loader.Options) {
+	return func(options *loader.Options) {
+		lookup := options.Interpolate.LookupValue
+		replace := func(substring string, mapping template.Mapping, cfg *template.Config) (string, error) {
+			if matches := unresolvedVariablePattern.FindStringSubmatch(substring); matches != nil {
+				name := matches[1] + matches[2]
+				if _, ok := lookup(name); !ok {
+					unresolved[name] = true
+					return "${" + name + "}", nil
+				}
+			}
+			return template.DefaultReplacementFunc(substring, mapping, cfg)
+		}
+		options.Interpolate.Substitute = func(value string, mapping template.Mapping) (string, error) {
+			return template.SubstituteWithOptions(value, mapping, template.WithReplacementFunction(replace))
+		}
+	}
 }
 
 // checkUnsupportedKey checks if compose-go project contains
@@ -156,7 +190,13 @@ func (c *Compose) LoadFile(files []string) (kobject.KomposeObject, error) {
 		return kobject.KomposeObject{}, err
 	}
 
-	projectOptions, err := cli.NewProjectOptions(files, cli.WithOsEnv, cli.WithWorkingDirectory(workingDir), cli.WithInterpolation(true))
+	unresolved := map[string]bool{}
+	optionsFns := []cli.ProjectOptionsFn{cli.WithOsEnv, cli.WithWorkingDirectory(workingDir), cli.WithInterpolation(true)}
+	if c.KeepUnresolvedVariables {
+		optionsFns = append(optionsFns, cli.WithLoadOptions(keepUnresolvedVariables(unresolved)))
+	}
+
+	projectOptions, err := cli.NewProjectOptions(files, optionsFns...)
 	if err != nil {
 		return kobject.KomposeObject{}, errors.Wrap(err, "Unable to create compose options")
 	}
@@ -170,9 +210,35 @@ func (c *Compose) LoadFile(files []string) (kobject.KomposeObject, error) {
 	if err != nil {
 		return kobject.KomposeObject{}, err
 	}
+	for name := range unresolved {
+		komposeObject.UnresolvedVariables = append(komposeObject.UnresolvedVariables, name)
+	}
+	sort.Strings(komposeObject.UnresolvedVariables)
 	return komposeObject, nil
 }
 
+// Name returns the input format of the compose loader
+func (c *Compose) Name() string {
+	return "compose"
+}
+
+// Detect reports whether the files are compose files, the first file is read from stdin or has services at its top level
+func Detect(files []string) bool {
+	if len(files) == 0 || files[0] == "-" {
+		return true
+	}
+	data, err := os.ReadFile(files[0])
+	if err != nil {
+		return false
+	}
+	document := map[string]interface{}{}
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return false
+	}
+	_, ok := document["services"]
+	return ok
+}
+
 func loadPlacement(placement types.Placement) kobject.Placement {
 	komposePlacement := kobject.Placement{
 		PositiveConstraints: make(map[string]string),
@@ -442,6 +508,7 @@ func dockerComposeToKomposeMapping(composeObject *types.Project) (kobject.Kompos
 		ServiceConfigs: make(map[string]kobject.ServiceConfig),
 		LoadedFrom:     "compose",
 		Secrets:        composeObject.Secrets,
+		ProjectName:    composeObject.Name,
 	}
 
 	// Step 2. Parse through the object and convert it to kobject.KomposeObject!
@@ -602,7 +669,12 @@ func parseNetwork(composeServiceConfig *types.ServiceConfig, serviceConfig *kobj
 		}
 	} else {
 		var alias = ""
+		keys := make([]string, 0, len(composeServiceConfig.Networks))
 		for key := range composeServiceConfig.Networks {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
 			alias = key
 			netName := composeObject.Networks[alias].Name
 
@@ -715,12 +787,53 @@ func parseKomposeLabels(labels map[string]string, serviceConfig *kobject.Service
 			serviceConfig.ExposeServiceTLS = value
 		case LabelServiceExposeIngressClassName:
 			serviceConfig.ExposeServiceIngressClassName = value
+		case LabelServiceExposeMode:
+			mode, err := handleExposeMode(value)
+			if err != nil {
+				return errors.Wrap(err, "handleExposeMode failed")
+			}
+
+			serviceConfig.ExposeServiceMode = mode
+		case LabelServiceExposeGateway:
+			serviceConfig.ExposeServiceGateway = value
 		case LabelImagePullSecret:
 			serviceConfig.ImagePullSecret = value
 		case LabelImagePullPolicy:
 			serviceConfig.ImagePullPolicy = value
 		case LabelContainerVolumeSubpath:
 			serviceConfig.VolumeMountSubPath = value
+		case LabelCronJobSchedule:
+			serviceConfig.CronJobSchedule = value
+		case LabelCronJobConcurrencyPolicy:
+			policy, err := handleCronJobConcurrencyPolicy(value)
+			if err != nil {
+				return errors.Wrap(err, "handleCronJobConcurrencyPolicy failed")
+			}
+
+			serviceConfig.CronJobConcurrencyPolicy = string(policy)
+		case LabelCronJobBackoffLimit:
+			backoffLimit, err := cast.ToInt32E(value)
+			if err != nil {
+				return errors.Wrap(err, "invalid value for "+LabelCronJobBackoffLimit)
+			}
+
+			serviceConfig.CronJobBackoffLimit = &backoffLimit
+		case LabelHPAMinReplicas, LabelHPAMaxReplicas, LabelHPACPU, LabelHPAMemory:
+			v, err := handleHPAValue(key, value)
+			if err != nil {
+				return errors.Wrap(err, "handleHPAValue failed")
+			}
+
+			switch key {
+			case LabelHPAMinReplicas:
+				serviceConfig.HPAMinReplicas = v
+			case LabelHPAMaxReplicas:
+				serviceConfig.HPAMaxReplicas = v
+			case LabelHPACPU:
+				serviceConfig.HPACPU = v
+			case LabelHPAMemory:
+				serviceConfig.HPAMemory = v
+			}
 		default:
 			serviceConfig.Labels[key] = value
 		}
@@ -734,6 +847,34 @@ func parseKomposeLabels(labels map[string]string, serviceConfig *kobject.Service
 		return errors.New("kompose.service.expose.ingress-class-name was specified without kompose.service.expose")
 	}
 
+	if serviceConfig.ExposeService == "" && serviceConfig.ExposeServiceMode != "" {
+		return errors.New("kompose.service.expose.mode was specified without kompose.service.expose")
+	}
+
+	if serviceConfig.ExposeService == "" && serviceConfig.ExposeServiceGateway != "" {
+		return errors.New("kompose.service.expose.gateway was specified without kompose.service.expose")
+	}
+
+	if serviceConfig.CronJobSchedule == "" && serviceConfig.CronJobConcurrencyPolicy != "" {
+		return errors.New("kompose.cronjob.concurrency-policy was specified without kompose.cronjob.schedule")
+	}
+
+	if serviceConfig.CronJobSchedule == "" && serviceConfig.CronJobBackoffLimit != nil {
+		return errors.New("kompose.cronjob.backoff-limit was specified without kompose.cronjob.schedule")
+	}
+
+	if serviceConfig.HPAMaxReplicas == 0 && (serviceConfig.HPAMinReplicas != 0 || serviceConfig.HPACPU != 0 || serviceConfig.HPAMemory != 0) {
+		return errors.New("kompose.hpa.* labels were specified without kompose.hpa.replicas.max")
+	}
+
+	if serviceConfig.HPAMinReplicas > serviceConfig.HPAMaxReplicas {
+		return errors.New("kompose.hpa.replicas.min can't be greater than kompose.hpa.replicas.max")
+	}
+
+	if serviceConfig.CronJobSchedule == "" && serviceConfig.Labels[LabelControllerType] == "cronjob" {
+		return errors.New("kompose.controller.type cronjob was specified without kompose.cronjob.schedule")
+	}
+
 	if serviceConfig.ServiceType != string(api.ServiceTypeNodePort) && serviceConfig.NodePortPort != 0 {
 		return errors.New("kompose.service.type must be nodeport when assign node port value")
 	}
@@ -28,6 +28,7 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/kubernetes/kompose/pkg/kobject"
 	"github.com/pkg/errors"
+	batchv1 "k8s.io/api/batch/v1"
 	api "k8s.io/api/core/v1"
 )
 
@@ -270,6 +271,80 @@ func TestHandleServiceType(t *testing.T) {
 	}
 }
 
+// Test if cronjob concurrency policies are parsed properly on user input
+func TestHandleCronJobConcurrencyPolicy(t *testing.T) {
+	tests := []struct {
+		labelValue string
+		policy     batchv1.ConcurrencyPolicy
+	}{
+		{"Allow", batchv1.AllowConcurrent},
+		{"forbid", batchv1.ForbidConcurrent},
+		{"Replace", batchv1.ReplaceConcurrent},
+		{"", batchv1.AllowConcurrent},
+	}
+
+	for _, tt := range tests {
+		result, err := handleCronJobConcurrencyPolicy(tt.labelValue)
+		if err != nil {
+			t.Error(errors.Wrap(err, "handleCronJobConcurrencyPolicy failed"))
+		}
+		if result != tt.policy {
+			t.Errorf("Expected %q, got %q", tt.policy, result)
+		}
+	}
+
+	if _, err := handleCronJobConcurrencyPolicy("sometimes"); err == nil {
+		t.Errorf("Expected an error for an unknown concurrency policy")
+	}
+}
+
+func TestParseKomposeLabelsHPA(t *testing.T) {
+	testCases := map[string]struct {
+		labels   map[string]string
+		expected kobject.ServiceConfig
+		fail     bool
+	}{
+		"all labels": {
+			map[string]string{LabelHPAMinReplicas: "2", LabelHPAMaxReplicas: "6", LabelHPACPU: "60%", LabelHPAMemory: "70", "tier": "web"},
+			kobject.ServiceConfig{HPAMinReplicas: 2, HPAMaxReplicas: 6, HPACPU: 60, HPAMemory: 70, Labels: map[string]string{"tier": "web"}},
+			false,
+		},
+		"missing max":          {map[string]string{LabelHPACPU: "50"}, kobject.ServiceConfig{}, true},
+		"min greater than max": {map[string]string{LabelHPAMinReplicas: "4", LabelHPAMaxReplicas: "2"}, kobject.ServiceConfig{}, true},
+		"invalid cpu":          {map[string]string{LabelHPAMaxReplicas: "2", LabelHPACPU: "half"}, kobject.ServiceConfig{}, true},
+	}
+
+	for name, test := range testCases {
+		serviceConfig := kobject.ServiceConfig{}
+		err := parseKomposeLabels(test.labels, &serviceConfig)
+		if (err != nil) != test.fail {
+			t.Errorf("Case '%v' for TestParseKomposeLabelsHPA fail, Expected error %v, got %v", name, test.fail, err)
+		}
+		if err == nil && !reflect.DeepEqual(serviceConfig, test.expected) {
+			t.Errorf("Case '%v' for TestParseKomposeLabelsHPA fail, Expected %+v, got %+v", name, test.expected, serviceConfig)
+		}
+	}
+}
+
+func TestParseKomposeLabelsCronJob(t *testing.T) {
+	testCases := map[string]struct {
+		labels map[string]string
+		fail   bool
+	}{
+		"schedule":                    {map[string]string{LabelCronJobSchedule: "*/5 * * * *"}, false},
+		"controller with schedule":    {map[string]string{LabelControllerType: "cronjob", LabelCronJobSchedule: "*/5 * * * *"}, false},
+		"controller without schedule": {map[string]string{LabelControllerType: "cronjob"}, true},
+		"backoff without schedule":    {map[string]string{LabelCronJobBackoffLimit: "3"}, true},
+	}
+
+	for name, test := range testCases {
+		err := parseKomposeLabels(test.labels, &kobject.ServiceConfig{})
+		if (err != nil) != test.fail {
+			t.Errorf("Case '%v' for TestParseKomposeLabelsCronJob fail, Expected error %v, got %v", name, test.fail, err)
+		}
+	}
+}
+
 // Test loading of ports
 func TestLoadPorts(t *testing.T) {
 	portWithIPAddress, _ := types.ParsePortConfig("127.0.0.1:80:80/tcp")
@@ -601,3 +676,44 @@ func checkConstraints(t *testing.T, caseName string, output, expected map[string
 		}
 	}
 }
+
+func TestKeepUnresolvedVariables(t *testing.T) {
+	dir := t.TempDir()
+	file := dir + "/compose.yaml"
+	content := `services:
+  web:
+    image: nginx:${TAG}
+    environment:
+      PASSWORD: ${PASSWORD:?password is required}
+      LEVEL: ${LEVEL:-info}
+      HOME_DIR: ${KOMPOSE_TEST_HOME}
+`
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("KOMPOSE_TEST_HOME", "/home/web")
+
+	c := Compose{KeepUnresolvedVariables: true}
+	komposeObject, err := c.LoadFile([]string{file})
+	if err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+
+	expected := []string{"PASSWORD", "TAG"}
+	if !reflect.DeepEqual(komposeObject.UnresolvedVariables, expected) {
+		t.Errorf("Expected unresolved variables %v, got %v", expected, komposeObject.UnresolvedVariables)
+	}
+
+	service := komposeObject.ServiceConfigs["web"]
+	if service.Image != "nginx:${TAG}" {
+		t.Errorf("Expected image nginx:${TAG}, got %s", service.Image)
+	}
+	env := map[string]string{}
+	for _, e := range service.Environment {
+		env[e.Name] = e.Value
+	}
+	expectedEnv := map[string]string{"PASSWORD": "${PASSWORD}", "LEVEL": "info", "HOME_DIR": "/home/web"}
+	if !reflect.DeepEqual(env, expectedEnv) {
+		t.Errorf("Expected environment %v, got %v", expectedEnv, env)
+	}
+}
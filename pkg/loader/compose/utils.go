@@ -21,11 +21,13 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/kubernetes/kompose/pkg/kobject"
 	"github.com/pkg/errors"
 
+	batchv1 "k8s.io/api/batch/v1"
 	api "k8s.io/api/core/v1"
 )
 
@@ -44,6 +46,10 @@ const (
 	LabelServiceExposeTLSSecret = "kompose.service.expose.tls-secret"
 	// LabelServiceExposeIngressClassName provides the name of ingress class to use with the Kubernetes ingress controller
 	LabelServiceExposeIngressClassName = "kompose.service.expose.ingress-class-name"
+	// LabelServiceExposeMode defines if the service is exposed with an Ingress or a Gateway API HTTPRoute
+	LabelServiceExposeMode = "kompose.service.expose.mode"
+	// LabelServiceExposeGateway provides the parent Gateway ([namespace/]name) the HTTPRoute is attached to
+	LabelServiceExposeGateway = "kompose.service.expose.gateway"
 	// LabelServiceAccountName defines the service account name to provide the credential info of the pod.
 	LabelServiceAccountName = "kompose.serviceaccount-name"
 	// LabelControllerType defines the type of controller to be created
@@ -84,6 +90,29 @@ const (
 
 	// LabelContainerVolumeSubpath defines the volume mount subpath inside container
 	LabelContainerVolumeSubpath = "kompose.volume.subpath"
+	// LabelVolumeType defines the type of the volumes of the service
+	LabelVolumeType = "kompose.volume.type"
+	// LabelVolumeStorageClassName defines the storage class of the PersistentVolumeClaims of the service
+	LabelVolumeStorageClassName = "kompose.volume.storage-class-name"
+
+	// LabelCronJobSchedule defines the cron schedule of the CronJob to be created
+	LabelCronJobSchedule = "kompose.cronjob.schedule"
+	// LabelCronJobConcurrencyPolicy defines how concurrent executions of the CronJob are handled
+	LabelCronJobConcurrencyPolicy = "kompose.cronjob.concurrency-policy"
+	// LabelCronJobBackoffLimit defines the number of retries before the job of the CronJob is marked as failed
+	LabelCronJobBackoffLimit = "kompose.cronjob.backoff-limit"
+
+	// LabelHPAMinReplicas defines the minimum number of replicas of the HorizontalPodAutoscaler
+	LabelHPAMinReplicas = "kompose.hpa.replicas.min"
+	// LabelHPAMaxReplicas defines the maximum number of replicas of the HorizontalPodAutoscaler
+	LabelHPAMaxReplicas = "kompose.hpa.replicas.max"
+	// LabelHPACPU defines the target average cpu utilization (percentage of the requests) of the HorizontalPodAutoscaler
+	LabelHPACPU = "kompose.hpa.cpu"
+	// LabelHPAMemory defines the target average memory utilization (percentage of the requests) of the HorizontalPodAutoscaler
+	LabelHPAMemory = "kompose.hpa.memory"
+
+	// LabelPDBMinAvailable defines the minAvailable value (number or percentage) of the PodDisruptionBudget
+	LabelPDBMinAvailable = "kompose.pdb.min-available"
 )
 
 // load environment variables from compose file
@@ -169,6 +198,39 @@ func handleServiceExternalTrafficPolicy(ServiceExternalTrafficPolicyType string)
 	}
 }
 
+func handleCronJobConcurrencyPolicy(policy string) (batchv1.ConcurrencyPolicy, error) {
+	switch strings.ToLower(policy) {
+	case "", "allow":
+		return batchv1.AllowConcurrent, nil
+	case "forbid":
+		return batchv1.ForbidConcurrent, nil
+	case "replace":
+		return batchv1.ReplaceConcurrent, nil
+	default:
+		return "", errors.New("Unknown value " + policy + " , supported values are 'allow, forbid or replace'")
+	}
+}
+
+// handleHPAValue parses the value of a kompose.hpa.* label, a number of replicas or a utilization percentage
+func handleHPAValue(label, value string) (int32, error) {
+	v, err := strconv.ParseInt(strings.TrimSuffix(strings.TrimSpace(value), "%"), 10, 32)
+	if err != nil || v < 1 {
+		return 0, errors.Errorf("invalid value %q for label %s, must be an integer greater than or equal to 1", value, label)
+	}
+	return int32(v), nil
+}
+
+func handleExposeMode(mode string) (string, error) {
+	switch strings.ToLower(mode) {
+	case "", "ingress":
+		return "ingress", nil
+	case "gateway":
+		return "gateway", nil
+	default:
+		return "", errors.New("Unknown value " + mode + " , supported values are 'ingress or gateway'")
+	}
+}
+
 func normalizeContainerNames(svcName string) string {
 	return strings.ToLower(svcName)
 }
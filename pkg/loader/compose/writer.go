@@ -0,0 +1,365 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package compose
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/kubernetes/kompose/pkg/kobject"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+	api "k8s.io/api/core/v1"
+)
+
+// composeFile is the compose file written by Marshal
+type composeFile struct {
+	Name     string                    `yaml:"name,omitempty"`
+	Services map[string]composeService `yaml:"services"`
+	Volumes  map[string]composeVolume  `yaml:"volumes,omitempty"`
+}
+
+// composeService is a service of the compose file, with the keys the loader maps to a kobject.ServiceConfig
+type composeService struct {
+	Image           string              `yaml:"image,omitempty"`
+	ContainerName   string              `yaml:"container_name,omitempty"`
+	Entrypoint      []string            `yaml:"entrypoint,omitempty"`
+	Command         []string            `yaml:"command,omitempty"`
+	WorkingDir      string              `yaml:"working_dir,omitempty"`
+	Hostname        string              `yaml:"hostname,omitempty"`
+	DomainName      string              `yaml:"domainname,omitempty"`
+	User            string              `yaml:"user,omitempty"`
+	GroupAdd        []string            `yaml:"group_add,omitempty"`
+	Privileged      bool                `yaml:"privileged,omitempty"`
+	ReadOnly        bool                `yaml:"read_only,omitempty"`
+	CapAdd          []string            `yaml:"cap_add,omitempty"`
+	CapDrop         []string            `yaml:"cap_drop,omitempty"`
+	StdinOpen       bool                `yaml:"stdin_open,omitempty"`
+	Tty             bool                `yaml:"tty,omitempty"`
+	Environment     map[string]string   `yaml:"environment,omitempty"`
+	Ports           []string            `yaml:"ports,omitempty"`
+	Volumes         []string            `yaml:"volumes,omitempty"`
+	Tmpfs           []string            `yaml:"tmpfs,omitempty"`
+	Healthcheck     *composeHealthcheck `yaml:"healthcheck,omitempty"`
+	Restart         string              `yaml:"restart,omitempty"`
+	StopGracePeriod string              `yaml:"stop_grace_period,omitempty"`
+	Deploy          *composeDeploy      `yaml:"deploy,omitempty"`
+	Labels          map[string]string   `yaml:"labels,omitempty"`
+}
+
+type composeHealthcheck struct {
+	Test        []string `yaml:"test,omitempty"`
+	Interval    string   `yaml:"interval,omitempty"`
+	Timeout     string   `yaml:"timeout,omitempty"`
+	Retries     int32    `yaml:"retries,omitempty"`
+	StartPeriod string   `yaml:"start_period,omitempty"`
+	Disable     bool     `yaml:"disable,omitempty"`
+}
+
+type composeDeploy struct {
+	Mode      string            `yaml:"mode,omitempty"`
+	Replicas  *int              `yaml:"replicas,omitempty"`
+	Labels    map[string]string `yaml:"labels,omitempty"`
+	Resources *composeResources `yaml:"resources,omitempty"`
+}
+
+type composeResources struct {
+	Limits       *composeResource `yaml:"limits,omitempty"`
+	Reservations *composeResource `yaml:"reservations,omitempty"`
+}
+
+type composeResource struct {
+	CPUs   string `yaml:"cpus,omitempty"`
+	Memory string `yaml:"memory,omitempty"`
+}
+
+type composeVolume struct {
+	Labels map[string]string `yaml:"labels,omitempty"`
+}
+
+// Marshal returns the compose file of the services of the kompose object, the inverse of the mapping of the
+// compose files to a kobject.KomposeObject: loading the compose file gives back the services
+func Marshal(komposeObject kobject.KomposeObject, indent int) ([]byte, error) {
+	file := composeFile{
+		Name:     komposeObject.ProjectName,
+		Services: map[string]composeService{},
+	}
+	for name, service := range komposeObject.ServiceConfigs {
+		file.Services[name] = marshalService(service)
+		for _, volume := range service.Volumes {
+			if volume.VolumeName == "" || volume.Host != "" {
+				continue
+			}
+			if file.Volumes == nil {
+				file.Volumes = map[string]composeVolume{}
+			}
+			// The size of the PersistentVolumeClaim is a label of the named volume
+			v := file.Volumes[volume.VolumeName]
+			if volume.PVCSize != "" {
+				v.Labels = map[string]string{"kompose.volume.size": volume.PVCSize}
+			}
+			file.Volumes[volume.VolumeName] = v
+		}
+	}
+
+	var buffer bytes.Buffer
+	encoder := yaml.NewEncoder(&buffer)
+	encoder.SetIndent(indent)
+	if err := encoder.Encode(file); err != nil {
+		return nil, errors.Wrap(err, "failed to marshal the compose file")
+	}
+	return buffer.Bytes(), nil
+}
+
+// marshalService returns the compose service of a service
+func marshalService(service kobject.ServiceConfig) composeService {
+	s := composeService{
+		Image:           service.Image,
+		ContainerName:   service.ContainerName,
+		Entrypoint:      escapeAll(service.Command),
+		Command:         escapeAll(service.Args),
+		WorkingDir:      service.WorkingDir,
+		Hostname:        service.HostName,
+		DomainName:      service.DomainName,
+		User:            service.User,
+		Privileged:      service.Privileged,
+		ReadOnly:        service.ReadOnly,
+		CapAdd:          service.CapAdd,
+		CapDrop:         service.CapDrop,
+		StdinOpen:       service.Stdin,
+		Tty:             service.Tty,
+		Tmpfs:           service.TmpFs,
+		Restart:         service.Restart,
+		StopGracePeriod: service.StopGracePeriod,
+		Labels:          marshalLabels(service),
+	}
+	for _, group := range service.GroupAdd {
+		s.GroupAdd = append(s.GroupAdd, strconv.FormatInt(group, 10))
+	}
+
+	for _, env := range service.Environment {
+		if s.Environment == nil {
+			s.Environment = map[string]string{}
+		}
+		s.Environment[env.Name] = escape(env.Value)
+	}
+
+	for _, port := range service.Port {
+		p := strconv.Itoa(int(port.ContainerPort))
+		if port.HostPort != 0 {
+			p = fmt.Sprintf("%d:%s", port.HostPort, p)
+			if port.HostIP != "" {
+				p = port.HostIP + ":" + p
+			}
+		}
+		if port.Protocol != "" && port.Protocol != string(api.ProtocolTCP) {
+			p += "/" + strings.ToLower(port.Protocol)
+		}
+		s.Ports = append(s.Ports, p)
+	}
+
+	for _, volume := range service.Volumes {
+		v := volume.Container
+		switch {
+		case volume.Host != "":
+			v = volume.Host + ":" + v
+		case volume.VolumeName != "":
+			v = volume.VolumeName + ":" + v
+		}
+		if volume.Mode != "" {
+			v += ":" + volume.Mode
+		}
+		s.Volumes = append(s.Volumes, v)
+	}
+
+	if liveness := service.HealthChecks.Liveness; !reflect.DeepEqual(liveness, kobject.HealthCheck{}) {
+		s.Healthcheck = &composeHealthcheck{
+			Interval:    seconds(liveness.Interval),
+			Timeout:     seconds(liveness.Timeout),
+			Retries:     liveness.Retries,
+			StartPeriod: seconds(liveness.StartPeriod),
+			Disable:     liveness.Disable,
+		}
+		if len(liveness.Test) > 0 {
+			s.Healthcheck.Test = append([]string{"CMD"}, escapeAll(liveness.Test)...)
+		}
+	}
+
+	deploy := &composeDeploy{Mode: service.DeployMode, Labels: service.DeployLabels}
+	if service.Replicas != 0 {
+		replicas := service.Replicas
+		deploy.Replicas = &replicas
+	}
+	limits := composeResource{CPUs: cpus(service.CPULimit), Memory: memory(int64(service.MemLimit))}
+	reservations := composeResource{CPUs: cpus(service.CPUReservation), Memory: memory(int64(service.MemReservation))}
+	if limits != (composeResource{}) || reservations != (composeResource{}) {
+		deploy.Resources = &composeResources{}
+		if limits != (composeResource{}) {
+			deploy.Resources.Limits = &limits
+		}
+		if reservations != (composeResource{}) {
+			deploy.Resources.Reservations = &reservations
+		}
+	}
+	if deploy.Mode != "" || deploy.Replicas != nil || len(deploy.Labels) > 0 || deploy.Resources != nil {
+		s.Deploy = deploy
+	}
+	return s
+}
+
+// marshalLabels returns the labels of a service, with the kompose labels of the fields parseKomposeLabels and the
+// health checks parsers set
+func marshalLabels(service kobject.ServiceConfig) map[string]string {
+	labels := map[string]string{}
+	for key, value := range service.Annotations {
+		labels[key] = value
+	}
+	for key, value := range service.Labels {
+		labels[key] = value
+	}
+	set := func(key, value string) {
+		if value != "" {
+			labels[key] = value
+		}
+	}
+
+	if service.ServiceType != string(api.ServiceTypeClusterIP) {
+		set(LabelServiceType, strings.ToLower(service.ServiceType))
+	}
+	if service.ServiceExternalTrafficPolicy != string(api.ServiceExternalTrafficPolicyTypeCluster) {
+		set(LabelServiceExternalTrafficPolicy, strings.ToLower(service.ServiceExternalTrafficPolicy))
+	}
+	if service.NodePortPort != 0 {
+		set(LabelNodePortPort, strconv.Itoa(int(service.NodePortPort)))
+	}
+	if service.FsGroup != 0 {
+		set(LabelSecurityContextFsGroup, strconv.FormatInt(service.FsGroup, 10))
+	}
+	set(LabelServiceExpose, service.ExposeService)
+	set(LabelServiceExposeTLSSecret, service.ExposeServiceTLS)
+	set(LabelServiceExposeIngressClassName, service.ExposeServiceIngressClassName)
+	set(LabelServiceExposeMode, service.ExposeServiceMode)
+	set(LabelServiceExposeGateway, service.ExposeServiceGateway)
+	set(LabelImagePullSecret, service.ImagePullSecret)
+	set(LabelImagePullPolicy, service.ImagePullPolicy)
+	set(LabelContainerVolumeSubpath, service.VolumeMountSubPath)
+	set(LabelCronJobSchedule, service.CronJobSchedule)
+	set(LabelCronJobConcurrencyPolicy, service.CronJobConcurrencyPolicy)
+	if service.CronJobBackoffLimit != nil {
+		set(LabelCronJobBackoffLimit, strconv.Itoa(int(*service.CronJobBackoffLimit)))
+	}
+
+	liveness := service.HealthChecks.Liveness
+	set(HealthCheckLivenessHTTPGetPath, liveness.HTTPPath)
+	set(HealthCheckLivenessHTTPGetPort, port(liveness.HTTPPort))
+	set(HealthCheckLivenessTCPPort, port(liveness.TCPPort))
+
+	readiness := service.HealthChecks.Readiness
+	if readiness.Disable {
+		set(HealthCheckReadinessDisable, "true")
+	}
+	if len(readiness.Test) > 0 {
+		set(HealthCheckReadinessTest, shellJoin(readiness.Test))
+	}
+	set(HealthCheckReadinessInterval, seconds(readiness.Interval))
+	set(HealthCheckReadinessTimeout, seconds(readiness.Timeout))
+	if readiness.Retries != 0 {
+		set(HealthCheckReadinessRetries, strconv.Itoa(int(readiness.Retries)))
+	}
+	set(HealthCheckReadinessStartPeriod, seconds(readiness.StartPeriod))
+	set(HealthCheckReadinessHTTPGetPath, readiness.HTTPPath)
+	set(HealthCheckReadinessHTTPGetPort, port(readiness.HTTPPort))
+	set(HealthCheckReadinessTCPPort, port(readiness.TCPPort))
+
+	for key, value := range labels {
+		labels[key] = escape(value)
+	}
+	return labels
+}
+
+// seconds formats a number of seconds as a compose duration, "" for 0
+func seconds(value int32) string {
+	if value == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%ds", value)
+}
+
+// port formats a port, "" for 0
+func port(value int32) string {
+	if value == 0 {
+		return ""
+	}
+	return strconv.Itoa(int(value))
+}
+
+// cpus formats millicpus as the number of cpus of the resources of a service, "" for 0
+func cpus(milliCPUs int64) string {
+	if milliCPUs == 0 {
+		return ""
+	}
+	return strconv.FormatFloat(float64(milliCPUs)/1000, 'f', -1, 64)
+}
+
+// memory formats bytes as the memory of the resources of a service, with the largest binary unit that keeps the value exact
+func memory(bytes int64) string {
+	if bytes == 0 {
+		return ""
+	}
+	for _, unit := range []struct {
+		suffix string
+		size   int64
+	}{{"g", 1 << 30}, {"m", 1 << 20}, {"k", 1 << 10}} {
+		if bytes%unit.size == 0 {
+			return fmt.Sprintf("%d%s", bytes/unit.size, unit.suffix)
+		}
+	}
+	return strconv.FormatInt(bytes, 10)
+}
+
+// shellJoin joins words in a command line the shlex parser of the loader splits back in the same words
+func shellJoin(words []string) string {
+	quoted := make([]string, len(words))
+	for i, word := range words {
+		if word != "" && !strings.ContainsAny(word, " \t\n'\"\\#") {
+			quoted[i] = word
+			continue
+		}
+		quoted[i] = "'" + strings.ReplaceAll(word, "'", `'"'"'`) + "'"
+	}
+	return strings.Join(quoted, " ")
+}
+
+// escape escapes the $ of a value, so that the interpolation of the compose files keeps it
+func escape(value string) string {
+	return strings.ReplaceAll(value, "$", "$$")
+}
+
+// escapeAll escapes the $ of the values
+func escapeAll(values []string) []string {
+	if values == nil {
+		return nil
+	}
+	escaped := make([]string, len(values))
+	for i, value := range values {
+		escaped[i] = escape(value)
+	}
+	return escaped
+}
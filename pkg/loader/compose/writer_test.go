@@ -0,0 +1,106 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package compose
+
+import (
+	"os"
+	"sort"
+	"testing"
+
+	"github.com/compose-spec/compose-go/types"
+	"github.com/google/go-cmp/cmp"
+	"github.com/kubernetes/kompose/pkg/kobject"
+)
+
+func TestMarshal(t *testing.T) {
+	web := kobject.ServiceConfig{
+		Image:         "nginx:1.25",
+		ContainerName: "nginx",
+		Command:       []string{"/docker-entrypoint.sh"},
+		Args:          []string{"sh", "-c", "echo $HOME"},
+		WorkingDir:    "/srv",
+		HostName:      "web",
+		User:          "1000",
+		GroupAdd:      []int64{2000},
+		ReadOnly:      true,
+		CapAdd:        []string{"NET_ADMIN"},
+		Tty:           true,
+		Environment:   []kobject.EnvVar{{Name: "PRICE", Value: "$5"}, {Name: "LEVEL", Value: "info"}},
+		Port: []kobject.Ports{
+			{HostPort: 8080, ContainerPort: 80, Protocol: "TCP"},
+			{HostPort: 53, ContainerPort: 53, HostIP: "127.0.0.1", Protocol: "UDP"},
+			{ContainerPort: 443, Protocol: "TCP"},
+		},
+		Volumes: []kobject.Volumes{
+			{VolumeName: "data", Container: "/data", PVCSize: "2Gi"},
+			{Host: "/etc/web", Container: "/etc/web", Mode: "ro"},
+		},
+		TmpFs:           []string{"/tmp"},
+		StopGracePeriod: "45s",
+		Replicas:        2,
+		CPULimit:        500,
+		MemLimit:        types.UnitBytes(512 << 20),
+		MemReservation:  types.UnitBytes(1000),
+		ServiceType:     "NodePort",
+		FsGroup:         3000,
+		ImagePullPolicy: "Always",
+		Labels:          map[string]string{LabelServiceAccountName: "web"},
+		HealthChecks: kobject.HealthChecks{
+			Liveness:  kobject.HealthCheck{Test: []string{"curl", "-f", "http://localhost"}, Interval: 10, Retries: 3},
+			Readiness: kobject.HealthCheck{HTTPPath: "/ready", HTTPPort: 80, Timeout: 2},
+		},
+	}
+	komposeObject := kobject.KomposeObject{
+		ServiceConfigs: map[string]kobject.ServiceConfig{"web": web},
+		ProjectName:    "shop",
+	}
+
+	data, err := Marshal(komposeObject, 2)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	file := t.TempDir() + "/compose.yaml"
+	if err := os.WriteFile(file, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := new(Compose).LoadFile([]string{file})
+	if err != nil {
+		t.Fatalf("Failed to load the compose file %s: %v", data, err)
+	}
+
+	if loaded.ProjectName != "shop" {
+		t.Errorf("Expected the project name shop, got %s", loaded.ProjectName)
+	}
+	service := loaded.ServiceConfigs["web"]
+	sort.Slice(service.Environment, func(i, j int) bool { return service.Environment[i].Name > service.Environment[j].Name })
+	// The fields the compose loader sets without an equivalent in the kompose object
+	service.Name, service.Annotations, service.ConfigsMetaData, service.VolList, service.Network = "", nil, nil, nil, nil
+	service.Placement = kobject.Placement{}
+	// The loader keeps the kompose labels it parses
+	for key, value := range web.Labels {
+		if service.Labels[key] != value {
+			t.Errorf("Expected the label %s=%s, got %v", key, value, service.Labels)
+		}
+	}
+	service.Labels = web.Labels
+	for i := range service.Volumes {
+		service.Volumes[i].SvcName, service.Volumes[i].MountPath, service.Volumes[i].PVCName = "", "", ""
+	}
+	if diff := cmp.Diff(web, service); diff != "" {
+		t.Errorf("The loaded service differs from the marshaled one (-marshaled +loaded):\n%s", diff)
+	}
+}
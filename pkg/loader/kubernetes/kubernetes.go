@@ -0,0 +1,831 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package kubernetes loads Kubernetes manifests into a kobject.KomposeObject, for kompose reverse
+package kubernetes
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/compose-spec/compose-go/types"
+	"github.com/kubernetes/kompose/pkg/kobject"
+	"github.com/kubernetes/kompose/pkg/loader/compose"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// Kubernetes loads the Deployments, StatefulSets and DaemonSets of Kubernetes manifests as services, with the
+// Services, ConfigMaps, Secrets and PersistentVolumeClaims they use. It inverts the conversion of the kubernetes
+// transformer, what can't be expressed in a compose file is reported with a warning.
+type Kubernetes struct{}
+
+// workload is a controller of pods, one service of the compose file
+type workload struct {
+	kind           string
+	name           string
+	labels         map[string]string
+	annotations    map[string]string
+	replicas       *int32
+	template       corev1.PodTemplateSpec
+	claimTemplates []corev1.PersistentVolumeClaim
+}
+
+// manifests are the objects read from the files
+type manifests struct {
+	workloads  []workload
+	services   []corev1.Service
+	configMaps map[string]corev1.ConfigMap
+	secrets    map[string]corev1.Secret
+	claims     map[string]corev1.PersistentVolumeClaim
+
+	// used are the ConfigMaps, Secrets, PersistentVolumeClaims and Services used by the workloads, e.g. ConfigMap/web-env
+	used map[string]bool
+	// plainSecrets are the Secrets whose values were written in the compose file
+	plainSecrets map[string]bool
+}
+
+// LoadFile loads the manifests of the files, or of the YAML and JSON files of the directories
+func (k *Kubernetes) LoadFile(files []string) (kobject.KomposeObject, error) {
+	m, err := readManifests(files)
+	if err != nil {
+		return kobject.KomposeObject{}, err
+	}
+
+	komposeObject := kobject.KomposeObject{
+		ServiceConfigs: make(map[string]kobject.ServiceConfig),
+		LoadedFrom:     "kubernetes",
+	}
+	for _, w := range m.workloads {
+		if _, ok := komposeObject.ServiceConfigs[w.name]; ok {
+			log.Warnf("%s %q: a workload with the same name was already loaded, it is left out of the compose file", w.kind, w.name)
+			continue
+		}
+		service, ok := m.serviceConfig(w)
+		if ok {
+			komposeObject.ServiceConfigs[w.name] = service
+		}
+	}
+	m.reportUnused()
+	return komposeObject, nil
+}
+
+// Name returns the input format of the Kubernetes loader
+func (k *Kubernetes) Name() string {
+	return "kubernetes"
+}
+
+// Detect reports whether the files are Kubernetes manifests, the first file is a directory or its first document
+// has an apiVersion and a kind
+func Detect(files []string) bool {
+	if len(files) == 0 {
+		return false
+	}
+	info, err := os.Stat(files[0])
+	if err != nil {
+		return false
+	}
+	if info.IsDir() {
+		return true
+	}
+	file, err := os.Open(files[0])
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+	document := map[string]interface{}{}
+	if err := yaml.NewYAMLOrJSONDecoder(file, 4096).Decode(&document); err != nil {
+		return false
+	}
+	return document["apiVersion"] != nil && document["kind"] != nil
+}
+
+// readManifests reads the objects of the files, the items of the Lists are read as objects
+func readManifests(files []string) (*manifests, error) {
+	m := &manifests{
+		configMaps:   map[string]corev1.ConfigMap{},
+		secrets:      map[string]corev1.Secret{},
+		claims:       map[string]corev1.PersistentVolumeClaim{},
+		used:         map[string]bool{},
+		plainSecrets: map[string]bool{},
+	}
+
+	paths, err := manifestFiles(files)
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range paths {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read %s", path)
+		}
+		decoder := yaml.NewYAMLOrJSONDecoder(file, 4096)
+		for {
+			document := map[string]interface{}{}
+			if err := decoder.Decode(&document); err == io.EOF {
+				break
+			} else if err != nil {
+				file.Close()
+				return nil, errors.Wrapf(err, "failed to parse %s", path)
+			}
+			if len(document) == 0 {
+				continue
+			}
+			object := &unstructured.Unstructured{Object: document}
+			if object.IsList() {
+				err = object.EachListItem(func(item runtime.Object) error {
+					return m.add(item.(*unstructured.Unstructured))
+				})
+			} else {
+				err = m.add(object)
+			}
+			if err != nil {
+				file.Close()
+				return nil, errors.Wrapf(err, "failed to parse %s", path)
+			}
+		}
+		file.Close()
+	}
+	return m, nil
+}
+
+// manifestFiles returns the files, and the YAML and JSON files of the directories read recursively
+func manifestFiles(files []string) ([]string, error) {
+	var paths []string
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read %s", file)
+		}
+		if !info.IsDir() {
+			paths = append(paths, file)
+			continue
+		}
+		err = filepath.Walk(file, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			switch strings.ToLower(filepath.Ext(path)) {
+			case ".yaml", ".yml", ".json":
+				if !info.IsDir() {
+					paths = append(paths, path)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read the manifests of %s", file)
+		}
+	}
+	return paths, nil
+}
+
+// add adds an object to the manifests, the kinds that have no equivalent in a compose file are reported
+func (m *manifests) add(object *unstructured.Unstructured) error {
+	gvk := object.GroupVersionKind()
+	// kustomization.yaml and the files of a chart aren't manifests
+	if gvk.Kind == "" || gvk.Kind == "Kustomization" {
+		return nil
+	}
+
+	var err error
+	switch gvk.GroupKind().String() {
+	case "Deployment.apps":
+		var deployment appsv1.Deployment
+		if err = fromUnstructured(object, &deployment); err == nil {
+			m.workloads = append(m.workloads, workload{kind: gvk.Kind, name: deployment.Name, labels: deployment.Labels, annotations: deployment.Annotations,
+				replicas: deployment.Spec.Replicas, template: deployment.Spec.Template})
+		}
+	case "StatefulSet.apps":
+		var statefulSet appsv1.StatefulSet
+		if err = fromUnstructured(object, &statefulSet); err == nil {
+			m.workloads = append(m.workloads, workload{kind: gvk.Kind, name: statefulSet.Name, labels: statefulSet.Labels, annotations: statefulSet.Annotations,
+				replicas: statefulSet.Spec.Replicas, template: statefulSet.Spec.Template, claimTemplates: statefulSet.Spec.VolumeClaimTemplates})
+		}
+	case "DaemonSet.apps":
+		var daemonSet appsv1.DaemonSet
+		if err = fromUnstructured(object, &daemonSet); err == nil {
+			m.workloads = append(m.workloads, workload{kind: gvk.Kind, name: daemonSet.Name, labels: daemonSet.Labels, annotations: daemonSet.Annotations,
+				template: daemonSet.Spec.Template})
+		}
+	case "Service":
+		var service corev1.Service
+		if err = fromUnstructured(object, &service); err == nil {
+			m.services = append(m.services, service)
+		}
+	case "ConfigMap":
+		var configMap corev1.ConfigMap
+		if err = fromUnstructured(object, &configMap); err == nil {
+			m.configMaps[configMap.Name] = configMap
+		}
+	case "Secret":
+		var secret corev1.Secret
+		if err = fromUnstructured(object, &secret); err == nil {
+			m.secrets[secret.Name] = secret
+		}
+	case "PersistentVolumeClaim":
+		var claim corev1.PersistentVolumeClaim
+		if err = fromUnstructured(object, &claim); err == nil {
+			m.claims[claim.Name] = claim
+		}
+	default:
+		log.Warnf("%s %q is not supported by kompose reverse, it is left out of the compose file", gvk.Kind, object.GetName())
+	}
+	return err
+}
+
+// fromUnstructured converts an unstructured object to a typed one
+func fromUnstructured(object *unstructured.Unstructured, typed interface{}) error {
+	err := runtime.DefaultUnstructuredConverter.FromUnstructured(object.Object, typed)
+	return errors.Wrapf(err, "invalid %s %q", object.GetKind(), object.GetName())
+}
+
+// serviceConfig returns the service of a workload, false if the workload has no container
+func (m *manifests) serviceConfig(w workload) (kobject.ServiceConfig, bool) {
+	spec := w.template.Spec.DeepCopy()
+	if len(spec.Containers) == 0 {
+		log.Warnf("%s %q has no container, it is left out of the compose file", w.kind, w.name)
+		return kobject.ServiceConfig{}, false
+	}
+	if len(spec.Containers) > 1 {
+		var names []string
+		for _, container := range spec.Containers[1:] {
+			names = append(names, container.Name)
+		}
+		log.Warnf("%s %q: a service has one container, the containers %s are left out of the compose file", w.kind, w.name, strings.Join(names, ", "))
+	}
+	container := spec.Containers[0]
+	containerName := container.Name
+	spec.Containers = nil
+
+	service := kobject.ServiceConfig{
+		Name:        w.name,
+		Labels:      map[string]string{},
+		Annotations: map[string]string{},
+	}
+	// The kompose annotations are the labels of the compose file kompose converted, they are derived from the objects
+	for key, value := range w.annotations {
+		if !strings.HasPrefix(key, "kompose.") {
+			service.Annotations[key] = value
+		}
+	}
+	for key, value := range w.labels {
+		if !strings.HasPrefix(key, "io.kompose.") {
+			if service.DeployLabels == nil {
+				service.DeployLabels = map[string]string{}
+			}
+			service.DeployLabels[key] = value
+		}
+	}
+	if w.replicas != nil {
+		service.Replicas = int(*w.replicas)
+	}
+	switch w.kind {
+	case "StatefulSet":
+		service.Labels[compose.LabelControllerType] = "statefulset"
+	case "DaemonSet":
+		service.DeployMode = "global"
+	}
+
+	m.loadContainer(w, &container, &service)
+	m.loadVolumes(w, &container, spec, &service)
+	m.loadPodSpec(spec, &service)
+	m.loadServices(w, &service)
+
+	if fields := leftoverFields(&container); len(fields) > 0 {
+		log.Warnf("%s %q: the fields %s of the container %q are not supported, they are left out of the compose file", w.kind, w.name, strings.Join(fields, ", "), containerName)
+	}
+	if fields := leftoverFields(spec); len(fields) > 0 {
+		log.Warnf("%s %q: the fields %s of the pod are not supported, they are left out of the compose file", w.kind, w.name, strings.Join(fields, ", "))
+	}
+	return service, true
+}
+
+// loadContainer loads the fields of the container, the loaded fields are cleared
+func (m *manifests) loadContainer(w workload, container *corev1.Container, service *kobject.ServiceConfig) {
+	if container.Name != w.name {
+		service.ContainerName = container.Name
+	}
+	container.Name = ""
+	service.Image, container.Image = container.Image, ""
+	service.Command, container.Command = container.Command, nil
+	// The transformer turns $NAME into the $(NAME) of Kubernetes
+	for _, arg := range container.Args {
+		service.Args = append(service.Args, dependentVariable.ReplaceAllString(arg, `$$$1`))
+	}
+	container.Args = nil
+	service.WorkingDir, container.WorkingDir = container.WorkingDir, ""
+	service.Stdin, container.Stdin = container.Stdin, false
+	service.Tty, container.TTY = container.TTY, false
+	service.ImagePullPolicy, container.ImagePullPolicy = string(container.ImagePullPolicy), ""
+	// The defaults of the API server
+	if container.TerminationMessagePath == corev1.TerminationMessagePathDefault {
+		container.TerminationMessagePath = ""
+	}
+	if container.TerminationMessagePolicy == corev1.TerminationMessageReadFile {
+		container.TerminationMessagePolicy = ""
+	}
+
+	service.Environment = m.loadEnvironment(w, container)
+	container.Env, container.EnvFrom = nil, nil
+
+	for _, port := range container.Ports {
+		protocol := string(port.Protocol)
+		if protocol == "" {
+			protocol = string(corev1.ProtocolTCP)
+		}
+		service.Port = append(service.Port, kobject.Ports{
+			HostPort:      port.HostPort,
+			ContainerPort: port.ContainerPort,
+			HostIP:        port.HostIP,
+			Protocol:      protocol,
+		})
+	}
+
+	if sc := container.SecurityContext; sc != nil {
+		if sc.Privileged != nil {
+			service.Privileged, sc.Privileged = *sc.Privileged, nil
+		}
+		if sc.ReadOnlyRootFilesystem != nil {
+			service.ReadOnly, sc.ReadOnlyRootFilesystem = *sc.ReadOnlyRootFilesystem, nil
+		}
+		if sc.RunAsUser != nil {
+			service.User, sc.RunAsUser = fmt.Sprint(*sc.RunAsUser), nil
+		}
+		if sc.Capabilities != nil {
+			for _, capability := range sc.Capabilities.Add {
+				service.CapAdd = append(service.CapAdd, string(capability))
+			}
+			for _, capability := range sc.Capabilities.Drop {
+				service.CapDrop = append(service.CapDrop, string(capability))
+			}
+			sc.Capabilities = nil
+		}
+	}
+
+	limits, requests := container.Resources.Limits, container.Resources.Requests
+	if cpu, ok := limits[corev1.ResourceCPU]; ok {
+		service.CPULimit = cpu.MilliValue()
+		delete(limits, corev1.ResourceCPU)
+	}
+	if memory, ok := limits[corev1.ResourceMemory]; ok {
+		service.MemLimit = types.UnitBytes(memory.Value())
+		delete(limits, corev1.ResourceMemory)
+	}
+	if cpu, ok := requests[corev1.ResourceCPU]; ok {
+		service.CPUReservation = cpu.MilliValue()
+		delete(requests, corev1.ResourceCPU)
+	}
+	if memory, ok := requests[corev1.ResourceMemory]; ok {
+		service.MemReservation = types.UnitBytes(memory.Value())
+		delete(requests, corev1.ResourceMemory)
+	}
+
+	service.HealthChecks.Liveness = loadProbe(container.LivenessProbe, container.Ports)
+	service.HealthChecks.Readiness = loadProbe(container.ReadinessProbe, container.Ports)
+	// The compose file has no port names, they are left to be reported
+	for i, port := range container.Ports {
+		container.Ports[i] = corev1.ContainerPort{Name: port.Name}
+	}
+}
+
+// loadEnvironment returns the environment variables of the container, the values of the ConfigMaps and Secrets they
+// reference are written in the compose file
+func (m *manifests) loadEnvironment(w workload, container *corev1.Container) []kobject.EnvVar {
+	var environment []kobject.EnvVar
+	for _, source := range container.EnvFrom {
+		var data map[string]string
+		switch {
+		case source.ConfigMapRef != nil:
+			data = m.configMapData(w, source.ConfigMapRef.Name)
+		case source.SecretRef != nil:
+			data = m.secretData(w, source.SecretRef.Name)
+		}
+		keys := make([]string, 0, len(data))
+		for key := range data {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			environment = append(environment, kobject.EnvVar{Name: source.Prefix + key, Value: data[key]})
+		}
+	}
+
+	for _, env := range container.Env {
+		if env.ValueFrom == nil {
+			environment = append(environment, kobject.EnvVar{Name: env.Name, Value: env.Value})
+			continue
+		}
+		var data map[string]string
+		var key string
+		switch {
+		case env.ValueFrom.ConfigMapKeyRef != nil:
+			data, key = m.configMapData(w, env.ValueFrom.ConfigMapKeyRef.Name), env.ValueFrom.ConfigMapKeyRef.Key
+		case env.ValueFrom.SecretKeyRef != nil:
+			data, key = m.secretData(w, env.ValueFrom.SecretKeyRef.Name), env.ValueFrom.SecretKeyRef.Key
+		default:
+			log.Warnf("%s %q: the value of the environment variable %s is read from the pod, it is left out of the compose file", w.kind, w.name, env.Name)
+			continue
+		}
+		if value, ok := data[key]; ok {
+			environment = append(environment, kobject.EnvVar{Name: env.Name, Value: value})
+		} else if data != nil {
+			log.Warnf("%s %q: the key %s of the environment variable %s is not found, it is left out of the compose file", w.kind, w.name, key, env.Name)
+		}
+	}
+	return environment
+}
+
+// configMapData returns the data of a ConfigMap, nil if it is not in the manifests
+func (m *manifests) configMapData(w workload, name string) map[string]string {
+	configMap, ok := m.configMaps[name]
+	if !ok {
+		log.Warnf("%s %q: ConfigMap %q is not in the manifests, its environment variables are left out of the compose file", w.kind, w.name, name)
+		return nil
+	}
+	m.used["ConfigMap/"+name] = true
+	data := map[string]string{}
+	for key, value := range configMap.Data {
+		data[key] = value
+	}
+	return data
+}
+
+// secretData returns the decoded data of a Secret, nil if it is not in the manifests
+func (m *manifests) secretData(w workload, name string) map[string]string {
+	secret, ok := m.secrets[name]
+	if !ok {
+		log.Warnf("%s %q: Secret %q is not in the manifests, its environment variables are left out of the compose file", w.kind, w.name, name)
+		return nil
+	}
+	m.used["Secret/"+name] = true
+	if !m.plainSecrets[name] {
+		m.plainSecrets[name] = true
+		log.Warnf("%s %q: the values of Secret %q are written in plain text in the environment of the compose file", w.kind, w.name, name)
+	}
+	data := map[string]string{}
+	for key, value := range secret.StringData {
+		data[key] = value
+	}
+	for key, value := range secret.Data {
+		data[key] = string(value)
+	}
+	return data
+}
+
+// loadProbe returns the health check of a probe, the loaded fields are cleared
+func loadProbe(probe *corev1.Probe, ports []corev1.ContainerPort) kobject.HealthCheck {
+	if probe == nil {
+		return kobject.HealthCheck{}
+	}
+	healthCheck := kobject.HealthCheck{
+		Timeout:     probe.TimeoutSeconds,
+		Interval:    probe.PeriodSeconds,
+		Retries:     probe.FailureThreshold,
+		StartPeriod: probe.InitialDelaySeconds,
+	}
+	probe.TimeoutSeconds, probe.PeriodSeconds, probe.FailureThreshold, probe.InitialDelaySeconds = 0, 0, 0, 0
+
+	switch {
+	case probe.Exec != nil:
+		healthCheck.Test, probe.Exec = probe.Exec.Command, nil
+	case probe.HTTPGet != nil:
+		if port := portNumber(probe.HTTPGet.Port, ports); port != 0 {
+			healthCheck.HTTPPath, healthCheck.HTTPPort = probe.HTTPGet.Path, port
+			probe.HTTPGet.Path, probe.HTTPGet.Port = "", intstr.IntOrString{}
+		}
+	case probe.TCPSocket != nil:
+		if port := portNumber(probe.TCPSocket.Port, ports); port != 0 {
+			healthCheck.TCPPort, probe.TCPSocket.Port = port, intstr.IntOrString{}
+		}
+	}
+	return healthCheck
+}
+
+// portNumber returns the number of a port of the container, 0 if a named port is not found
+func portNumber(port intstr.IntOrString, ports []corev1.ContainerPort) int32 {
+	if port.Type == intstr.Int {
+		return port.IntVal
+	}
+	for _, p := range ports {
+		if p.Name == port.StrVal {
+			return p.ContainerPort
+		}
+	}
+	return 0
+}
+
+// loadVolumes loads the volumes mounted in the container, the inverse of ConfigVolumes and ConfigTmpfs of the
+// kubernetes transformer. The loaded mounts and volumes are cleared.
+func (m *manifests) loadVolumes(w workload, container *corev1.Container, spec *corev1.PodSpec, service *kobject.ServiceConfig) {
+	volumes := map[string]corev1.Volume{}
+	for _, volume := range spec.Volumes {
+		volumes[volume.Name] = volume
+	}
+	claimTemplates := map[string]corev1.PersistentVolumeClaim{}
+	for _, claim := range w.claimTemplates {
+		claimTemplates[claim.Name] = claim
+	}
+
+	// The sources of the volumes, without the tmpfs
+	sources := map[string]bool{}
+	var mounts []corev1.VolumeMount
+	for _, mount := range container.VolumeMounts {
+		if mount.SubPath != "" {
+			// The subpath label applies to all the volumes of the service
+			if subPath, ok := service.Labels[compose.LabelContainerVolumeSubpath]; ok && subPath != mount.SubPath {
+				mounts = append(mounts, mount)
+				continue
+			}
+			service.Labels[compose.LabelContainerVolumeSubpath] = mount.SubPath
+		}
+		mode := ""
+		if mount.ReadOnly {
+			mode = "ro"
+		}
+		volume := kobject.Volumes{SvcName: service.Name, Container: mount.MountPath, Mode: mode}
+
+		source, ok := volumes[mount.Name]
+		claim, isTemplate := claimTemplates[mount.Name]
+		switch {
+		case isTemplate:
+			volume.VolumeName = mount.Name
+			m.used["PersistentVolumeClaim/"+mount.Name] = true
+			m.loadClaim(service, &volume, claim)
+			sources["persistentVolumeClaim"] = true
+		case !ok:
+			log.Warnf("%s %q: the volume %q of the mount %s is not found, it is left out of the compose file", w.kind, w.name, mount.Name, mount.MountPath)
+			continue
+		case source.PersistentVolumeClaim != nil:
+			volume.VolumeName = source.PersistentVolumeClaim.ClaimName
+			if claim, ok := m.claims[volume.VolumeName]; ok {
+				m.used["PersistentVolumeClaim/"+volume.VolumeName] = true
+				m.loadClaim(service, &volume, claim)
+			}
+			sources["persistentVolumeClaim"] = true
+		case source.HostPath != nil:
+			volume.Host = source.HostPath.Path
+			sources["hostPath"] = true
+		case source.EmptyDir != nil && source.EmptyDir.Medium == corev1.StorageMediumMemory:
+			service.TmpFs = append(service.TmpFs, mount.MountPath)
+			delete(volumes, mount.Name)
+			continue
+		case source.EmptyDir != nil:
+			sources["emptyDir"] = true
+		default:
+			mounts = append(mounts, mount)
+			continue
+		}
+		service.Volumes = append(service.Volumes, volume)
+		delete(volumes, mount.Name)
+	}
+	container.VolumeMounts = mounts
+
+	// The volumes that aren't PersistentVolumeClaims are only converted back to the same kind of volume with the
+	// volume type label, which applies to all the volumes of the service
+	if !sources["persistentVolumeClaim"] && len(sources) == 1 {
+		for source := range sources {
+			service.Labels[compose.LabelVolumeType] = source
+		}
+	} else if sources["hostPath"] || sources["emptyDir"] {
+		log.Warnf("%s %q: the hostPath and emptyDir volumes are converted to PersistentVolumeClaims with the other volumes of the service", w.kind, w.name)
+	}
+
+	spec.Volumes = nil
+	for _, volume := range volumes {
+		spec.Volumes = append(spec.Volumes, volume)
+	}
+	sort.Slice(spec.Volumes, func(i, j int) bool { return spec.Volumes[i].Name < spec.Volumes[j].Name })
+}
+
+// loadClaim loads the size and the storage class of the PersistentVolumeClaim of a volume
+func (m *manifests) loadClaim(service *kobject.ServiceConfig, volume *kobject.Volumes, claim corev1.PersistentVolumeClaim) {
+	if size, ok := claim.Spec.Resources.Requests[corev1.ResourceStorage]; ok {
+		volume.PVCSize = size.String()
+	}
+	if claim.Spec.StorageClassName != nil {
+		service.Labels[compose.LabelVolumeStorageClassName] = *claim.Spec.StorageClassName
+	}
+}
+
+// loadPodSpec loads the fields of the pod, the loaded fields are cleared
+func (m *manifests) loadPodSpec(spec *corev1.PodSpec, service *kobject.ServiceConfig) {
+	service.HostName, spec.Hostname = spec.Hostname, ""
+	service.DomainName, spec.Subdomain = spec.Subdomain, ""
+	if spec.ServiceAccountName != "" {
+		service.Labels[compose.LabelServiceAccountName] = spec.ServiceAccountName
+	}
+	spec.ServiceAccountName, spec.DeprecatedServiceAccount = "", ""
+	if len(spec.ImagePullSecrets) > 0 {
+		service.ImagePullSecret = spec.ImagePullSecrets[0].Name
+		spec.ImagePullSecrets = spec.ImagePullSecrets[1:]
+	}
+	if spec.TerminationGracePeriodSeconds != nil {
+		service.StopGracePeriod = fmt.Sprintf("%ds", *spec.TerminationGracePeriodSeconds)
+		spec.TerminationGracePeriodSeconds = nil
+	}
+	switch spec.RestartPolicy {
+	case corev1.RestartPolicyNever:
+		service.Restart = "no"
+	case corev1.RestartPolicyOnFailure:
+		service.Restart = "on-failure"
+	}
+	spec.RestartPolicy = ""
+	if sc := spec.SecurityContext; sc != nil {
+		if sc.FSGroup != nil {
+			service.FsGroup, sc.FSGroup = *sc.FSGroup, nil
+		}
+		service.GroupAdd, sc.SupplementalGroups = sc.SupplementalGroups, nil
+	}
+	// The defaults of the API server
+	if spec.DNSPolicy == corev1.DNSClusterFirst {
+		spec.DNSPolicy = ""
+	}
+	if spec.SchedulerName == corev1.DefaultSchedulerName {
+		spec.SchedulerName = ""
+	}
+}
+
+// loadServices loads the ports and the type of the Services selecting the pods of the workload
+func (m *manifests) loadServices(w workload, service *kobject.ServiceConfig) {
+	var selecting []corev1.Service
+	for _, s := range m.services {
+		if len(s.Spec.Selector) > 0 && selects(s.Spec.Selector, w.template.Labels) {
+			selecting = append(selecting, s)
+			m.used["Service/"+s.Name] = true
+		}
+	}
+	if len(selecting) == 0 {
+		if len(service.Port) > 0 {
+			log.Warnf("%s %q: no Service selects its pods, kompose convert creates one for its ports", w.kind, w.name)
+		}
+		return
+	}
+	if len(selecting) > 1 {
+		var names []string
+		for _, s := range selecting[1:] {
+			names = append(names, s.Name)
+		}
+		log.Warnf("%s %q: a service has one Service, the Services %s are left out of the compose file", w.kind, w.name, strings.Join(names, ", "))
+	}
+
+	s := selecting[0]
+	if s.Name != w.name {
+		log.Warnf("Service %q is named %q by kompose convert, after the %s it selects", s.Name, w.name, w.kind)
+	}
+	switch {
+	case s.Spec.ClusterIP == corev1.ClusterIPNone:
+		service.ServiceType = compose.ServiceTypeHeadless
+	case s.Spec.Type == corev1.ServiceTypeNodePort || s.Spec.Type == corev1.ServiceTypeLoadBalancer:
+		service.ServiceType = string(s.Spec.Type)
+	}
+	if s.Spec.ExternalTrafficPolicy == corev1.ServiceExternalTrafficPolicyTypeLocal {
+		service.ServiceExternalTrafficPolicy = string(s.Spec.ExternalTrafficPolicy)
+	}
+	if s.Spec.Type == corev1.ServiceTypeNodePort && len(s.Spec.Ports) == 1 {
+		service.NodePortPort = s.Spec.Ports[0].NodePort
+	}
+
+	// The transformer publishes the host port of the compose ports, or their container port
+	for _, servicePort := range s.Spec.Ports {
+		protocol := string(servicePort.Protocol)
+		if protocol == "" {
+			protocol = string(corev1.ProtocolTCP)
+		}
+		target := servicePort.TargetPort
+		if target.Type == intstr.Int && target.IntVal == 0 {
+			target = intstr.FromInt(int(servicePort.Port))
+		}
+		targetPort := portNumber(target, w.template.Spec.Containers[0].Ports)
+		if targetPort == 0 {
+			log.Warnf("Service %q: the target port %s is not a port of the container, it is left out of the compose file", s.Name, target.String())
+			continue
+		}
+
+		found := false
+		for i := range service.Port {
+			port := &service.Port[i]
+			if port.ContainerPort == targetPort && port.Protocol == protocol {
+				if port.HostPort == 0 && servicePort.Port != targetPort {
+					port.HostPort = servicePort.Port
+				}
+				found = true
+			}
+		}
+		if !found {
+			port := kobject.Ports{ContainerPort: targetPort, Protocol: protocol}
+			if servicePort.Port != targetPort {
+				port.HostPort = servicePort.Port
+			}
+			service.Port = append(service.Port, port)
+		}
+	}
+}
+
+// selects returns true if the labels match the selector
+func selects(selector, labels map[string]string) bool {
+	for key, value := range selector {
+		if labels[key] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// reportUnused reports the ConfigMaps, Secrets, PersistentVolumeClaims and Services no workload uses
+func (m *manifests) reportUnused() {
+	var unused []string
+	for name := range m.configMaps {
+		if !m.used["ConfigMap/"+name] {
+			unused = append(unused, fmt.Sprintf("ConfigMap %q", name))
+		}
+	}
+	for name := range m.secrets {
+		if !m.used["Secret/"+name] {
+			unused = append(unused, fmt.Sprintf("Secret %q", name))
+		}
+	}
+	for name := range m.claims {
+		if !m.used["PersistentVolumeClaim/"+name] {
+			unused = append(unused, fmt.Sprintf("PersistentVolumeClaim %q", name))
+		}
+	}
+	for _, s := range m.services {
+		if !m.used["Service/"+s.Name] {
+			unused = append(unused, fmt.Sprintf("Service %q", s.Name))
+		}
+	}
+	sort.Strings(unused)
+	for _, object := range unused {
+		log.Warnf("%s is not used by a workload, it is left out of the compose file", object)
+	}
+}
+
+// leftoverFields returns the paths of the fields set in a part of a manifest, the fields that weren't loaded
+func leftoverFields(object interface{}) []string {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(object)
+	if err != nil {
+		return nil
+	}
+	var paths []string
+	var collect func(path string, value interface{})
+	collect = func(path string, value interface{}) {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			for key, item := range v {
+				if path != "" {
+					key = path + "." + key
+				}
+				collect(key, item)
+			}
+		case []interface{}:
+			if len(v) == 0 {
+				break
+			}
+			// The fields of the items of a list of objects are reported one by one
+			if _, ok := v[0].(map[string]interface{}); !ok {
+				paths = append(paths, path)
+				break
+			}
+			for i, item := range v {
+				collect(fmt.Sprintf("%s[%d]", path, i), item)
+			}
+		case nil, string, bool, int64, float64:
+			if v != nil && v != "" && v != false && v != int64(0) && v != float64(0) {
+				paths = append(paths, path)
+			}
+		default:
+			paths = append(paths, path)
+		}
+	}
+	collect("", content)
+	sort.Strings(paths)
+	return paths
+}
+
+// dependentVariable matches the $(NAME) references of Kubernetes to environment variables
+var dependentVariable = regexp.MustCompile(`\$\(([a-zA-Z0-9_]*)\)`)
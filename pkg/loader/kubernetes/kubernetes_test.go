@@ -0,0 +1,256 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/compose-spec/compose-go/types"
+	"github.com/google/go-cmp/cmp"
+	"github.com/kubernetes/kompose/pkg/kobject"
+	"github.com/kubernetes/kompose/pkg/loader/compose"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const webManifests = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  labels:
+    io.kompose.service: web
+    tier: frontend
+  annotations:
+    kompose.cmd: kompose convert
+    owner: shop
+spec:
+  replicas: 2
+  selector:
+    matchLabels:
+      io.kompose.service: web
+  template:
+    metadata:
+      labels:
+        io.kompose.service: web
+    spec:
+      containers:
+        - name: web
+          image: nginx:1.25
+          args: ["sh", "-c", "echo $(GREETING)"]
+          env:
+            - name: GREETING
+              value: hello
+            - name: LEVEL
+              valueFrom:
+                configMapKeyRef:
+                  name: web-env
+                  key: LEVEL
+            - name: PASSWORD
+              valueFrom:
+                secretKeyRef:
+                  name: web-secret
+                  key: password
+          ports:
+            - containerPort: 80
+              name: http
+          livenessProbe:
+            httpGet:
+              path: /health
+              port: http
+            periodSeconds: 10
+          resources:
+            limits:
+              cpu: 500m
+              memory: 256Mi
+          securityContext:
+            runAsUser: 1000
+            capabilities:
+              add: ["NET_ADMIN"]
+          volumeMounts:
+            - name: data
+              mountPath: /data
+            - name: cache
+              mountPath: /cache
+          lifecycle:
+            preStop:
+              exec:
+                command: ["nginx", "-s", "quit"]
+      restartPolicy: Always
+      volumes:
+        - name: data
+          persistentVolumeClaim:
+            claimName: data
+        - name: cache
+          emptyDir:
+            medium: Memory
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  type: NodePort
+  selector:
+    io.kompose.service: web
+  ports:
+    - port: 8080
+      targetPort: http
+      nodePort: 30080
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: web-env
+data:
+  LEVEL: debug
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: web-secret
+data:
+  password: c2VjcmV0
+---
+apiVersion: v1
+kind: PersistentVolumeClaim
+metadata:
+  name: data
+spec:
+  storageClassName: fast
+  resources:
+    requests:
+      storage: 2Gi
+`
+
+const dbManifests = `{
+  "apiVersion": "v1",
+  "kind": "List",
+  "items": [
+    {
+      "apiVersion": "apps/v1",
+      "kind": "StatefulSet",
+      "metadata": {"name": "db"},
+      "spec": {
+        "template": {
+          "metadata": {"labels": {"app": "db"}},
+          "spec": {
+            "containers": [
+              {"name": "postgres", "image": "postgres:16", "volumeMounts": [{"name": "pg", "mountPath": "/var/lib/postgresql/data"}]}
+            ]
+          }
+        },
+        "volumeClaimTemplates": [
+          {"metadata": {"name": "pg"}, "spec": {"resources": {"requests": {"storage": "5Gi"}}}}
+        ]
+      }
+    },
+    {"apiVersion": "networking.k8s.io/v1", "kind": "Ingress", "metadata": {"name": "db"}}
+  ]
+}
+`
+
+func TestLoadFile(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "web.yaml"), []byte(webManifests), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(dir, "db"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "db", "db.json"), []byte(dbManifests), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	komposeObject, err := new(Kubernetes).LoadFile([]string{dir})
+	if err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+	if komposeObject.LoadedFrom != "kubernetes" {
+		t.Errorf("Expected the kompose object to be loaded from kubernetes, got %s", komposeObject.LoadedFrom)
+	}
+
+	expected := map[string]kobject.ServiceConfig{
+		"web": {
+			Name:         "web",
+			Image:        "nginx:1.25",
+			Args:         []string{"sh", "-c", "echo $GREETING"},
+			Environment:  []kobject.EnvVar{{Name: "GREETING", Value: "hello"}, {Name: "LEVEL", Value: "debug"}, {Name: "PASSWORD", Value: "secret"}},
+			Port:         []kobject.Ports{{HostPort: 8080, ContainerPort: 80, Protocol: "TCP"}},
+			User:         "1000",
+			CapAdd:       []string{"NET_ADMIN"},
+			CPULimit:     500,
+			MemLimit:     types.UnitBytes(256 << 20),
+			Replicas:     2,
+			ServiceType:  string(corev1.ServiceTypeNodePort),
+			NodePortPort: 30080,
+			Volumes:      []kobject.Volumes{{SvcName: "web", VolumeName: "data", Container: "/data", PVCSize: "2Gi"}},
+			TmpFs:        []string{"/cache"},
+			Labels:       map[string]string{compose.LabelVolumeStorageClassName: "fast"},
+			Annotations:  map[string]string{"owner": "shop"},
+			DeployLabels: map[string]string{"tier": "frontend"},
+			HealthChecks: kobject.HealthChecks{
+				Liveness: kobject.HealthCheck{HTTPPath: "/health", HTTPPort: 80, Interval: 10},
+			},
+		},
+		"db": {
+			Name:          "db",
+			ContainerName: "postgres",
+			Image:         "postgres:16",
+			Volumes:       []kobject.Volumes{{SvcName: "db", VolumeName: "pg", Container: "/var/lib/postgresql/data", PVCSize: "5Gi"}},
+			Labels:        map[string]string{compose.LabelControllerType: "statefulset"},
+			Annotations:   map[string]string{},
+		},
+	}
+	if diff := cmp.Diff(expected, komposeObject.ServiceConfigs); diff != "" {
+		t.Errorf("Unexpected services (-expected +loaded):\n%s", diff)
+	}
+}
+
+func TestLoadFileNotFound(t *testing.T) {
+	_, err := new(Kubernetes).LoadFile([]string{filepath.Join(t.TempDir(), "missing.yaml")})
+	if err == nil {
+		t.Errorf("Expected an error for a missing file")
+	}
+}
+
+func TestLeftoverFields(t *testing.T) {
+	testCases := map[string]struct {
+		object   interface{}
+		expected []string
+	}{
+		"empty container": {&corev1.Container{}, nil},
+		"fields set": {&corev1.Container{
+			Lifecycle:       &corev1.Lifecycle{PreStop: &corev1.LifecycleHandler{Exec: &corev1.ExecAction{Command: []string{"quit"}}}},
+			StartupProbe:    &corev1.Probe{ProbeHandler: corev1.ProbeHandler{TCPSocket: &corev1.TCPSocketAction{Port: intstr.FromInt(80)}}},
+			SecurityContext: &corev1.SecurityContext{},
+		}, []string{"lifecycle.preStop.exec.command", "startupProbe.tcpSocket.port"}},
+		"false and zero values": {&corev1.PodSpec{HostNetwork: false, Priority: new(int32)}, nil},
+		"port names": {&corev1.Container{
+			Ports: []corev1.ContainerPort{{}, {Name: "http"}},
+		}, []string{"ports[1].name"}},
+	}
+
+	for name, test := range testCases {
+		output := leftoverFields(test.object)
+		if !reflect.DeepEqual(output, test.expected) {
+			t.Errorf("Case '%v' for TestLeftoverFields fail, Expected %v, got %v", name, test.expected, output)
+		}
+	}
+}
@@ -18,21 +18,101 @@ package loader
 
 import (
 	"fmt"
+	"sync"
 
 	"github.com/kubernetes/kompose/pkg/kobject"
 	"github.com/kubernetes/kompose/pkg/loader/compose"
+	"github.com/kubernetes/kompose/pkg/loader/kubernetes"
 )
 
 // Loader interface defines loader that loads files and converts it to kobject representation
 type Loader interface {
 	LoadFile(files []string) (kobject.KomposeObject, error)
-	///Name() string
+	// Name is the input format the loader is registered with
+	Name() string
+}
+
+// Factory returns a new loader of an input format
+type Factory func() Loader
+
+// DetectFunc reports whether the input files are in the format of a loader
+type DetectFunc func(files []string) bool
+
+// DefaultFormat is the input format of the files that no registered loader detects
+const DefaultFormat = "compose"
+
+type registration struct {
+	name    string
+	factory Factory
+	detect  DetectFunc
+}
+
+var (
+	registryMutex sync.RWMutex
+	// registry holds the loaders in the order of their registration, the order of the detection
+	registry []registration
+)
+
+func init() {
+	Register("compose", func() Loader { return new(compose.Compose) }, compose.Detect)
+	Register("kubernetes", func() Loader { return new(kubernetes.Kubernetes) }, kubernetes.Detect)
+}
+
+// Register makes a loader available under the name of its input format, for --input-format and client.WithLoader.
+// detect recognizes the files of the format when no input format is given, it may be nil for a loader that is only
+// selected by name. Register panics if the name is empty or already registered.
+func Register(name string, factory Factory, detect DetectFunc) {
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+
+	if name == "" || factory == nil {
+		panic("loader: Register needs a name and a factory")
+	}
+	for _, r := range registry {
+		if r.name == name {
+			panic(fmt.Sprintf("loader: input format %s is already registered", name))
+		}
+	}
+	registry = append(registry, registration{name: name, factory: factory, detect: detect})
 }
 
 // GetLoader returns loader for given format
 func GetLoader(format string) (Loader, error) {
-	if format != "compose" {
-		return nil, fmt.Errorf("input file format %s is not supported", format)
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+
+	for _, r := range registry {
+		if r.name == format {
+			return r.factory(), nil
+		}
+	}
+	return nil, fmt.Errorf("input file format %s is not supported, the supported formats are %v", format, formats())
+}
+
+// Detect returns the input format of the first registered loader recognizing the files, or DefaultFormat
+func Detect(files []string) string {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+
+	for _, r := range registry {
+		if r.detect != nil && r.detect(files) {
+			return r.name
+		}
+	}
+	return DefaultFormat
+}
+
+// Formats returns the registered input formats, in the order of their registration
+func Formats() []string {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+	return formats()
+}
+
+func formats() []string {
+	names := make([]string, 0, len(registry))
+	for _, r := range registry {
+		names = append(names, r.name)
 	}
-	return new(compose.Compose), nil
+	return names
 }
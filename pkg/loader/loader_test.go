@@ -0,0 +1,100 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loader
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/kubernetes/kompose/pkg/kobject"
+)
+
+// descriptor is a loader of the files with the .descriptor extension
+type descriptor struct{}
+
+func (d *descriptor) LoadFile(files []string) (kobject.KomposeObject, error) {
+	return kobject.KomposeObject{ServiceConfigs: map[string]kobject.ServiceConfig{"app": {Image: "app"}}}, nil
+}
+
+func (d *descriptor) Name() string {
+	return "descriptor"
+}
+
+func TestRegister(t *testing.T) {
+	Register("descriptor", func() Loader { return new(descriptor) }, func(files []string) bool {
+		return len(files) > 0 && strings.HasSuffix(files[0], ".descriptor")
+	})
+
+	if formats := Formats(); !reflect.DeepEqual(formats, []string{"compose", "kubernetes", "descriptor"}) {
+		t.Errorf("Expected the formats in the order of their registration, got %v", formats)
+	}
+	l, err := GetLoader("descriptor")
+	if err != nil {
+		t.Fatalf("GetLoader failed: %v", err)
+	}
+	if l.Name() != "descriptor" {
+		t.Errorf("Expected the descriptor loader, got %s", l.Name())
+	}
+	if _, err := GetLoader("unknown"); err == nil {
+		t.Errorf("Expected an error for an unregistered format")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Expected Register to panic for a format registered twice")
+		}
+	}()
+	Register("descriptor", func() Loader { return new(descriptor) }, nil)
+}
+
+func TestDetect(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"compose.yaml":  "services:\n  web:\n    image: nginx\n",
+		"manifest.yaml": "apiVersion: v1\nkind: Service\nmetadata:\n  name: web\n",
+		"manifest.json": `{"apiVersion": "v1", "kind": "List", "items": []}`,
+		"other.yaml":    "name: web\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	testCases := map[string]struct {
+		files    []string
+		expected string
+	}{
+		"compose file":        {[]string{filepath.Join(dir, "compose.yaml")}, "compose"},
+		"stdin":               {[]string{"-"}, "compose"},
+		"yaml manifest":       {[]string{filepath.Join(dir, "manifest.yaml")}, "kubernetes"},
+		"json manifest":       {[]string{filepath.Join(dir, "manifest.json")}, "kubernetes"},
+		"manifests directory": {[]string{dir}, "kubernetes"},
+		"unknown file":        {[]string{filepath.Join(dir, "other.yaml")}, DefaultFormat},
+		"missing file":        {[]string{filepath.Join(dir, "missing.yaml")}, DefaultFormat},
+	}
+
+	for name, test := range testCases {
+		output := Detect(test.files)
+		if output != test.expected {
+			t.Errorf("Case '%v' for TestDetect fail, Expected %v, got %v", name, test.expected, output)
+		}
+	}
+}
@@ -0,0 +1,233 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package knative
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/kubernetes/kompose/pkg/kobject"
+	"github.com/kubernetes/kompose/pkg/transformer/kubernetes"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	appsv1 "k8s.io/api/apps/v1"
+	api "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// Knative implements Transformer interface and represents Knative Serving transformer.
+// The services Knative can't host are converted by the embedded Kubernetes transformer.
+type Knative struct {
+	kubernetes.Kubernetes
+}
+
+const (
+	// AnnotationMinScale is the minimum number of replicas of a Knative revision
+	AnnotationMinScale = "autoscaling.knative.dev/min-scale"
+	// AnnotationMaxScale is the maximum number of replicas of a Knative revision
+	AnnotationMaxScale = "autoscaling.knative.dev/max-scale"
+	// AnnotationClass is the autoscaler of a Knative revision
+	AnnotationClass = "autoscaling.knative.dev/class"
+	// AnnotationMetric is the metric the autoscaler scales on
+	AnnotationMetric = "autoscaling.knative.dev/metric"
+	// AnnotationTarget is the target value of the autoscaling metric
+	AnnotationTarget = "autoscaling.knative.dev/target"
+	// LabelVisibility restricts a Knative service to the cluster
+	LabelVisibility = "networking.knative.dev/visibility"
+
+	// HPAClass is the autoscaler class scaling on cpu or memory instead of requests
+	HPAClass = "hpa.autoscaling.knative.dev"
+)
+
+// unsupportedReason returns why Knative can't host the service, or an empty string if it can
+func unsupportedReason(service kobject.ServiceConfig, opt kobject.ConvertOptions) string {
+	switch {
+	case opt.ServiceGroupMode != "":
+		return "services are grouped"
+	case len(service.Port) != 1:
+		return fmt.Sprintf("it has %d ports, Knative requires a single HTTP port", len(service.Port))
+	case service.Port[0].Protocol != "" && !strings.EqualFold(service.Port[0].Protocol, string(api.ProtocolTCP)):
+		return fmt.Sprintf("it uses a %s port", strings.ToUpper(service.Port[0].Protocol))
+	case len(service.Volumes) > 0 || len(service.TmpFs) > 0:
+		return "it has volumes"
+	case service.ServiceType != "" && !strings.EqualFold(service.ServiceType, string(api.ServiceTypeClusterIP)):
+		return fmt.Sprintf("it uses the service type %s", service.ServiceType)
+	}
+	return ""
+}
+
+// Transform maps komposeObject to Knative Services, the services Knative can't host
+// are kept as the Kubernetes objects
+func (k *Knative) Transform(komposeObject kobject.KomposeObject, opt kobject.ConvertOptions) ([]runtime.Object, error) {
+	objects, err := k.Kubernetes.Transform(komposeObject, opt)
+	if err != nil {
+		return nil, err
+	}
+
+	deployments := map[string]*appsv1.Deployment{}
+	for _, obj := range objects {
+		if d, ok := obj.(*appsv1.Deployment); ok {
+			deployments[d.Name] = d
+		}
+	}
+
+	hosted := map[string]bool{}
+	for _, name := range kubernetes.SortedKeys(komposeObject) {
+		reason := unsupportedReason(komposeObject.ServiceConfigs[name], opt)
+		if reason == "" && deployments[name] == nil {
+			reason = "it is not converted to a Deployment"
+		}
+		if reason != "" {
+			log.Warnf("Knative can't host service %s because %s, converting it to Kubernetes objects", name, reason)
+			continue
+		}
+		hosted[name] = true
+	}
+
+	var allobjects []runtime.Object
+	for _, obj := range objects {
+		accessor, err := meta.Accessor(obj)
+		if err != nil {
+			return nil, errors.Wrap(err, "meta.Accessor failed")
+		}
+		name := accessor.GetName()
+		if !hosted[name] {
+			allobjects = append(allobjects, obj)
+			continue
+		}
+
+		// The Knative Service replaces the workload, the Service, the ingress and the autoscaling of the service
+		switch kind := obj.GetObjectKind().GroupVersionKind().Kind; kind {
+		case "Deployment":
+			ksvc, err := k.initKnativeService(name, komposeObject.ServiceConfigs[name], deployments[name], opt)
+			if err != nil {
+				return nil, err
+			}
+			allobjects = append(allobjects, ksvc)
+		case "Service", "Ingress", "HTTPRoute", "HorizontalPodAutoscaler", "PodDisruptionBudget":
+		default:
+			allobjects = append(allobjects, obj)
+		}
+	}
+	// The Knative Services are created after the other objects got the common metadata
+	kubernetes.AssignCommonMetadata(allobjects, opt)
+	return allobjects, nil
+}
+
+// initKnativeService initializes a serving.knative.dev/v1 Service running the pod template of the deployment
+func (k *Knative) initKnativeService(name string, service kobject.ServiceConfig, deployment *appsv1.Deployment, opt kobject.ConvertOptions) (*unstructured.Unstructured, error) {
+	template := deployment.Spec.Template.DeepCopy()
+
+	// Knative routes the requests to the single container port and manages the restarts
+	template.Spec.RestartPolicy = ""
+	for i := range template.Spec.Containers {
+		var ports []api.ContainerPort
+		for _, port := range template.Spec.Containers[i].Ports {
+			ports = append(ports, api.ContainerPort{ContainerPort: port.ContainerPort})
+		}
+		template.Spec.Containers[i].Ports = ports
+	}
+
+	annotations, err := autoscalingAnnotations(service, deployment, opt)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range annotations {
+		if template.Annotations == nil {
+			template.Annotations = map[string]string{}
+		}
+		template.Annotations[key] = value
+	}
+
+	templateMap, err := runtime.DefaultUnstructuredConverter.ToUnstructured(template)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to convert the pod template")
+	}
+	if metadata, ok := templateMap["metadata"].(map[string]interface{}); ok {
+		delete(metadata, "creationTimestamp")
+	}
+
+	ksvc := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "serving.knative.dev/v1",
+		"kind":       "Service",
+		"spec": map[string]interface{}{
+			"template": templateMap,
+		},
+	}}
+	ksvc.SetName(name)
+	ksvc.SetNamespace(deployment.Namespace)
+	ksvc.SetAnnotations(deployment.Annotations)
+
+	labels := map[string]string{}
+	for key, value := range deployment.Labels {
+		labels[key] = value
+	}
+	// Only the services exposed with kompose.service.expose are reachable from outside the cluster
+	if service.ExposeService == "" {
+		labels[LabelVisibility] = "cluster-local"
+	}
+	ksvc.SetLabels(labels)
+	return ksvc, nil
+}
+
+// autoscalingAnnotations maps the replicas, the kompose.hpa.* labels and the resources of the service
+// to the autoscaling annotations of the Knative revision
+func autoscalingAnnotations(service kobject.ServiceConfig, deployment *appsv1.Deployment, opt kobject.ConvertOptions) (map[string]string, error) {
+	annotations := map[string]string{}
+
+	if (opt.IsReplicaSetFlag || service.Replicas > 0) && deployment.Spec.Replicas != nil {
+		annotations[AnnotationMinScale] = strconv.Itoa(int(*deployment.Spec.Replicas))
+	}
+
+	hpa, err := kubernetes.GetHPAValues(service)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid autoscaling labels for service %s", service.Name)
+	}
+	switch {
+	case hpa != nil:
+		annotations[AnnotationMinScale] = strconv.Itoa(int(hpa.MinReplicas))
+		annotations[AnnotationMaxScale] = strconv.Itoa(int(hpa.MaxReplicas))
+		annotations[AnnotationClass] = HPAClass
+		if hpa.CPU > 0 {
+			annotations[AnnotationMetric] = "cpu"
+			annotations[AnnotationTarget] = strconv.Itoa(int(hpa.CPU))
+			if hpa.Memory > 0 {
+				log.Warnf("Knative scales service %s on a single metric, ignoring the memory target", service.Name)
+			}
+		} else {
+			// The memory target of Knative is an amount of memory, not a percentage of the requests
+			memory := service.MemReservation
+			if memory == 0 {
+				memory = service.MemLimit
+			}
+			if memory == 0 {
+				return nil, errors.Errorf("service %s needs a memory reservation or limit to scale on memory with Knative", service.Name)
+			}
+			annotations[AnnotationMetric] = "memory"
+			annotations[AnnotationTarget] = strconv.FormatInt(int64(memory)*int64(hpa.Memory)/100/(1024*1024), 10)
+		}
+	case service.CPUReservation > 0 || service.CPULimit > 0:
+		// Services with cpu resources scale on their cpu usage, like a HorizontalPodAutoscaler
+		annotations[AnnotationClass] = HPAClass
+		annotations[AnnotationMetric] = "cpu"
+		annotations[AnnotationTarget] = strconv.Itoa(kubernetes.DefaultHPACPUUtilization)
+	}
+	return annotations, nil
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package knative
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/kubernetes/kompose/pkg/kobject"
+	"github.com/kubernetes/kompose/pkg/transformer/kubernetes"
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	api "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func newServiceConfig(name string, ports ...kobject.Ports) kobject.ServiceConfig {
+	return kobject.ServiceConfig{
+		Name:    name,
+		Image:   "image",
+		Port:    ports,
+		Restart: "always",
+	}
+}
+
+func TestKnativeTransform(t *testing.T) {
+	web := newServiceConfig("web", kobject.Ports{HostPort: 8080, ContainerPort: 80, Protocol: string(api.ProtocolTCP)})
+	web.Replicas = 3
+	web.CPULimit = 500
+	dns := newServiceConfig("dns", kobject.Ports{HostPort: 53, ContainerPort: 53, Protocol: string(api.ProtocolUDP)})
+	komposeObject := kobject.KomposeObject{
+		ServiceConfigs: map[string]kobject.ServiceConfig{"web": web, "dns": dns},
+	}
+	opt := kobject.ConvertOptions{CreateD: true, Replicas: 1}
+	k := Knative{Kubernetes: kubernetes.Kubernetes{Opt: opt}}
+
+	objects, err := k.Transform(komposeObject, opt)
+	if err != nil {
+		t.Fatal(errors.Wrap(err, "k.Transform failed"))
+	}
+
+	var ksvcs []*unstructured.Unstructured
+	deployments := map[string]bool{}
+	services := map[string]bool{}
+	for _, obj := range objects {
+		switch o := obj.(type) {
+		case *unstructured.Unstructured:
+			ksvcs = append(ksvcs, o)
+		case *appsv1.Deployment:
+			deployments[o.Name] = true
+		case *api.Service:
+			services[o.Name] = true
+		}
+	}
+
+	if !deployments["dns"] || !services["dns"] {
+		t.Errorf("Expected the UDP service dns to be converted to a Deployment and a Service, got %v and %v", deployments, services)
+	}
+	if deployments["web"] || services["web"] {
+		t.Errorf("Expected no Deployment or Service for web, got %v and %v", deployments, services)
+	}
+	if len(ksvcs) != 1 || ksvcs[0].GetName() != "web" || ksvcs[0].GetAPIVersion() != "serving.knative.dev/v1" {
+		t.Fatalf("Expected a single Knative Service web, got %v", ksvcs)
+	}
+
+	if ksvcs[0].GetLabels()[LabelVisibility] != "cluster-local" {
+		t.Errorf("Expected the unexposed service to be cluster-local, got labels %v", ksvcs[0].GetLabels())
+	}
+	annotations, _, _ := unstructured.NestedStringMap(ksvcs[0].Object, "spec", "template", "metadata", "annotations")
+	expected := map[string]string{
+		AnnotationMinScale: "3",
+		AnnotationClass:    HPAClass,
+		AnnotationMetric:   "cpu",
+		AnnotationTarget:   "80",
+	}
+	if !reflect.DeepEqual(annotations, expected) {
+		t.Errorf("Expected annotations %v, got %v", expected, annotations)
+	}
+	containers, _, _ := unstructured.NestedSlice(ksvcs[0].Object, "spec", "template", "spec", "containers")
+	container := containers[0].(map[string]interface{})
+	if !reflect.DeepEqual(container["ports"], []interface{}{map[string]interface{}{"containerPort": int64(80)}}) {
+		t.Errorf("Expected the single container port 80, got %v", container["ports"])
+	}
+}
+
+func TestUnsupportedReason(t *testing.T) {
+	tcp := kobject.Ports{ContainerPort: 80, Protocol: string(api.ProtocolTCP)}
+	withVolume := newServiceConfig("volume", tcp)
+	withVolume.Volumes = []kobject.Volumes{{MountPath: "/data"}}
+	nodePort := newServiceConfig("nodeport", tcp)
+	nodePort.ServiceType = string(api.ServiceTypeNodePort)
+
+	testCases := map[string]struct {
+		service   kobject.ServiceConfig
+		opt       kobject.ConvertOptions
+		supported bool
+	}{
+		"Single TCP port":  {newServiceConfig("web", tcp), kobject.ConvertOptions{}, true},
+		"No port":          {newServiceConfig("worker"), kobject.ConvertOptions{}, false},
+		"Multiple ports":   {newServiceConfig("web", tcp, kobject.Ports{ContainerPort: 443, Protocol: string(api.ProtocolTCP)}), kobject.ConvertOptions{}, false},
+		"UDP port":         {newServiceConfig("dns", kobject.Ports{ContainerPort: 53, Protocol: string(api.ProtocolUDP)}), kobject.ConvertOptions{}, false},
+		"Volumes":          {withVolume, kobject.ConvertOptions{}, false},
+		"NodePort service": {nodePort, kobject.ConvertOptions{}, false},
+		"Grouped services": {newServiceConfig("web", tcp), kobject.ConvertOptions{ServiceGroupMode: "label"}, false},
+	}
+	for name, test := range testCases {
+		t.Log("Test case:", name)
+		reason := unsupportedReason(test.service, test.opt)
+		if (reason == "") != test.supported {
+			t.Errorf("Expected supported %v, got reason %q", test.supported, reason)
+		}
+	}
+}
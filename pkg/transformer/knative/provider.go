@@ -0,0 +1,37 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package knative
+
+import (
+	"github.com/kubernetes/kompose/pkg/kobject"
+	"github.com/kubernetes/kompose/pkg/transformer"
+	"github.com/kubernetes/kompose/pkg/transformer/kubernetes"
+)
+
+func init() {
+	// Knative converts the services it can't host with the Kubernetes transformer, it takes the same options
+	transformer.RegisterProvider(transformer.Provider{
+		Name: "knative",
+		New: func(opt kobject.ConvertOptions) transformer.Transformer {
+			return &Knative{Kubernetes: kubernetes.Kubernetes{Opt: opt}}
+		},
+		Flags:         kubernetes.ProviderFlags,
+		OutputFormats: kubernetes.OutputFormats,
+		Validate:      kubernetes.ValidateOptions,
+		SetDefaults:   kubernetes.SetDefaultOptions,
+	})
+}
@@ -0,0 +1,368 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/kubernetes/kompose/pkg/kobject"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// diffContext is the number of unchanged lines around the changes of the diff
+const diffContext = 3
+
+// conversionAnnotations are the annotations kompose adds about the conversion, they change with every
+// kompose command and version and aren't compared
+var conversionAnnotations = []string{"kompose.cmd", "kompose.version"}
+
+// manifest is an object read from a file
+type manifest struct {
+	file   string
+	object map[string]interface{}
+}
+
+// DiffList compares the objects with the manifests of the file or the directory opt.OutFile, ignoring the order
+// of the fields and the annotations about the conversion. It writes a unified diff of every object that differs
+// to out and returns true if any object differs.
+func DiffList(objects []runtime.Object, opt kobject.ConvertOptions, out io.Writer) (bool, error) {
+	manifests, err := readManifests(opt.OutFile)
+	if err != nil {
+		return false, err
+	}
+	existing := map[string]manifest{}
+	for _, m := range manifests {
+		removeConversionAnnotations(m.object)
+		existing[objectKey(m.object)] = m
+	}
+
+	indent := opt.YAMLIndent
+	if indent == 0 {
+		indent = 2
+	}
+	differ := false
+	generated := map[string]bool{}
+	for _, obj := range objects {
+		object, err := toUnstructuredMap(obj)
+		if err != nil {
+			return false, err
+		}
+		removeConversionAnnotations(object)
+		key := objectKey(object)
+		generated[key] = true
+
+		old, ok := existing[key]
+		fromFile := "/dev/null"
+		if ok {
+			fromFile = old.file
+			if reflect.DeepEqual(old.object, object) {
+				continue
+			}
+		}
+		differ = true
+		if err := writeObjectDiff(out, old.object, object, fromFile, "kompose", objectDescription(object), indent); err != nil {
+			return false, err
+		}
+	}
+
+	// The manifests kompose doesn't generate anymore
+	for _, m := range manifests {
+		if generated[objectKey(m.object)] {
+			continue
+		}
+		differ = true
+		if err := writeObjectDiff(out, m.object, nil, m.file, "/dev/null", objectDescription(m.object), indent); err != nil {
+			return false, err
+		}
+	}
+	return differ, nil
+}
+
+// readManifests returns the objects of the file path, or of the YAML and JSON files of the directory path read
+// recursively. The items of the v1 Lists are returned as objects, the documents that aren't Kubernetes objects
+// (kustomization.yaml) are skipped. The templates of the Helm charts aren't manifests, a chart is rejected.
+func readManifests(path string) ([]manifest, error) {
+	var files []string
+	err := filepath.Walk(path, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if _, err := os.Stat(filepath.Join(file, "Chart.yaml")); err == nil {
+				return errors.Errorf("%s is a Helm chart, its templates can't be compared", file)
+			}
+			return nil
+		}
+		// The file of --out is read whatever its extension
+		if file == path {
+			files = append(files, file)
+			return nil
+		}
+		switch strings.ToLower(filepath.Ext(file)) {
+		case ".yaml", ".yml", ".json":
+			files = append(files, file)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read the manifests of %s", path)
+	}
+
+	var manifests []manifest
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read %s", file)
+		}
+		// JSON is YAML, both formats are read by the YAML decoder
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		for {
+			var document interface{}
+			if err := decoder.Decode(&document); err == io.EOF {
+				break
+			} else if err != nil {
+				return nil, errors.Wrapf(err, "failed to parse %s", file)
+			}
+			object, err := normalizeManifest(document)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to parse %s", file)
+			}
+			items := []map[string]interface{}{object}
+			if object["kind"] == "List" {
+				items = nil
+				list, _ := object["items"].([]interface{})
+				for _, item := range list {
+					if item, ok := item.(map[string]interface{}); ok {
+						items = append(items, item)
+					}
+				}
+			}
+			for _, item := range items {
+				if item["apiVersion"] == nil || item["kind"] == nil || item["kind"] == "Kustomization" {
+					continue
+				}
+				manifests = append(manifests, manifest{file: file, object: item})
+			}
+		}
+	}
+	return manifests, nil
+}
+
+// normalizeManifest converts a YAML document to the values of a JSON document, like the generated objects
+func normalizeManifest(document interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(document)
+	if err != nil {
+		return nil, err
+	}
+	object := map[string]interface{}{}
+	if err := json.Unmarshal(data, &object); err != nil {
+		return nil, err
+	}
+	return object, nil
+}
+
+// removeConversionAnnotations removes the conversionAnnotations of the object and of the templates it holds
+func removeConversionAnnotations(value interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if metadata, ok := v["metadata"].(map[string]interface{}); ok {
+			if annotations, ok := metadata["annotations"].(map[string]interface{}); ok {
+				for _, annotation := range conversionAnnotations {
+					delete(annotations, annotation)
+				}
+				if len(annotations) == 0 {
+					delete(metadata, "annotations")
+				}
+			}
+		}
+		for _, item := range v {
+			removeConversionAnnotations(item)
+		}
+	case []interface{}:
+		for _, item := range v {
+			removeConversionAnnotations(item)
+		}
+	}
+}
+
+// objectKey identifies an object by its group, kind, namespace and name, a new version of its API is a change of the object
+func objectKey(object map[string]interface{}) string {
+	apiVersion, _ := object["apiVersion"].(string)
+	group := ""
+	if i := strings.LastIndex(apiVersion, "/"); i >= 0 {
+		group = apiVersion[:i]
+	}
+	metadata, _ := object["metadata"].(map[string]interface{})
+	return fmt.Sprintf("%s/%v/%v/%v", group, object["kind"], metadata["namespace"], metadata["name"])
+}
+
+// objectDescription describes an object in the headers of the diff, e.g. Deployment web
+func objectDescription(object map[string]interface{}) string {
+	metadata, _ := object["metadata"].(map[string]interface{})
+	return fmt.Sprintf("%v %v", object["kind"], metadata["name"])
+}
+
+// writeObjectDiff writes the unified diff of the YAML of two versions of an object, a nil object has no line
+func writeObjectDiff(out io.Writer, from, to map[string]interface{}, fromFile, toFile, description string, indent int) error {
+	lines := func(object map[string]interface{}) ([]string, error) {
+		if object == nil {
+			return nil, nil
+		}
+		data, err := marshalWithIndent(object, indent)
+		if err != nil {
+			return nil, err
+		}
+		return splitLines(string(data)), nil
+	}
+	fromLines, err := lines(from)
+	if err != nil {
+		return err
+	}
+	toLines, err := lines(to)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(out, "--- %s\t%s\n+++ %s\t%s\n%s", fromFile, description, toFile, description, unifiedDiff(fromLines, toLines))
+	return err
+}
+
+// splitLines splits a text in lines, each line keeping its newline
+func splitLines(text string) []string {
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// unifiedDiff returns the hunks of the unified diff of two lists of lines, each line ending with a newline but the last ones
+func unifiedDiff(from, to []string) string {
+	// lcs[i][j] is the length of the longest common subsequence of from[i:] and to[j:]
+	lcs := make([][]int, len(from)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(to)+1)
+	}
+	for i := len(from) - 1; i >= 0; i-- {
+		for j := len(to) - 1; j >= 0; j-- {
+			if from[i] == to[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	// The edits turning from into to, ' ' keeps a line, '-' removes it and '+' adds it
+	type edit struct {
+		op   byte
+		line string
+	}
+	var edits []edit
+	i, j := 0, 0
+	for i < len(from) || j < len(to) {
+		switch {
+		case i < len(from) && j < len(to) && from[i] == to[j]:
+			edits = append(edits, edit{' ', from[i]})
+			i, j = i+1, j+1
+		case j < len(to) && (i == len(from) || lcs[i][j+1] > lcs[i+1][j]):
+			edits = append(edits, edit{'+', to[j]})
+			j++
+		default:
+			edits = append(edits, edit{'-', from[i]})
+			i++
+		}
+	}
+
+	var hunks strings.Builder
+	fromLine, toLine := 1, 1
+	for start := 0; start < len(edits); {
+		if edits[start].op == ' ' {
+			fromLine, toLine = fromLine+1, toLine+1
+			start++
+			continue
+		}
+		// A hunk starts diffContext lines before a change and ends when the changes are more than twice diffContext lines apart
+		first := start - diffContext
+		if first < 0 {
+			first = 0
+		}
+		end, unchanged := start, 0
+		for ; end < len(edits) && unchanged <= 2*diffContext; end++ {
+			if edits[end].op == ' ' {
+				unchanged++
+			} else {
+				unchanged = 0
+			}
+		}
+		end -= unchanged - diffContext
+		if end > len(edits) {
+			end = len(edits)
+		}
+
+		hunkFrom, hunkTo := fromLine-(start-first), toLine-(start-first)
+		var body strings.Builder
+		fromCount, toCount := 0, 0
+		for _, e := range edits[first:end] {
+			if e.op != '+' {
+				fromCount++
+			}
+			if e.op != '-' {
+				toCount++
+			}
+			body.WriteByte(e.op)
+			body.WriteString(e.line)
+			if !strings.HasSuffix(e.line, "\n") {
+				body.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+		fmt.Fprintf(&hunks, "@@ -%s +%s @@\n%s", hunkRange(hunkFrom, fromCount), hunkRange(hunkTo, toCount), body.String())
+
+		for _, e := range edits[start:end] {
+			if e.op != '+' {
+				fromLine++
+			}
+			if e.op != '-' {
+				toLine++
+			}
+		}
+		start = end
+	}
+	return hunks.String()
+}
+
+// hunkRange formats the range of lines of a hunk, an empty range starts at the line before
+func hunkRange(start, count int) string {
+	if count == 0 {
+		start--
+	}
+	if count == 1 {
+		return fmt.Sprint(start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
@@ -0,0 +1,167 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kubernetes/kompose/pkg/kobject"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	testCases := map[string]struct {
+		from, to string
+		expected string
+	}{
+		"equal":   {"a\nb\n", "a\nb\n", ""},
+		"added":   {"", "a\n", "@@ -0,0 +1 @@\n+a\n"},
+		"removed": {"a\nb\n", "", "@@ -1,2 +0,0 @@\n-a\n-b\n"},
+		"changed": {
+			"1\n2\n3\n4\n5\n6\n7\n8\n", "1\n2\n3\n4\nfive\n6\n7\n8\n",
+			"@@ -2,7 +2,7 @@\n 2\n 3\n 4\n-5\n+five\n 6\n 7\n 8\n",
+		},
+		"separate hunks": {
+			"a\n1\n2\n3\n4\n5\n6\n7\nb\n", "A\n1\n2\n3\n4\n5\n6\n7\nB\n",
+			"@@ -1,4 +1,4 @@\n-a\n+A\n 1\n 2\n 3\n@@ -6,4 +6,4 @@\n 5\n 6\n 7\n-b\n+B\n",
+		},
+	}
+
+	for name, test := range testCases {
+		if diff := unifiedDiff(splitLines(test.from), splitLines(test.to)); diff != test.expected {
+			t.Errorf("Case '%v' for TestUnifiedDiff fail, Expected:\n%v\ngot:\n%v", name, test.expected, diff)
+		}
+	}
+}
+
+func newDiffObjects(replicas int32) []runtime.Object {
+	labels := map[string]string{"io.kompose.service": "web"}
+	annotations := map[string]string{"kompose.cmd": "kompose convert", "kompose.version": "1.0.0"}
+	deployment := &appsv1.Deployment{
+		TypeMeta:   metav1.TypeMeta{Kind: "Deployment", APIVersion: "apps/v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Labels: labels, Annotations: annotations},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels, Annotations: annotations},
+				Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "web", Image: "nginx"}}},
+			},
+		},
+	}
+	service := &corev1.Service{
+		TypeMeta:   metav1.TypeMeta{Kind: "Service", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Labels: labels},
+		Spec:       corev1.ServiceSpec{Selector: labels, Ports: []corev1.ServicePort{{Name: "80", Port: 80}}},
+	}
+	return []runtime.Object{deployment, service}
+}
+
+func TestDiffList(t *testing.T) {
+	testCases := map[string]struct {
+		existing []runtime.Object
+		objects  []runtime.Object
+		json     bool
+		differ   bool
+		expected []string
+	}{
+		"same objects":      {newDiffObjects(1), newDiffObjects(1), false, false, nil},
+		"same objects json": {newDiffObjects(1), newDiffObjects(1), true, false, nil},
+		"kompose annotations": {
+			newDiffObjects(1),
+			func() []runtime.Object {
+				objects := newDiffObjects(1)
+				objects[0].(*appsv1.Deployment).Annotations["kompose.version"] = "2.0.0"
+				objects[0].(*appsv1.Deployment).Spec.Template.Annotations = nil
+				return objects
+			}(),
+			false, false, nil,
+		},
+		"changed replicas": {
+			newDiffObjects(1), newDiffObjects(3), false, true,
+			[]string{"+++ kompose\tDeployment web", "-  replicas: 1\n+  replicas: 3\n"},
+		},
+		"new object": {
+			newDiffObjects(1)[:1], newDiffObjects(1), false, true,
+			[]string{"--- /dev/null\tService web", "+kind: Service\n"},
+		},
+		"removed object": {
+			newDiffObjects(1), newDiffObjects(1)[:1], false, true,
+			[]string{"+++ /dev/null\tService web", "-kind: Service\n"},
+		},
+	}
+
+	for name, test := range testCases {
+		dir := t.TempDir()
+		opt := kobject.ConvertOptions{OutFile: dir + string(os.PathSeparator), GenerateJSON: test.json, YAMLIndent: 2}
+		if err := PrintList(test.existing, opt); err != nil {
+			t.Fatalf("Case '%v' for TestDiffList fail, PrintList failed: %v", name, err)
+		}
+		// A file that isn't a Kubernetes object is ignored
+		if err := os.WriteFile(filepath.Join(dir, "kustomization.yaml"), []byte("resources: []\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		var out bytes.Buffer
+		differ, err := DiffList(test.objects, opt, &out)
+		if err != nil {
+			t.Fatalf("Case '%v' for TestDiffList fail, DiffList failed: %v", name, err)
+		}
+		if differ != test.differ {
+			t.Errorf("Case '%v' for TestDiffList fail, Expected differ %v, got %v:\n%v", name, test.differ, differ, out.String())
+		}
+		for _, expected := range test.expected {
+			if !strings.Contains(out.String(), expected) {
+				t.Errorf("Case '%v' for TestDiffList fail, Expected '%v' in:\n%v", name, expected, out.String())
+			}
+		}
+	}
+}
+
+func TestDiffListOutFile(t *testing.T) {
+	// The file of --out is read whatever its extension
+	opt := kobject.ConvertOptions{OutFile: filepath.Join(t.TempDir(), "manifests"), YAMLIndent: 2}
+	if err := PrintList(newDiffObjects(1), opt); err != nil {
+		t.Fatalf("PrintList failed: %v", err)
+	}
+	var out bytes.Buffer
+	differ, err := DiffList(newDiffObjects(1), opt, &out)
+	if err != nil {
+		t.Fatalf("DiffList failed: %v", err)
+	}
+	if differ {
+		t.Errorf("Expected the objects of %s to be the same, got:\n%v", opt.OutFile, out.String())
+	}
+}
+
+func TestDiffListChart(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "Chart.yaml"), []byte("name: web\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	opt := kobject.ConvertOptions{OutFile: dir, YAMLIndent: 2}
+	if _, err := DiffList(newDiffObjects(1), opt, &bytes.Buffer{}); err == nil || !strings.Contains(err.Error(), "is a Helm chart") {
+		t.Errorf("Expected the chart to be rejected, got %v", err)
+	}
+}
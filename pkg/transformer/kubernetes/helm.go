@@ -0,0 +1,449 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/kubernetes/kompose/pkg/transformer"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+	api "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// DefaultChartVersion is the version of the generated Helm chart when --chart-version is not set
+const DefaultChartVersion = "0.0.1"
+
+// helmIgnore is the .helmignore of the generated Helm chart, as written by helm create
+const helmIgnore = `# Patterns to ignore when building packages.
+# This supports shell glob matching, relative path matching, and
+# negation (prefixed with !). Only one pattern per line.
+.DS_Store
+# Common VCS dirs
+.git/
+.gitignore
+.bzr/
+.bzrignore
+.hg/
+.hgignore
+.svn/
+# Common backup files
+*.swp
+*.bak
+*.tmp
+*.orig
+*~
+# Various IDEs
+.project
+.idea/
+*.tmproj
+.vscode/
+`
+
+// helmPlaceholderPrefix marks the fields of a manifest replaced by a reference to values.yaml
+const helmPlaceholderPrefix = "kompose-helm-placeholder-"
+
+var helmPlaceholderRegexp = regexp.MustCompile(helmPlaceholderPrefix + `\d+`)
+
+var helmIdentifierRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// helmPlaceholder is the template written in place of a placeholder. Block placeholders
+// render a whole YAML node and are indented by Helm with nindent.
+type helmPlaceholder struct {
+	expr  string
+	block bool
+}
+
+// helmChart lifts the configurable fields of the converted objects into values.yaml,
+// keyed by service name, and turns the manifests into templates referencing them
+type helmChart struct {
+	name         string
+	indent       int
+	values       map[string]interface{}
+	placeholders []helmPlaceholder
+	claimOwners  map[string]string
+	// endpoints lists how to reach the exposed services in NOTES.txt
+	endpoints []string
+}
+
+func newHelmChart(name string, indent int, objects []runtime.Object) (*helmChart, error) {
+	chart := &helmChart{
+		name:        name,
+		indent:      indent,
+		values:      map[string]interface{}{},
+		claimOwners: map[string]string{},
+	}
+
+	// PVC sizes are keyed by the service mounting the claim
+	for _, obj := range objects {
+		m, err := toUnstructuredMap(obj)
+		if err != nil {
+			return nil, err
+		}
+		podSpec := helmPodSpec(m)
+		volumes, _ := podSpec["volumes"].([]interface{})
+		for _, v := range volumes {
+			volume, _ := v.(map[string]interface{})
+			if claim, ok := volume["persistentVolumeClaim"].(map[string]interface{}); ok {
+				if claimName, ok := claim["claimName"].(string); ok {
+					if _, exists := chart.claimOwners[claimName]; !exists {
+						chart.claimOwners[claimName] = helmServiceName(m)
+					}
+				}
+			}
+		}
+	}
+	return chart, nil
+}
+
+// template returns the manifest of obj with its configurable fields replaced by references to values.yaml
+func (c *helmChart) template(obj runtime.Object) ([]byte, error) {
+	m, err := toUnstructuredMap(obj)
+	if err != nil {
+		return nil, err
+	}
+	service := helmServiceName(m)
+	kind, _ := m["kind"].(string)
+	spec, _ := m["spec"].(map[string]interface{})
+
+	if replicas, ok := spec["replicas"]; ok {
+		spec["replicas"] = c.lift(replicas, "{{ %s }}", service, "replicas")
+	}
+
+	podSpec := helmPodSpec(m)
+	containers, _ := podSpec["containers"].([]interface{})
+	for _, ctr := range containers {
+		container, _ := ctr.(map[string]interface{})
+		path := []string{service}
+		if len(containers) > 1 {
+			path = helmPath(path, "containers", container["name"].(string))
+		}
+		c.liftContainer(container, path)
+	}
+
+	switch kind {
+	case "Service":
+		c.addServiceEndpoint(spec, helmObjectName(m), service)
+		// The type of a headless service can't be changed, the others can be switched to NodePort or LoadBalancer
+		if spec["clusterIP"] != api.ClusterIPNone {
+			serviceType, ok := spec["type"]
+			if !ok {
+				serviceType = string(api.ServiceTypeClusterIP)
+			}
+			spec["type"] = c.lift(serviceType, "{{ %s }}", service, "service", "type")
+		}
+	case "Ingress":
+		c.liftIngressHosts(spec, service)
+	case "HTTPRoute":
+		c.addRouteEndpoints(spec, service)
+	case "PersistentVolumeClaim":
+		resources, _ := spec["resources"].(map[string]interface{})
+		requests, _ := resources["requests"].(map[string]interface{})
+		if size, ok := requests["storage"]; ok {
+			owner, ok := c.claimOwners[helmObjectName(m)]
+			if !ok {
+				owner = service
+			}
+			requests["storage"] = c.lift(size, "{{ %s | quote }}", owner, "persistence", helmObjectName(m), "size")
+		}
+	}
+
+	metadata, _ := m["metadata"].(map[string]interface{})
+	labels, ok := metadata["labels"].(map[string]interface{})
+	if !ok {
+		labels = map[string]interface{}{}
+		metadata["labels"] = labels
+	}
+	labels[c.placeholder(fmt.Sprintf(`{{- include "%s.labels" . | nindent %%d }}`, c.name), true)] = ""
+
+	data, err := marshalWithIndent(m, c.indent)
+	if err != nil {
+		return nil, err
+	}
+	return c.render(data), nil
+}
+
+func (c *helmChart) liftContainer(container map[string]interface{}, path []string) {
+	if image, ok := container["image"].(string); ok {
+		repository, tag := splitImage(image)
+		c.setValue(repository, helmPath(path, "image", "repository")...)
+		c.setValue(tag, helmPath(path, "image", "tag")...)
+		container["image"] = c.placeholder(fmt.Sprintf("{{ %s }}{{ with %s }}:{{ . }}{{ end }}",
+			helmValuesRef(helmPath(path, "image", "repository")), helmValuesRef(helmPath(path, "image", "tag"))), false)
+	}
+
+	if resources, ok := container["resources"]; ok {
+		c.setValue(resources, helmPath(path, "resources")...)
+		container["resources"] = c.placeholder(fmt.Sprintf("{{- toYaml %s | nindent %%d }}", helmValuesRef(helmPath(path, "resources"))), true)
+	}
+
+	env, _ := container["env"].([]interface{})
+	for _, e := range env {
+		envVar, _ := e.(map[string]interface{})
+		name, _ := envVar["name"].(string)
+		if value, ok := envVar["value"]; ok {
+			envVar["value"] = c.lift(value, "{{ %s | quote }}", helmPath(path, "env", name)...)
+		}
+	}
+}
+
+func (c *helmChart) liftIngressHosts(spec map[string]interface{}, service string) {
+	var hosts []interface{}
+	index := map[string]int{}
+	hostRef := func(host string) string {
+		i, ok := index[host]
+		if !ok {
+			i = len(hosts)
+			index[host] = i
+			hosts = append(hosts, host)
+		}
+		return c.placeholder(fmt.Sprintf("{{ index %s %d | quote }}", helmValuesRef([]string{service, "ingress", "hosts"}), i), false)
+	}
+
+	scheme := "http"
+	tls, _ := spec["tls"].([]interface{})
+	if len(tls) > 0 {
+		scheme = "https"
+	}
+
+	rules, _ := spec["rules"].([]interface{})
+	for _, r := range rules {
+		rule, _ := r.(map[string]interface{})
+		host := "<ingress address>"
+		if h, ok := rule["host"].(string); ok {
+			rule["host"] = hostRef(h)
+			host = fmt.Sprintf("{{ index %s %d }}", helmValuesRef([]string{service, "ingress", "hosts"}), index[h])
+		}
+		http, _ := rule["http"].(map[string]interface{})
+		paths, _ := http["paths"].([]interface{})
+		for _, p := range paths {
+			path, _ := p.(map[string]interface{})
+			c.endpoints = append(c.endpoints, fmt.Sprintf("%s: %s://%s%v", service, scheme, host, path["path"]))
+		}
+	}
+
+	for _, t := range tls {
+		tlsEntry, _ := t.(map[string]interface{})
+		tlsHosts, _ := tlsEntry["hosts"].([]interface{})
+		for i, h := range tlsHosts {
+			if host, ok := h.(string); ok && host != "" {
+				tlsHosts[i] = hostRef(host)
+			}
+		}
+	}
+	if len(hosts) > 0 {
+		c.setValue(hosts, service, "ingress", "hosts")
+	}
+}
+
+func (c *helmChart) addRouteEndpoints(spec map[string]interface{}, service string) {
+	scheme := "http"
+	parentRefs, _ := spec["parentRefs"].([]interface{})
+	for _, p := range parentRefs {
+		if parentRef, _ := p.(map[string]interface{}); parentRef["sectionName"] != nil {
+			scheme = "https"
+		}
+	}
+
+	hostnames, _ := spec["hostnames"].([]interface{})
+	if len(hostnames) == 0 {
+		hostnames = []interface{}{"<gateway address>"}
+	}
+	rules, _ := spec["rules"].([]interface{})
+	for _, host := range hostnames {
+		for _, r := range rules {
+			rule, _ := r.(map[string]interface{})
+			matches, _ := rule["matches"].([]interface{})
+			for _, m := range matches {
+				match, _ := m.(map[string]interface{})
+				path, _ := match["path"].(map[string]interface{})
+				c.endpoints = append(c.endpoints, fmt.Sprintf("%s: %s://%v%v", service, scheme, host, path["value"]))
+			}
+		}
+	}
+}
+
+func (c *helmChart) addServiceEndpoint(spec map[string]interface{}, name string, service string) {
+	switch spec["type"] {
+	case string(api.ServiceTypeNodePort):
+		c.endpoints = append(c.endpoints, fmt.Sprintf(
+			"%s: NodePort service, get the port with: kubectl get --namespace {{ .Release.Namespace }} service %s -o jsonpath=\"{.spec.ports[0].nodePort}\"", service, name))
+	case string(api.ServiceTypeLoadBalancer):
+		c.endpoints = append(c.endpoints, fmt.Sprintf(
+			"%s: LoadBalancer service, get the address with: kubectl get --namespace {{ .Release.Namespace }} service %s -o jsonpath=\"{.status.loadBalancer.ingress[0]}\"", service, name))
+	}
+}
+
+// lift stores value in values.yaml at path and returns the placeholder of its reference
+func (c *helmChart) lift(value interface{}, format string, path ...string) string {
+	c.setValue(value, path...)
+	return c.placeholder(fmt.Sprintf(format, helmValuesRef(path)), false)
+}
+
+func (c *helmChart) setValue(value interface{}, path ...string) {
+	m := c.values
+	for _, key := range path[:len(path)-1] {
+		next, ok := m[key].(map[string]interface{})
+		if !ok {
+			next = map[string]interface{}{}
+			m[key] = next
+		}
+		m = next
+	}
+	m[path[len(path)-1]] = value
+}
+
+func (c *helmChart) placeholder(expr string, block bool) string {
+	c.placeholders = append(c.placeholders, helmPlaceholder{expr: expr, block: block})
+	return fmt.Sprintf("%s%d", helmPlaceholderPrefix, len(c.placeholders)-1)
+}
+
+// render replaces the placeholders of a marshalled manifest by their templates
+func (c *helmChart) render(data []byte) []byte {
+	// Escape the actions that are part of the converted content, like in a ConfigMap
+	escaped := strings.ReplaceAll(string(data), "{{", `{{ "{{" }}`)
+
+	lines := strings.Split(escaped, "\n")
+	for i, line := range lines {
+		loc := helmPlaceholderRegexp.FindStringIndex(line)
+		if loc == nil {
+			continue
+		}
+		n, _ := strconv.Atoi(strings.TrimPrefix(line[loc[0]:loc[1]], helmPlaceholderPrefix))
+		p := c.placeholders[n]
+
+		switch {
+		case !p.block:
+			lines[i] = line[:loc[0]] + p.expr + line[loc[1]:]
+		case strings.HasPrefix(line[loc[1]:], ":"):
+			// a map key, replaced by the rendered entries of the map
+			lines[i] = line[:loc[0]] + fmt.Sprintf(p.expr, loc[0])
+		default:
+			// a map value, rendered on the lines following the key
+			keyColumn := len(line) - len(strings.TrimLeft(line, " -"))
+			lines[i] = strings.TrimRight(line[:loc[0]], " ") + " " + fmt.Sprintf(p.expr, keyColumn+c.indent)
+		}
+	}
+	return []byte(strings.Join(lines, "\n"))
+}
+
+// valuesYAML returns the content of values.yaml
+func (c *helmChart) valuesYAML() ([]byte, error) {
+	var data bytes.Buffer
+	encoder := yaml.NewEncoder(&data)
+	encoder.SetIndent(c.indent)
+	if err := encoder.Encode(c.values); err != nil {
+		return nil, errors.Wrap(err, "failed to marshal values.yaml")
+	}
+	return data.Bytes(), nil
+}
+
+// helpersTpl returns the content of templates/_helpers.tpl
+func (c *helmChart) helpersTpl() []byte {
+	return []byte(fmt.Sprintf(`{{/*
+Common labels
+*/}}
+{{- define "%[1]s.labels" -}}
+helm.sh/chart: {{ printf "%%s-%%s" .Chart.Name .Chart.Version | replace "+" "_" | trunc 63 | trimSuffix "-" }}
+app.kubernetes.io/managed-by: {{ .Release.Service }}
+app.kubernetes.io/instance: {{ .Release.Name }}
+{{- if .Chart.AppVersion }}
+app.kubernetes.io/version: {{ .Chart.AppVersion | quote }}
+{{- end }}
+{{- end }}
+`, c.name))
+}
+
+// notesTxt returns the content of templates/NOTES.txt, printed by Helm after install
+func (c *helmChart) notesTxt() []byte {
+	var notes bytes.Buffer
+	notes.WriteString("{{ .Chart.Name }} is installed as release {{ .Release.Name }} in namespace {{ .Release.Namespace }}.\n\n")
+	if len(c.endpoints) == 0 {
+		notes.WriteString("No service is exposed outside of the cluster, use kubectl port-forward to reach them.\n")
+		return notes.Bytes()
+	}
+	notes.WriteString("Exposed endpoints:\n")
+	for _, endpoint := range c.endpoints {
+		notes.WriteString("  " + endpoint + "\n")
+	}
+	return notes.Bytes()
+}
+
+// helmPath returns a copy of path extended with keys
+func helmPath(path []string, keys ...string) []string {
+	return append(append([]string{}, path...), keys...)
+}
+
+// helmValuesRef returns the template reference to the value at path in values.yaml
+func helmValuesRef(path []string) string {
+	for _, key := range path {
+		if !helmIdentifierRegexp.MatchString(key) {
+			keys := make([]string, len(path))
+			for i, k := range path {
+				keys[i] = strconv.Quote(k)
+			}
+			return "(index .Values " + strings.Join(keys, " ") + ")"
+		}
+	}
+	return ".Values." + strings.Join(path, ".")
+}
+
+// helmPodSpec returns the pod spec of a workload, CronJob or Pod
+func helmPodSpec(m map[string]interface{}) map[string]interface{} {
+	spec, _ := m["spec"].(map[string]interface{})
+	if m["kind"] == "Pod" {
+		return spec
+	}
+	if jobTemplate, ok := spec["jobTemplate"].(map[string]interface{}); ok {
+		spec, _ = jobTemplate["spec"].(map[string]interface{})
+	}
+	template, _ := spec["template"].(map[string]interface{})
+	podSpec, _ := template["spec"].(map[string]interface{})
+	return podSpec
+}
+
+// helmServiceName returns the compose service an object was converted from
+func helmServiceName(m map[string]interface{}) string {
+	metadata, _ := m["metadata"].(map[string]interface{})
+	labels, _ := metadata["labels"].(map[string]interface{})
+	if service, ok := labels[transformer.Selector].(string); ok {
+		return service
+	}
+	return helmObjectName(m)
+}
+
+func helmObjectName(m map[string]interface{}) string {
+	metadata, _ := m["metadata"].(map[string]interface{})
+	name, _ := metadata["name"].(string)
+	return name
+}
+
+// splitImage splits an image reference into its repository and tag, images pinned by digest are kept whole
+func splitImage(image string) (string, string) {
+	if strings.Contains(image, "@") {
+		return image, ""
+	}
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		return image[:i], image[i+1:]
+	}
+	return image, ""
+}
@@ -0,0 +1,299 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/kubernetes/kompose/pkg/kobject"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// renderHelmTemplate renders a chart template with the subset of the Helm functions used by kompose
+func renderHelmTemplate(t *testing.T, chart *helmChart, manifest []byte, values map[string]interface{}) []byte {
+	tmpl := template.New("chart")
+	tmpl.Funcs(template.FuncMap{
+		"toYaml": func(v interface{}) string {
+			data, _ := yaml.Marshal(v)
+			return strings.TrimSuffix(string(data), "\n")
+		},
+		"nindent": func(n int, s string) string {
+			pad := strings.Repeat(" ", n)
+			return "\n" + pad + strings.ReplaceAll(s, "\n", "\n"+pad)
+		},
+		"quote":   func(v interface{}) string { return fmt.Sprintf("%q", fmt.Sprint(v)) },
+		"replace": func(old, new, s string) string { return strings.ReplaceAll(s, old, new) },
+		"trunc": func(n int, s string) string {
+			if len(s) > n {
+				return s[:n]
+			}
+			return s
+		},
+		"trimSuffix": func(suffix, s string) string { return strings.TrimSuffix(s, suffix) },
+		"include": func(name string, data interface{}) (string, error) {
+			var buf bytes.Buffer
+			err := tmpl.ExecuteTemplate(&buf, name, data)
+			return buf.String(), err
+		},
+	})
+	template.Must(tmpl.Parse(string(chart.helpersTpl())))
+	template.Must(tmpl.New("manifest").Parse(string(manifest)))
+
+	data := map[string]interface{}{
+		"Values":  values,
+		"Chart":   map[string]interface{}{"Name": chart.name, "Version": "0.0.1", "AppVersion": ""},
+		"Release": map[string]interface{}{"Name": "release", "Service": "Helm"},
+	}
+	var buf bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&buf, "manifest", data); err != nil {
+		t.Fatal(errors.Wrap(err, "failed to render template"))
+	}
+	return buf.Bytes()
+}
+
+func TestHelmChartTemplates(t *testing.T) {
+	serviceConfig := newServiceConfig()
+	serviceConfig.Name = "web-app"
+	serviceConfig.Image = "registry:5000/team/web:1.2"
+	serviceConfig.Replicas = 2
+	serviceConfig.ExposeService = "example.com"
+	serviceConfig.MemLimit = 1024
+	komposeObject := kobject.KomposeObject{
+		ServiceConfigs: map[string]kobject.ServiceConfig{"web-app": serviceConfig},
+	}
+
+	k := Kubernetes{}
+	objects, err := k.Transform(komposeObject, kobject.ConvertOptions{CreateD: true, Replicas: 1})
+	if err != nil {
+		t.Fatal(errors.Wrap(err, "k.Transform failed"))
+	}
+	chart, err := newHelmChart("mychart", 2, objects)
+	if err != nil {
+		t.Fatal(errors.Wrap(err, "newHelmChart failed"))
+	}
+
+	manifests := make([][]byte, len(objects))
+	for i, obj := range objects {
+		if manifests[i], err = chart.template(obj); err != nil {
+			t.Fatal(errors.Wrap(err, "chart.template failed"))
+		}
+	}
+
+	valuesData, err := chart.valuesYAML()
+	if err != nil {
+		t.Fatal(errors.Wrap(err, "chart.valuesYAML failed"))
+	}
+	values := map[string]interface{}{}
+	if err := yaml.Unmarshal(valuesData, &values); err != nil {
+		t.Fatal(err)
+	}
+	service := values["web-app"].(map[string]interface{})
+	image := service["image"].(map[string]interface{})
+	if image["repository"] != "registry:5000/team/web" || image["tag"] != "1.2" {
+		t.Errorf("Expected image repository and tag to be lifted, got %v", image)
+	}
+	if service["replicas"] != 2 {
+		t.Errorf("Expected replicas 2, got %v", service["replicas"])
+	}
+	if ingress := service["ingress"].(map[string]interface{}); !reflect.DeepEqual(ingress["hosts"], []interface{}{"example.com"}) {
+		t.Errorf("Expected ingress hosts to be lifted, got %v", ingress)
+	}
+	// The default service type is written so that it can be switched to NodePort or LoadBalancer
+	if serviceValues := service["service"].(map[string]interface{}); serviceValues["type"] != string(corev1.ServiceTypeClusterIP) {
+		t.Errorf("Expected service type ClusterIP to be lifted, got %v", serviceValues)
+	}
+
+	// Rendering the templates with the generated values gives back the converted objects
+	for i, obj := range objects {
+		rendered := map[string]interface{}{}
+		if err := yaml.Unmarshal(renderHelmTemplate(t, chart, manifests[i], values), &rendered); err != nil {
+			t.Fatalf("Rendered template of %v is not valid YAML: %v\n%s", obj.GetObjectKind().GroupVersionKind().Kind, err, manifests[i])
+		}
+		labels := rendered["metadata"].(map[string]interface{})["labels"].(map[string]interface{})
+		if labels["app.kubernetes.io/managed-by"] != "Helm" || labels["app.kubernetes.io/instance"] != "release" {
+			t.Errorf("Expected the standard labels from _helpers.tpl, got %v", labels)
+		}
+		for key := range labels {
+			if strings.HasPrefix(key, "app.kubernetes.io/") || strings.HasPrefix(key, "helm.sh/") {
+				delete(labels, key)
+			}
+		}
+
+		data, err := marshal(obj, false, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := map[string]interface{}{}
+		if err := yaml.Unmarshal(data, &expected); err != nil {
+			t.Fatal(err)
+		}
+		if expected["kind"] == "Service" {
+			expected["spec"].(map[string]interface{})["type"] = string(corev1.ServiceTypeClusterIP)
+		}
+		if !reflect.DeepEqual(rendered, expected) {
+			t.Errorf("Rendered template differs from the converted object:\n%s\nexpected:\n%s", manifests[i], data)
+		}
+	}
+}
+
+func TestHelmChartHeadlessService(t *testing.T) {
+	serviceConfig := newServiceConfig()
+	serviceConfig.ServiceType = "Headless"
+	komposeObject := kobject.KomposeObject{
+		ServiceConfigs: map[string]kobject.ServiceConfig{"app": serviceConfig},
+	}
+
+	k := Kubernetes{}
+	objects, err := k.Transform(komposeObject, kobject.ConvertOptions{CreateD: true, Replicas: 1})
+	if err != nil {
+		t.Fatal(errors.Wrap(err, "k.Transform failed"))
+	}
+	chart, err := newHelmChart("mychart", 2, objects)
+	if err != nil {
+		t.Fatal(errors.Wrap(err, "newHelmChart failed"))
+	}
+	for _, obj := range objects {
+		if _, err := chart.template(obj); err != nil {
+			t.Fatal(errors.Wrap(err, "chart.template failed"))
+		}
+	}
+	if _, ok := chart.values["app"].(map[string]interface{})["service"]; ok {
+		t.Errorf("Expected no service type value for a headless service, got %v", chart.values["app"])
+	}
+}
+
+func TestSplitImage(t *testing.T) {
+	testCases := map[string][2]string{
+		"nginx":                         {"nginx", ""},
+		"nginx:1.25":                    {"nginx", "1.25"},
+		"registry:5000/team/web":        {"registry:5000/team/web", ""},
+		"registry:5000/team/web:1.2":    {"registry:5000/team/web", "1.2"},
+		"nginx@sha256:0123456789abcdef": {"nginx@sha256:0123456789abcdef", ""},
+	}
+
+	for image, want := range testCases {
+		if repository, tag := splitImage(image); repository != want[0] || tag != want[1] {
+			t.Errorf("Expected %q to be split into %q and %q, got %q and %q", image, want[0], want[1], repository, tag)
+		}
+	}
+}
+
+func TestHelmChartNotes(t *testing.T) {
+	serviceConfig := newServiceConfig()
+	serviceConfig.ExposeService = "example.com"
+	serviceConfig.ServiceType = string(corev1.ServiceTypeNodePort)
+	komposeObject := kobject.KomposeObject{
+		ServiceConfigs: map[string]kobject.ServiceConfig{"app": serviceConfig},
+	}
+
+	k := Kubernetes{}
+	objects, err := k.Transform(komposeObject, kobject.ConvertOptions{CreateD: true, Replicas: 1})
+	if err != nil {
+		t.Fatal(errors.Wrap(err, "k.Transform failed"))
+	}
+	chart, err := newHelmChart("mychart", 2, objects)
+	if err != nil {
+		t.Fatal(errors.Wrap(err, "newHelmChart failed"))
+	}
+	for _, obj := range objects {
+		if _, err := chart.template(obj); err != nil {
+			t.Fatal(errors.Wrap(err, "chart.template failed"))
+		}
+	}
+
+	notes := string(renderHelmTemplate(t, chart, chart.notesTxt(), chart.values))
+	for _, expected := range []string{"http://example.com/", "NodePort service", "service app"} {
+		if !strings.Contains(notes, expected) {
+			t.Errorf("Expected NOTES.txt to contain %q, got:\n%s", expected, notes)
+		}
+	}
+}
+
+func TestPrintListChartPackage(t *testing.T) {
+	k := Kubernetes{}
+	objects, err := k.Transform(newKomposeObject(), kobject.ConvertOptions{CreateD: true, Replicas: 1})
+	if err != nil {
+		t.Fatal(errors.Wrap(err, "k.Transform failed"))
+	}
+
+	outDir := t.TempDir()
+	opt := kobject.ConvertOptions{
+		CreateChart:  true,
+		ChartPackage: true,
+		ChartName:    "mychart",
+		ChartVersion: "1.2.3",
+		AppVersion:   "2.0",
+		OutFile:      outDir,
+		InputFiles:   []string{"docker-compose.yaml"},
+		YAMLIndent:   2,
+	}
+	if err := PrintList(objects, opt); err != nil {
+		t.Fatal(errors.Wrap(err, "PrintList failed"))
+	}
+
+	f, err := os.Open(filepath.Join(outDir, "mychart-1.2.3.tgz"))
+	if err != nil {
+		t.Fatal(errors.Wrap(err, "chart package not found"))
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(errors.Wrap(err, "chart package is not gzip compressed"))
+	}
+
+	files := map[string]string{}
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := io.ReadAll(tr)
+		files[header.Name] = string(data)
+	}
+
+	for _, name := range []string{"mychart/Chart.yaml", "mychart/values.yaml", "mychart/.helmignore", "mychart/templates/NOTES.txt", "mychart/templates/_helpers.tpl", "mychart/templates/app-deployment.yaml"} {
+		if _, ok := files[name]; !ok {
+			t.Errorf("Expected %s in the chart package", name)
+		}
+	}
+	chartMetadata := map[string]interface{}{}
+	if err := yaml.Unmarshal([]byte(files["mychart/Chart.yaml"]), &chartMetadata); err != nil {
+		t.Fatal(err)
+	}
+	if chartMetadata["name"] != "mychart" || chartMetadata["version"] != "1.2.3" || chartMetadata["appVersion"] != "2.0" {
+		t.Errorf("Unexpected Chart.yaml: %v", chartMetadata)
+	}
+	if entries, _ := os.ReadDir(outDir); len(entries) != 1 {
+		t.Errorf("Expected only the chart package to be written, got %v", entries)
+	}
+}
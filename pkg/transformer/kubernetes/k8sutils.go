@@ -35,6 +35,7 @@ import (
 	"github.com/kubernetes/kompose/pkg/kobject"
 	"github.com/kubernetes/kompose/pkg/loader/compose"
 	"github.com/kubernetes/kompose/pkg/transformer"
+	"github.com/kubernetes/kompose/pkg/utils/archive"
 	deployapi "github.com/openshift/api/apps/v1"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
@@ -47,69 +48,139 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// outputWriter writes the files of PrintList, to the disk or to an archive
+type outputWriter interface {
+	MkdirAll(path string, perm os.FileMode) error
+	WriteFile(name string, data []byte, perm os.FileMode) error
+}
+
+// diskWriter writes the files of PrintList to the disk
+type diskWriter struct{}
+
+func (diskWriter) MkdirAll(path string, perm os.FileMode) error {
+	return os.MkdirAll(path, perm)
+}
+
+func (diskWriter) WriteFile(name string, data []byte, perm os.FileMode) error {
+	return os.WriteFile(name, data, perm)
+}
+
 /**
  * Generate Helm Chart configuration
  */
-func generateHelm(dirName string) error {
+func generateHelm(dirName string, chart *helmChart, opt kobject.ConvertOptions, out outputWriter) error {
 	type ChartDetails struct {
-		Name string
+		Name        string
+		Description string
+		Version     string
+		AppVersion  string
+	}
+
+	details := ChartDetails{
+		Name:        chart.name,
+		Description: opt.ChartDescription,
+		Version:     opt.ChartVersion,
+		AppVersion:  opt.AppVersion,
+	}
+	if details.Description == "" {
+		details.Description = fmt.Sprintf("A generated Helm Chart for %s from Kompose", chart.name)
+	}
+	if details.Version == "" {
+		details.Version = DefaultChartVersion
 	}
 
-	details := ChartDetails{dirName}
 	manifestDir := dirName + string(os.PathSeparator) + "templates"
-	dir, err := os.Open(dirName)
 
 	/* Setup the initial directories/files */
-	if err == nil {
-		_ = dir.Close()
+	if err := out.MkdirAll(manifestDir, 0755); err != nil {
+		return err
 	}
 
+	/* Create the values file, and the helpers and notes used by the templates */
+	values, err := chart.valuesYAML()
 	if err != nil {
-		err = os.Mkdir(dirName, 0755)
-		if err != nil {
-			return err
-		}
-
-		err = os.Mkdir(manifestDir, 0755)
-		if err != nil {
-			return err
-		}
+		return err
+	}
+	err = out.WriteFile(dirName+string(os.PathSeparator)+"values.yaml", values, 0644)
+	if err != nil {
+		return err
+	}
+	err = out.WriteFile(manifestDir+string(os.PathSeparator)+"_helpers.tpl", chart.helpersTpl(), 0644)
+	if err != nil {
+		return err
+	}
+	err = out.WriteFile(manifestDir+string(os.PathSeparator)+"NOTES.txt", chart.notesTxt(), 0644)
+	if err != nil {
+		return err
+	}
+	err = out.WriteFile(dirName+string(os.PathSeparator)+".helmignore", []byte(helmIgnore), 0644)
+	if err != nil {
+		return err
 	}
 
 	/* Create the readme file */
 	readme := "This chart was created by Kompose\n"
-	err = os.WriteFile(dirName+string(os.PathSeparator)+"README.md", []byte(readme), 0644)
+	err = out.WriteFile(dirName+string(os.PathSeparator)+"README.md", []byte(readme), 0644)
 	if err != nil {
 		return err
 	}
 
 	/* Create the Chart.yaml file */
-	chart := `name: {{.Name}}
-description: A generated Helm Chart for {{.Name}} from Skippbox Kompose
-version: 0.0.1
+	chartTmpl := `name: {{.Name}}
+description: {{printf "%q" .Description}}
+version: {{printf "%q" .Version}}
+{{- if .AppVersion}}
+appVersion: {{printf "%q" .AppVersion}}
+{{- end}}
 apiVersion: v2
 keywords:
   - {{.Name}}
-sources:
-home:
 `
 
-	t, err := template.New("ChartTmpl").Parse(chart)
+	t, err := template.New("ChartTmpl").Parse(chartTmpl)
 	if err != nil {
 		return errors.Wrap(err, "Failed to generate Chart.yaml template, template.New failed")
 	}
 	var chartData bytes.Buffer
 	_ = t.Execute(&chartData, details)
 
-	err = os.WriteFile(dirName+string(os.PathSeparator)+"Chart.yaml", chartData.Bytes(), 0644)
+	err = out.WriteFile(dirName+string(os.PathSeparator)+"Chart.yaml", chartData.Bytes(), 0644)
 	if err != nil {
 		return err
 	}
 
-	log.Infof("chart created in %q\n", dirName+string(os.PathSeparator))
+	if archive.IsArchive(opt.OutFile) {
+		return nil
+	}
+	if !opt.ChartPackage {
+		log.Infof("chart created in %q\n", dirName+string(os.PathSeparator))
+		return nil
+	}
+
+	/* Package the chart like helm package does */
+	packageDir := opt.OutFile
+	if packageDir == "" {
+		packageDir = "."
+	}
+	if err := os.MkdirAll(packageDir, 0755); err != nil {
+		return err
+	}
+	target := filepath.Join(packageDir, fmt.Sprintf("%s-%s.tgz", details.Name, details.Version))
+	if err := archive.CreateTarball(dirName, target); err != nil {
+		return errors.Wrap(err, "failed to package the chart")
+	}
+	log.Infof("chart packaged in %q\n", target)
 	return nil
 }
 
+// getChartName returns the name of the Helm chart, the name of the chart directory by default
+func getChartName(opt kobject.ConvertOptions) string {
+	if opt.ChartName != "" {
+		return opt.ChartName
+	}
+	return filepath.Base(getDirName(opt))
+}
+
 // Check if given path is a directory
 func isDir(name string) (bool, error) {
 	// Open file to get stat later
@@ -134,6 +205,10 @@ func isDir(name string) (bool, error) {
 
 func getDirName(opt kobject.ConvertOptions) string {
 	dirName := opt.OutFile
+	if opt.ChartPackage || archive.IsArchive(opt.OutFile) {
+		// --out is where the package or the archive is written, the chart is named after the compose file
+		dirName = ""
+	}
 	if dirName == "" {
 		// Let assume all the docker-compose files are in the same directory
 		if opt.CreateChart {
@@ -147,12 +222,31 @@ func getDirName(opt kobject.ConvertOptions) string {
 	return dirName
 }
 
+// OutputFormatYAMLList writes the objects printed to stdout or to a single file as one v1 List document
+const OutputFormatYAMLList = "yaml-list"
+
 // PrintList will take the data converted and decide on the commandline attributes given
 func PrintList(objects []runtime.Object, opt kobject.ConvertOptions) error {
+	if opt.Merge {
+		return printMerged(objects, opt)
+	}
+
 	var f *os.File
+	var out outputWriter = diskWriter{}
 	dirName := getDirName(opt)
 	log.Debugf("Target Dir: %s", dirName)
 
+	// The files are written in memory and archived when --out is an archive
+	var archiveWriter *archive.Writer
+	if archive.IsArchive(opt.OutFile) {
+		archiveWriter = archive.NewWriter(opt.OutFile)
+		out = archiveWriter
+		if opt.CreateChart {
+			// The chart is a directory of the archive, like in the packages of Helm
+			dirName = getChartName(opt)
+		}
+	}
+
 	// Create a directory if "out" ends with "/" and does not exist.
 	if !transformer.Exists(opt.OutFile) && strings.HasSuffix(opt.OutFile, "/") {
 		if err := os.MkdirAll(opt.OutFile, os.ModePerm); err != nil {
@@ -165,9 +259,18 @@ func PrintList(objects []runtime.Object, opt kobject.ConvertOptions) error {
 	if err != nil {
 		return errors.Wrap(err, "isDir failed")
 	}
-	if opt.CreateChart {
+	if opt.CreateChart || opt.Layout == LayoutPerService || archiveWriter != nil {
 		isDirVal = true
 	}
+	if opt.ChartPackage {
+		// Generate the chart in a temporary directory named after the chart, only the package is kept
+		tmpDir, err := os.MkdirTemp("", "kompose-chart-")
+		if err != nil {
+			return errors.Wrap(err, "failed to create a temporary directory")
+		}
+		defer os.RemoveAll(tmpDir)
+		dirName = filepath.Join(tmpDir, getChartName(opt))
+	}
 	if !isDirVal {
 		f, err = transformer.CreateOutFile(opt.OutFile)
 		if err != nil {
@@ -180,12 +283,33 @@ func PrintList(objects []runtime.Object, opt kobject.ConvertOptions) error {
 	}
 
 	var files []string
+	var chart *helmChart
 	// if asked to print to stdout or to put in single file
 	// we will create a list
 	if opt.ToStdout || f != nil {
-		// convert objects to versioned and add them to list
-		if opt.GenerateJSON {
-			return fmt.Errorf("cannot convert to one file while specifying a json output file or stdout option")
+		// JSON has no multi-document syntax, the objects are items of a v1 List
+		if opt.GenerateJSON || opt.OutputFormat == OutputFormatYAMLList {
+			list := &api.List{
+				TypeMeta: metav1.TypeMeta{
+					Kind:       "List",
+					APIVersion: "v1",
+				},
+			}
+			for _, object := range objects {
+				versionedObject, err := convertToVersion(object)
+				if err != nil {
+					return err
+				}
+				list.Items = append(list.Items, runtime.RawExtension{Object: versionedObject})
+			}
+			data, err := marshal(list, opt.GenerateJSON, opt.YAMLIndent)
+			if err != nil {
+				return fmt.Errorf("error in marshalling the List: %v", err)
+			}
+			if _, err := transformer.Print("", dirName, "", data, opt.ToStdout, opt.GenerateJSON, f, opt.Provider); err != nil {
+				return errors.Wrap(err, "transformer to print to one single file failed")
+			}
+			return nil
 		}
 		for _, object := range objects {
 			versionedObject, err := convertToVersion(object)
@@ -206,50 +330,54 @@ func PrintList(objects []runtime.Object, opt kobject.ConvertOptions) error {
 			files = append(files, printVal)
 		}
 	} else {
+		if opt.OutputFormat == OutputFormatYAMLList {
+			return fmt.Errorf("--output-format=%s writes a single file, use --stdout or set --out to a file", OutputFormatYAMLList)
+		}
+		if opt.Layout == LayoutPerService {
+			if err := printPerService(objects, dirName, opt, out); err != nil {
+				return err
+			}
+			return closeArchive(archiveWriter, opt)
+		}
 		finalDirName := dirName
 		if opt.CreateChart {
 			finalDirName = dirName + string(os.PathSeparator) + "templates"
 		}
 
-		if err := os.MkdirAll(finalDirName, 0755); err != nil {
+		if err := out.MkdirAll(finalDirName, 0755); err != nil {
 			return err
 		}
 
+		if opt.CreateChart {
+			chart, err = newHelmChart(getChartName(opt), opt.YAMLIndent, objects)
+			if err != nil {
+				return errors.Wrap(err, "newHelmChart failed")
+			}
+		}
+
 		var file string
 		// create a separate file for each provider
-		for _, v := range objects {
+		for i, v := range objects {
 			versionedObject, err := convertToVersion(v)
 			if err != nil {
 				return err
 			}
-			data, err := marshal(versionedObject, opt.GenerateJSON, opt.YAMLIndent)
+			var data []byte
+			if chart != nil {
+				data, err = chart.template(versionedObject)
+			} else {
+				data, err = marshal(versionedObject, opt.GenerateJSON, opt.YAMLIndent)
+			}
 			if err != nil {
 				return err
 			}
 
-			var typeMeta metav1.TypeMeta
-			var objectMeta metav1.ObjectMeta
-
-			if us, ok := v.(*unstructured.Unstructured); ok {
-				typeMeta = metav1.TypeMeta{
-					Kind:       us.GetKind(),
-					APIVersion: us.GetAPIVersion(),
-				}
-				objectMeta = metav1.ObjectMeta{
-					Name: us.GetName(),
-				}
-			} else {
-				val := reflect.ValueOf(v).Elem()
-				// Use reflect to access TypeMeta struct inside runtime.Object.
-				// cast it to correct type - metav1.TypeMeta
-				typeMeta = val.FieldByName("TypeMeta").Interface().(metav1.TypeMeta)
-
-				// Use reflect to access ObjectMeta struct inside runtime.Object.
-				// cast it to correct type - api.ObjectMeta
-				objectMeta = val.FieldByName("ObjectMeta").Interface().(metav1.ObjectMeta)
+			typeMeta, objectMeta := getTypeAndObjectMeta(v)
+			name := objectMeta.Name
+			if opt.NumberedFiles && chart == nil {
+				name = numberedFileName(name, i, len(objects))
 			}
-
-			file, err = transformer.Print(objectMeta.Name, finalDirName, strings.ToLower(typeMeta.Kind), data, opt.ToStdout, opt.GenerateJSON, f, opt.Provider)
+			file, err = printObjectFile(out, name, finalDirName, strings.ToLower(typeMeta.Kind), data, opt)
 			if err != nil {
 				return errors.Wrap(err, "transformer.Print failed")
 			}
@@ -258,14 +386,58 @@ func PrintList(objects []runtime.Object, opt kobject.ConvertOptions) error {
 		}
 	}
 	if opt.CreateChart {
-		err = generateHelm(dirName)
+		err = generateHelm(dirName, chart, opt, out)
 		if err != nil {
 			return errors.Wrap(err, "generateHelm failed")
 		}
 	}
+	return closeArchive(archiveWriter, opt)
+}
+
+// closeArchive writes the archive of the files when --out is an archive
+func closeArchive(archiveWriter *archive.Writer, opt kobject.ConvertOptions) error {
+	if archiveWriter == nil {
+		return nil
+	}
+	if err := archiveWriter.Close(); err != nil {
+		return err
+	}
+	log.Printf("Kubernetes archive %q created", opt.OutFile)
 	return nil
 }
 
+// numberedFileName prefixes name with the position of the object, padded so that the files
+// sort by name in the order the objects are installed, e.g. 01-db for the first of 10 objects
+func numberedFileName(name string, index, count int) string {
+	width := len(strconv.Itoa(count))
+	if width < 2 {
+		width = 2
+	}
+	return fmt.Sprintf("%0*d-%s", width, index+1, name)
+}
+
+// getTypeAndObjectMeta returns the TypeMeta and ObjectMeta of a typed or unstructured object
+func getTypeAndObjectMeta(v runtime.Object) (metav1.TypeMeta, metav1.ObjectMeta) {
+	if us, ok := v.(*unstructured.Unstructured); ok {
+		return metav1.TypeMeta{
+			Kind:       us.GetKind(),
+			APIVersion: us.GetAPIVersion(),
+		}, metav1.ObjectMeta{
+			Name: us.GetName(),
+		}
+	}
+
+	val := reflect.ValueOf(v).Elem()
+	// Use reflect to access TypeMeta struct inside runtime.Object.
+	// cast it to correct type - metav1.TypeMeta
+	typeMeta := val.FieldByName("TypeMeta").Interface().(metav1.TypeMeta)
+
+	// Use reflect to access ObjectMeta struct inside runtime.Object.
+	// cast it to correct type - api.ObjectMeta
+	objectMeta := val.FieldByName("ObjectMeta").Interface().(metav1.ObjectMeta)
+	return typeMeta, objectMeta
+}
+
 // marshal object runtime.Object and return byte array
 func marshal(obj runtime.Object, jsonFormat bool, indent int) (data []byte, err error) {
 	// convert data to yaml or json
@@ -687,7 +859,9 @@ func getServiceGroupID(service kobject.ServiceConfig, mode string) string {
 func KomposeObjectToServiceConfigGroupMapping(komposeObject *kobject.KomposeObject, opt kobject.ConvertOptions) map[string]kobject.ServiceConfigGroup {
 	serviceConfigGroup := make(map[string]kobject.ServiceConfigGroup)
 
-	for name, service := range komposeObject.ServiceConfigs {
+	// The services of a group are in the order of their names, so that the containers and the name of a volume group are stable
+	for _, name := range SortedKeys(*komposeObject) {
+		service := komposeObject.ServiceConfigs[name]
 		groupID := getServiceGroupID(service, opt.ServiceGroupMode)
 		if groupID != "" {
 			service.Name = name
@@ -733,6 +907,45 @@ func TranslatePodResource(service *kobject.ServiceConfig, template *api.PodTempl
 	}
 }
 
+// HPAValues holds the autoscaling settings of a service
+type HPAValues struct {
+	MinReplicas int32
+	MaxReplicas int32
+	CPU         int32
+	Memory      int32
+}
+
+// DefaultHPACPUUtilization is the target cpu utilization used when no metric label is set, same as kubectl autoscale
+const DefaultHPACPUUtilization = 80
+
+// GetHPAValues returns the autoscaling settings of the kompose.hpa.* labels of the service
+// return nil if the service shouldn't be autoscaled
+func GetHPAValues(service kobject.ServiceConfig) (*HPAValues, error) {
+	if service.HPAMinReplicas == 0 && service.HPAMaxReplicas == 0 && service.HPACPU == 0 && service.HPAMemory == 0 {
+		return nil, nil
+	}
+
+	values := &HPAValues{
+		MinReplicas: service.HPAMinReplicas,
+		MaxReplicas: service.HPAMaxReplicas,
+		CPU:         service.HPACPU,
+		Memory:      service.HPAMemory,
+	}
+	if values.MinReplicas == 0 {
+		values.MinReplicas = 1
+	}
+	if values.MaxReplicas == 0 {
+		return nil, errors.Errorf("label %s is required to autoscale the service", compose.LabelHPAMaxReplicas)
+	}
+	if values.MinReplicas > values.MaxReplicas {
+		return nil, errors.Errorf("%s (%d) can't be greater than %s (%d)", compose.LabelHPAMinReplicas, values.MinReplicas, compose.LabelHPAMaxReplicas, values.MaxReplicas)
+	}
+	if values.CPU == 0 && values.Memory == 0 {
+		values.CPU = DefaultHPACPUUtilization
+	}
+	return values, nil
+}
+
 // GetImagePullPolicy get image pull settings
 func GetImagePullPolicy(name, policy string) (api.PullPolicy, error) {
 	switch policy {
@@ -763,22 +976,61 @@ func GetRestartPolicy(name, restart string) (api.RestartPolicy, error) {
 	}
 }
 
-// SortServicesFirst - the objects that we get can be in any order this keeps services first
-// according to best practice kubernetes services should be created first
-// http://kubernetes.io/docs/user-guide/config-best-practices/
-func (k *Kubernetes) SortServicesFirst(objs *[]runtime.Object) {
-	var svc, others, ret []runtime.Object
+// installOrder is the rank of each kind in the order objects are installed, the objects
+// referenced by others come first so that e.g. a Deployment is never applied before its
+// PersistentVolumeClaims, ConfigMaps and Secrets
+var installOrder = map[string]int{
+	"Namespace":               0,
+	"ServiceAccount":          1,
+	"Role":                    2,
+	"ClusterRole":             2,
+	"RoleBinding":             3,
+	"ClusterRoleBinding":      3,
+	"Secret":                  4,
+	"ConfigMap":               5,
+	"PersistentVolumeClaim":   6,
+	"Service":                 7,
+	"ImageStream":             8,
+	"BuildConfig":             8,
+	"Deployment":              8,
+	"DeploymentConfig":        8,
+	"StatefulSet":             8,
+	"DaemonSet":               8,
+	"ReplicationController":   8,
+	"Rollout":                 8,
+	"Job":                     8,
+	"CronJob":                 8,
+	"Pod":                     8,
+	"HorizontalPodAutoscaler": 9,
+	"PodDisruptionBudget":     9,
+	"Ingress":                 10,
+	"Route":                   10,
+	"HTTPRoute":               10,
+	"NetworkPolicy":           11,
+}
 
-	for _, obj := range *objs {
-		if obj.GetObjectKind().GroupVersionKind().Kind == "Service" {
-			svc = append(svc, obj)
-		} else {
-			others = append(others, obj)
-		}
+// InstallRank returns the rank of obj in installOrder, kinds kompose doesn't know are installed last
+func InstallRank(obj runtime.Object) int {
+	gvk := obj.GetObjectKind().GroupVersionKind()
+	// Only the core Service is a dependency of the workloads, a Knative Service is a workload
+	if gvk.Kind == "Service" && gvk.Group != "" {
+		return installOrder["Deployment"]
 	}
-	ret = append(ret, svc...)
-	ret = append(ret, others...)
-	*objs = ret
+	if rank, ok := installOrder[gvk.Kind]; ok {
+		return rank
+	}
+	return len(installOrder)
+}
+
+// SortObjectsByInstallOrder - the objects that we get can be in any order, this sorts them in the order
+// they have to be installed: Namespace, ServiceAccount and RBAC, Secret, ConfigMap, PersistentVolumeClaim,
+// Service, the workloads, their autoscaling and disruption budgets, Ingress and Route, then NetworkPolicy.
+// Objects of the same kind keep their order.
+// http://kubernetes.io/docs/user-guide/config-best-practices/
+func (k *Kubernetes) SortObjectsByInstallOrder(objs *[]runtime.Object) {
+	sort.SliceStable(*objs, func(i, j int) bool {
+		return InstallRank((*objs)[i]) < InstallRank((*objs)[j])
+	})
 }
 
 // RemoveDupObjects remove objects that are dups...eg. configmaps from env.
@@ -17,18 +17,26 @@ limitations under the License.
 package kubernetes
 
 import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"io"
 	"os"
 	"path/filepath"
 	"reflect"
 	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/kubernetes/kompose/pkg/kobject"
 	"github.com/kubernetes/kompose/pkg/loader/compose"
 	"github.com/kubernetes/kompose/pkg/testutils"
 	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
 /*
@@ -657,3 +665,239 @@ func TestReadOnlyRootFS(t *testing.T) {
 		}
 	}
 }
+
+func TestGetHPAValues(t *testing.T) {
+	testCases := map[string]struct {
+		service  kobject.ServiceConfig
+		expected *HPAValues
+		hasError bool
+	}{
+		"no hpa labels": {
+			service:  kobject.ServiceConfig{Labels: map[string]string{"foo": "bar"}},
+			expected: nil,
+		},
+		"cpu default": {
+			service:  kobject.ServiceConfig{HPAMaxReplicas: 5},
+			expected: &HPAValues{MinReplicas: 1, MaxReplicas: 5, CPU: DefaultHPACPUUtilization},
+		},
+		"all labels": {
+			service:  kobject.ServiceConfig{HPAMinReplicas: 2, HPAMaxReplicas: 6, HPACPU: 60, HPAMemory: 70},
+			expected: &HPAValues{MinReplicas: 2, MaxReplicas: 6, CPU: 60, Memory: 70},
+		},
+		"missing max": {
+			service:  kobject.ServiceConfig{HPACPU: 50},
+			hasError: true,
+		},
+		"min greater than max": {
+			service:  kobject.ServiceConfig{HPAMinReplicas: 4, HPAMaxReplicas: 2},
+			hasError: true,
+		},
+	}
+
+	for name, test := range testCases {
+		result, err := GetHPAValues(test.service)
+		if test.hasError {
+			if err == nil {
+				t.Errorf("Case '%v' for TestGetHPAValues fail, expected an error", name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Case '%v' for TestGetHPAValues fail, unexpected error %v", name, err)
+		}
+		if !reflect.DeepEqual(result, test.expected) {
+			t.Errorf("Case '%v' for TestGetHPAValues fail, Expected '%v' , got '%v'", name, test.expected, result)
+		}
+	}
+}
+
+func TestSortObjectsByInstallOrder(t *testing.T) {
+	object := func(apiVersion, kind, name string) runtime.Object {
+		u := &unstructured.Unstructured{}
+		u.SetAPIVersion(apiVersion)
+		u.SetKind(kind)
+		u.SetName(name)
+		return u
+	}
+	objects := []runtime.Object{
+		object("networking.k8s.io/v1", "NetworkPolicy", "default"),
+		object("apps/v1", "Deployment", "web"),
+		object("networking.k8s.io/v1", "Ingress", "web"),
+		object("v1", "Service", "web"),
+		object("serving.knative.dev/v1", "Service", "api"),
+		object("example.com/v1", "Unknown", "web"),
+		object("v1", "PersistentVolumeClaim", "data"),
+		object("apps/v1", "Deployment", "db"),
+		object("v1", "ConfigMap", "web-env"),
+		object("v1", "Secret", "token"),
+		object("autoscaling/v2", "HorizontalPodAutoscaler", "web"),
+		object("v1", "ServiceAccount", "web"),
+		object("v1", "Namespace", "app"),
+		object("v1", "Service", "db"),
+	}
+	expected := []string{
+		"Namespace/app",
+		"ServiceAccount/web",
+		"Secret/token",
+		"ConfigMap/web-env",
+		"PersistentVolumeClaim/data",
+		"Service/web",
+		"Service/db",
+		"Deployment/web",
+		"Service/api",
+		"Deployment/db",
+		"HorizontalPodAutoscaler/web",
+		"Ingress/web",
+		"NetworkPolicy/default",
+		"Unknown/web",
+	}
+
+	k := Kubernetes{}
+	k.SortObjectsByInstallOrder(&objects)
+
+	var result []string
+	for _, obj := range objects {
+		result = append(result, obj.GetObjectKind().GroupVersionKind().Kind+"/"+obj.(*unstructured.Unstructured).GetName())
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected objects in the order %v, got %v", expected, result)
+	}
+}
+
+func TestNumberedFileName(t *testing.T) {
+	testCases := map[string]struct {
+		index    int
+		count    int
+		expected string
+	}{
+		"first of few":   {0, 5, "01-web"},
+		"last of ten":    {9, 10, "10-web"},
+		"first of many":  {0, 120, "001-web"},
+		"middle of many": {41, 120, "042-web"},
+	}
+
+	for name, test := range testCases {
+		if result := numberedFileName("web", test.index, test.count); result != test.expected {
+			t.Errorf("Case '%v' for TestNumberedFileName fail, Expected '%v' , got '%v'", name, test.expected, result)
+		}
+	}
+}
+
+func TestPrintListSingleFileList(t *testing.T) {
+	k := Kubernetes{}
+	objects, err := k.Transform(newKomposeObject(), kobject.ConvertOptions{CreateD: true, Replicas: 1})
+	if err != nil {
+		t.Fatal(errors.Wrap(err, "k.Transform failed"))
+	}
+
+	testCases := map[string]kobject.ConvertOptions{
+		"json":      {GenerateJSON: true},
+		"yaml-list": {OutputFormat: OutputFormatYAMLList, YAMLIndent: 2},
+	}
+
+	for name, opt := range testCases {
+		opt.OutFile = filepath.Join(t.TempDir(), "output")
+		if err := PrintList(objects, opt); err != nil {
+			t.Fatalf("Case '%v' for TestPrintListSingleFileList fail, PrintList failed: %v", name, err)
+		}
+		data, err := os.ReadFile(opt.OutFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// YAML is a superset of JSON, both outputs are read as YAML
+		list := struct {
+			APIVersion string                   `yaml:"apiVersion"`
+			Kind       string                   `yaml:"kind"`
+			Items      []map[string]interface{} `yaml:"items"`
+		}{}
+		if err := yaml.Unmarshal(data, &list); err != nil {
+			t.Fatalf("Case '%v' for TestPrintListSingleFileList fail, the output is not a single document: %v", name, err)
+		}
+		if list.APIVersion != "v1" || list.Kind != "List" {
+			t.Errorf("Case '%v' for TestPrintListSingleFileList fail, Expected a v1 List, got %s %s", name, list.APIVersion, list.Kind)
+		}
+		if len(list.Items) != len(objects) {
+			t.Errorf("Case '%v' for TestPrintListSingleFileList fail, Expected %d items, got %d", name, len(objects), len(list.Items))
+		}
+	}
+}
+
+func TestPrintListArchive(t *testing.T) {
+	k := Kubernetes{}
+	objects, err := k.Transform(newKomposeObject(), kobject.ConvertOptions{CreateD: true, Replicas: 1})
+	if err != nil {
+		t.Fatal(errors.Wrap(err, "k.Transform failed"))
+	}
+
+	testCases := map[string]struct {
+		out      string
+		chart    bool
+		expected []string
+	}{
+		"tar.gz":      {"manifests.tar.gz", false, []string{"app-deployment.yaml"}},
+		"zip":         {"manifests.zip", false, []string{"app-deployment.yaml"}},
+		"chart zip":   {"chart.zip", true, []string{"mychart/Chart.yaml", "mychart/values.yaml", "mychart/templates/app-deployment.yaml"}},
+		"chart tgz":   {"chart.tgz", true, []string{"mychart/Chart.yaml", "mychart/templates/_helpers.tpl", "mychart/templates/app-deployment.yaml"}},
+		"nested path": {"dist/out/manifests.tar", false, []string{"app-deployment.yaml"}},
+	}
+
+	for name, test := range testCases {
+		dir := t.TempDir()
+		opt := kobject.ConvertOptions{
+			CreateChart: test.chart,
+			ChartName:   "mychart",
+			OutFile:     filepath.Join(dir, test.out),
+			InputFiles:  []string{"docker-compose.yaml"},
+			YAMLIndent:  2,
+		}
+		if err := PrintList(objects, opt); err != nil {
+			t.Fatalf("Case '%v' for TestPrintListArchive fail, PrintList failed: %v", name, err)
+		}
+
+		files := map[string]bool{}
+		if strings.HasSuffix(test.out, ".zip") {
+			archive, err := zip.OpenReader(opt.OutFile)
+			if err != nil {
+				t.Fatalf("Case '%v' for TestPrintListArchive fail, the output is not a zip archive: %v", name, err)
+			}
+			for _, file := range archive.File {
+				files[file.Name] = true
+			}
+			archive.Close()
+		} else {
+			f, err := os.Open(opt.OutFile)
+			if err != nil {
+				t.Fatalf("Case '%v' for TestPrintListArchive fail, archive not found: %v", name, err)
+			}
+			var r io.Reader = f
+			if !strings.HasSuffix(test.out, ".tar") {
+				if r, err = gzip.NewReader(f); err != nil {
+					t.Fatalf("Case '%v' for TestPrintListArchive fail, the archive is not gzip compressed: %v", name, err)
+				}
+			}
+			tr := tar.NewReader(r)
+			for {
+				header, err := tr.Next()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				files[header.Name] = true
+			}
+			f.Close()
+		}
+
+		for _, file := range test.expected {
+			if !files[file] {
+				t.Errorf("Case '%v' for TestPrintListArchive fail, Expected %s in the archive, got %v", name, file, files)
+			}
+		}
+		// Only the archive is written
+		if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+			t.Errorf("Case '%v' for TestPrintListArchive fail, Expected only the archive in the output directory, got %d entries", name, len(entries))
+		}
+	}
+}
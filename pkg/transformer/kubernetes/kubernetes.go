@@ -19,6 +19,7 @@ package kubernetes
 import (
 	"encoding/base64"
 	"fmt"
+	"hash/fnv"
 	"os"
 	"os/exec"
 	"path"
@@ -42,10 +43,14 @@ import (
 	"github.com/spf13/cast"
 	"golang.org/x/tools/godoc/util"
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	batchv1 "k8s.io/api/batch/v1"
 	api "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
@@ -69,6 +74,17 @@ const (
 	DaemonSetController = "daemonset"
 	// StatefulStateController is controller type for StatefulSet
 	StatefulStateController = "statefulset"
+	// CronJobController is controller type for CronJob
+	CronJobController = "cronjob"
+	// RolloutController is controller type for Argo Rollouts Rollout
+	RolloutController = "rollout"
+)
+
+const (
+	// ExposeModeIngress exposes services with a networking.k8s.io/v1 Ingress
+	ExposeModeIngress = "ingress"
+	// ExposeModeGateway exposes services with a gateway.networking.k8s.io/v1 HTTPRoute
+	ExposeModeGateway = "gateway"
 )
 
 // CheckUnsupportedKey checks if given komposeObject contains
@@ -466,6 +482,112 @@ func (k *Kubernetes) InitSS(name string, service kobject.ServiceConfig, replicas
 	return ds
 }
 
+// InitCJ initializes Kubernetes CronJob object
+func (k *Kubernetes) InitCJ(name string, service kobject.ServiceConfig) *batchv1.CronJob {
+	var podSpec api.PodSpec
+	if len(service.Configs) > 0 {
+		podSpec = k.InitPodSpecWithConfigMap(name, service.Image, service)
+	} else {
+		podSpec = k.InitPodSpec(name, service.Image, service.ImagePullSecret)
+	}
+
+	cj := &batchv1.CronJob{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "CronJob",
+			APIVersion: "batch/v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: transformer.ConfigAllLabels(name, &service),
+		},
+		Spec: batchv1.CronJobSpec{
+			Schedule:          service.CronJobSchedule,
+			ConcurrencyPolicy: batchv1.ConcurrencyPolicy(service.CronJobConcurrencyPolicy),
+			JobTemplate: batchv1.JobTemplateSpec{
+				Spec: batchv1.JobSpec{
+					BackoffLimit: service.CronJobBackoffLimit,
+					Template: api.PodTemplateSpec{
+						Spec: podSpec,
+					},
+				},
+			},
+		},
+	}
+	return cj
+}
+
+// InitHPA initializes Kubernetes HorizontalPodAutoscaler object scaling the given workload
+func (k *Kubernetes) InitHPA(name string, service kobject.ServiceConfig, target autoscalingv2.CrossVersionObjectReference, values HPAValues) *autoscalingv2.HorizontalPodAutoscaler {
+	hpa := &autoscalingv2.HorizontalPodAutoscaler{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "HorizontalPodAutoscaler",
+			APIVersion: "autoscaling/v2",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Labels:      transformer.ConfigLabels(name),
+			Annotations: transformer.ConfigAnnotations(service),
+		},
+		Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: target,
+			MinReplicas:    &values.MinReplicas,
+			MaxReplicas:    values.MaxReplicas,
+		},
+	}
+
+	metric := func(resourceName api.ResourceName, utilization int32) autoscalingv2.MetricSpec {
+		return autoscalingv2.MetricSpec{
+			Type: autoscalingv2.ResourceMetricSourceType,
+			Resource: &autoscalingv2.ResourceMetricSource{
+				Name: resourceName,
+				Target: autoscalingv2.MetricTarget{
+					Type:               autoscalingv2.UtilizationMetricType,
+					AverageUtilization: &utilization,
+				},
+			},
+		}
+	}
+	if values.CPU != 0 {
+		hpa.Spec.Metrics = append(hpa.Spec.Metrics, metric(api.ResourceCPU, values.CPU))
+	}
+	if values.Memory != 0 {
+		hpa.Spec.Metrics = append(hpa.Spec.Metrics, metric(api.ResourceMemory, values.Memory))
+	}
+	return hpa
+}
+
+// InitPDB initializes Kubernetes PodDisruptionBudget object protecting the pods matched by selector
+func (k *Kubernetes) InitPDB(name string, service kobject.ServiceConfig, selector *metav1.LabelSelector) *policyv1.PodDisruptionBudget {
+	pdb := &policyv1.PodDisruptionBudget{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "PodDisruptionBudget",
+			APIVersion: "policy/v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Labels:      transformer.ConfigLabels(name),
+			Annotations: transformer.ConfigAnnotations(service),
+		},
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			Selector: selector,
+		},
+	}
+
+	if minAvailable, ok := service.Labels[compose.LabelPDBMinAvailable]; ok {
+		v := intstr.Parse(minAvailable)
+		pdb.Spec.MinAvailable = &v
+		return pdb
+	}
+
+	// Drain the pods at the same pace as a rolling update would replace them
+	maxUnavailable := intstr.FromInt(1)
+	if service.DeployUpdateConfig.Parallelism != nil && *service.DeployUpdateConfig.Parallelism > 0 {
+		maxUnavailable = intstr.FromInt(cast.ToInt(*service.DeployUpdateConfig.Parallelism))
+	}
+	pdb.Spec.MaxUnavailable = &maxUnavailable
+	return pdb
+}
+
 func (k *Kubernetes) initIngress(name string, service kobject.ServiceConfig, port int32) *networkingv1.Ingress {
 	hosts := regexp.MustCompile("[ ,]*,[ ,]*").Split(service.ExposeService, -1)
 
@@ -539,10 +661,134 @@ func (k *Kubernetes) initIngress(name string, service kobject.ServiceConfig, por
 	return ingress
 }
 
+// exposeMode returns how the service is exposed, the kompose.service.expose.mode label wins over --expose-mode
+func (k *Kubernetes) exposeMode(service kobject.ServiceConfig) string {
+	if service.ExposeServiceMode != "" {
+		return service.ExposeServiceMode
+	}
+	if k.Opt.ExposeMode != "" {
+		return k.Opt.ExposeMode
+	}
+	return ExposeModeIngress
+}
+
+// initHTTPRoutes creates a Gateway API HTTPRoute for each of the kompose.service.expose hosts, with the paths of the host.
+// TLS is terminated by the parent Gateway, so the route is attached to the listener named after the TLS secret.
+func (k *Kubernetes) initHTTPRoutes(name string, service kobject.ServiceConfig, port int32) []*unstructured.Unstructured {
+	gateway := service.ExposeServiceGateway
+	if gateway == "" {
+		gateway = k.Opt.Gateway
+	}
+	if gateway == "" {
+		log.Warnf("HTTPRoute for service %s is ignored, no parent Gateway is set with --gateway or the %s label", name, compose.LabelServiceExposeGateway)
+		return nil
+	}
+	if service.ExposeServiceIngressClassName != "" {
+		log.Warnf("%s is ignored for service %s, the class of an HTTPRoute is the one of its parent Gateway", compose.LabelServiceExposeIngressClassName, name)
+	}
+	if service.ExposeServiceTLS != "" {
+		log.Warnf("%s is ignored for service %s, TLS is terminated by the listeners of the parent Gateway", compose.LabelServiceExposeTLSSecret, name)
+	}
+
+	parentRef := map[string]interface{}{}
+	if i := strings.Index(gateway, "/"); i >= 0 {
+		parentRef["namespace"] = gateway[:i]
+		parentRef["name"] = gateway[i+1:]
+	} else {
+		parentRef["name"] = gateway
+	}
+
+	// The hostnames apply to every rule of a route, so each host gets its own route with only its paths,
+	// like the rules of an Ingress
+	var hosts []string
+	paths := map[string][]string{}
+	seenPaths := map[string]bool{}
+	for _, entry := range regexp.MustCompile("[ ,]*,[ ,]*").Split(service.ExposeService, -1) {
+		host, p := transformer.ParseIngressPath(entry)
+		if p == "" {
+			p = "/"
+		}
+		if host == "true" {
+			host = ""
+		}
+		if _, ok := paths[host]; !ok {
+			hosts = append(hosts, host)
+		}
+		if !seenPaths[host+p] {
+			seenPaths[host+p] = true
+			paths[host] = append(paths[host], p)
+		}
+	}
+
+	var routes []*unstructured.Unstructured
+	routeNames := map[string]bool{}
+	for _, host := range hosts {
+		var rules []interface{}
+		for _, p := range paths[host] {
+			rules = append(rules, map[string]interface{}{
+				"matches": []interface{}{
+					map[string]interface{}{
+						"path": map[string]interface{}{
+							"type":  "PathPrefix",
+							"value": p,
+						},
+					},
+				},
+				"backendRefs": []interface{}{
+					map[string]interface{}{
+						"name": name,
+						"port": int64(port),
+					},
+				},
+			})
+		}
+
+		spec := map[string]interface{}{
+			"parentRefs": []interface{}{parentRef},
+			"rules":      rules,
+		}
+		routeName := name
+		if host != "" {
+			spec["hostnames"] = []interface{}{host}
+			if len(hosts) > 1 {
+				routeName = name + "-" + strings.Trim(regexp.MustCompile("[^a-z0-9]+").ReplaceAllString(strings.ToLower(host), "-"), "-")
+				// Hosts differing only by their punctuation, e.g. *.example.com and example.com, get the same name
+				if routeNames[routeName] || strings.HasSuffix(routeName, "-") {
+					h := fnv.New32a()
+					h.Write([]byte(host))
+					routeName = fmt.Sprintf("%s-%08x", strings.TrimSuffix(routeName, "-"), h.Sum32())
+				}
+			}
+		}
+		routeNames[routeName] = true
+
+		route := &unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"apiVersion": "gateway.networking.k8s.io/v1",
+				"kind":       "HTTPRoute",
+				"spec":       spec,
+			},
+		}
+		route.SetName(routeName)
+		route.SetLabels(transformer.ConfigLabels(name))
+		if annotations := transformer.ConfigAnnotations(service); len(annotations) > 0 {
+			route.SetAnnotations(annotations)
+		}
+		routes = append(routes, route)
+	}
+	return routes
+}
+
 // CreateSecrets create secrets
 func (k *Kubernetes) CreateSecrets(komposeObject kobject.KomposeObject) ([]*api.Secret, error) {
 	var objects []*api.Secret
-	for name, config := range komposeObject.Secrets {
+	names := make([]string, 0, len(komposeObject.Secrets))
+	for name := range komposeObject.Secrets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		config := komposeObject.Secrets[name]
 		if config.File != "" {
 			dataString, err := GetContentFromFile(config.File)
 			if err != nil {
@@ -905,7 +1151,7 @@ func (k *Kubernetes) ConfigVolumes(name string, service kobject.ServiceConfig) (
 	}
 
 	// Override volume type if specified in service labels.
-	if vt, ok := service.Labels["kompose.volume.type"]; ok {
+	if vt, ok := service.Labels[compose.LabelVolumeType]; ok {
 		if _, okk := ValidVolumeSet[vt]; !okk {
 			return nil, nil, nil, nil, fmt.Errorf("invalid volume type %s specified in label 'kompose.volume.type' in service %s", vt, service.Name)
 		}
@@ -985,7 +1231,7 @@ func (k *Kubernetes) ConfigVolumes(name string, service kobject.ServiceConfig) (
 					for key, value := range service.Labels {
 						if key == "kompose.volume.size" {
 							defaultSize = value
-						} else if key == "kompose.volume.storage-class-name" {
+						} else if key == compose.LabelVolumeStorageClassName {
 							storageClassName = value
 						}
 					}
@@ -1198,17 +1444,35 @@ func configConstrains(constrains map[string]string, operator api.NodeSelectorOpe
 	if constraintsLen == 0 {
 		return rs
 	}
-	for k, v := range constrains {
+	keys := make([]string, 0, constraintsLen)
+	for k := range constrains {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
 		r := api.NodeSelectorRequirement{
 			Key:      k,
 			Operator: operator,
-			Values:   []string{v},
+			Values:   []string{constrains[k]},
 		}
 		rs = append(rs, r)
 	}
 	return rs
 }
 
+// CheckCronJobSchedule returns an error if the service is converted to a CronJob, by --controller or the
+// kompose.controller.type label, without the kompose.cronjob.schedule label: the CronJob would have no schedule
+func CheckCronJobSchedule(name string, service kobject.ServiceConfig, opt kobject.ConvertOptions) error {
+	controller := opt.Controller
+	if val, ok := service.Labels[compose.LabelControllerType]; ok {
+		controller = val
+	}
+	if controller == CronJobController && service.CronJobSchedule == "" {
+		return fmt.Errorf("service %s is converted to a cronjob without the %s label", name, compose.LabelCronJobSchedule)
+	}
+	return nil
+}
+
 // CreateWorkloadAndConfigMapObjects generates a Kubernetes artifact for each input type service
 func (k *Kubernetes) CreateWorkloadAndConfigMapObjects(name string, service kobject.ServiceConfig, opt kobject.ConvertOptions) []runtime.Object {
 	var objects []runtime.Object
@@ -1246,6 +1510,21 @@ func (k *Kubernetes) CreateWorkloadAndConfigMapObjects(name string, service kobj
 		objects = k.createConfigMapFromComposeConfig(name, service, objects)
 	}
 
+	// A scheduled service only runs as a CronJob, whatever controller was requested
+	if service.CronJobSchedule != "" {
+		if opt.Controller != "" || opt.CreateDS || opt.CreateRC {
+			log.Warnf("Service %s has label %s, it will be converted to cronjob", name, compose.LabelCronJobSchedule)
+		}
+		opt.CreateD = false
+		opt.CreateDS = false
+		opt.CreateRC = false
+		opt.Controller = CronJobController
+	}
+
+	if opt.Controller == CronJobController {
+		objects = append(objects, k.InitCJ(name, service))
+	}
+
 	if opt.CreateD || opt.Controller == DeploymentController {
 		objects = append(objects, k.InitD(name, service, replica))
 	}
@@ -1258,6 +1537,10 @@ func (k *Kubernetes) CreateWorkloadAndConfigMapObjects(name string, service kobj
 		objects = append(objects, k.InitSS(name, service, replica))
 	}
 
+	if opt.Controller == RolloutController {
+		objects = append(objects, k.InitRollout(name, service, replica))
+	}
+
 	if len(service.EnvFile) > 0 {
 		for _, envFile := range service.EnvFile {
 			configMap := k.InitConfigMapForEnv(name, opt, envFile)
@@ -1397,7 +1680,13 @@ func (k *Kubernetes) configKubeServiceAndIngressForService(service kobject.Servi
 			svc := k.CreateService(name, service)
 			*objects = append(*objects, svc)
 			if service.ExposeService != "" {
-				*objects = append(*objects, k.initIngress(name, service, svc.Spec.Ports[0].Port))
+				if k.exposeMode(service) == ExposeModeGateway {
+					for _, route := range k.initHTTPRoutes(name, service, svc.Spec.Ports[0].Port) {
+						*objects = append(*objects, route)
+					}
+				} else {
+					*objects = append(*objects, k.initIngress(name, service, svc.Spec.Ports[0].Port))
+				}
 			}
 			if service.ServiceExternalTrafficPolicy != "" && svc.Spec.Type != api.ServiceTypeNodePort {
 				log.Warningf("External Traffic Policy is ignored for the service %v of type %v", name, service.ServiceType)
@@ -1431,6 +1720,138 @@ func (k *Kubernetes) configNetworkPolicyForService(service kobject.ServiceConfig
 	return nil
 }
 
+// configHorizontalPodAutoscalerForService adds a HorizontalPodAutoscaler for the Deployment, StatefulSet or Rollout
+// of the service when kompose.hpa.* labels are set
+func (k *Kubernetes) configHorizontalPodAutoscalerForService(service kobject.ServiceConfig, name string, objects *[]runtime.Object) error {
+	values, err := GetHPAValues(service)
+	if err != nil {
+		return errors.Wrapf(err, "Unable to create HorizontalPodAutoscaler for service %v", name)
+	}
+	if values == nil {
+		return nil
+	}
+
+	for _, obj := range *objects {
+		var template *api.PodTemplateSpec
+		target := autoscalingv2.CrossVersionObjectReference{APIVersion: "apps/v1"}
+		switch t := obj.(type) {
+		case *appsv1.Deployment:
+			template = &t.Spec.Template
+			target.Kind = "Deployment"
+			target.Name = t.Name
+		case *appsv1.StatefulSet:
+			template = &t.Spec.Template
+			target.Kind = "StatefulSet"
+			target.Name = t.Name
+		case *Rollout:
+			template = &t.Spec.Template
+			target.APIVersion = "argoproj.io/v1alpha1"
+			target.Kind = "Rollout"
+			target.Name = t.Name
+		default:
+			continue
+		}
+
+		// Utilization targets are a percentage of the requests, without requests the metric never triggers scaling
+		for _, c := range template.Spec.Containers {
+			if _, ok := c.Resources.Requests[api.ResourceCPU]; !ok && values.CPU != 0 {
+				return errors.Errorf("service %s is autoscaled on cpu but container %s has no cpu reservation, the HorizontalPodAutoscaler can't scale", name, c.Name)
+			}
+			if _, ok := c.Resources.Requests[api.ResourceMemory]; !ok && values.Memory != 0 {
+				return errors.Errorf("service %s is autoscaled on memory but container %s has no memory reservation, the HorizontalPodAutoscaler can't scale", name, c.Name)
+			}
+		}
+
+		*objects = append(*objects, k.InitHPA(target.Name, service, target, *values))
+		return nil
+	}
+
+	log.Warnf("HorizontalPodAutoscaler for service %s is ignored, only Deployment, StatefulSet and Rollout can be autoscaled", name)
+	return nil
+}
+
+// configPodDisruptionBudgetForService adds a PodDisruptionBudget for the replicated Deployment, StatefulSet or Rollout
+// of the service when --generate-pod-disruption-budgets or the kompose.pdb.min-available label is set
+func (k *Kubernetes) configPodDisruptionBudgetForService(service kobject.ServiceConfig, name string, opt kobject.ConvertOptions, objects *[]runtime.Object) error {
+	minAvailable, hasLabel := service.Labels[compose.LabelPDBMinAvailable]
+	if !opt.GeneratePodDisruptionBudgets && !hasLabel {
+		return nil
+	}
+	if hasLabel {
+		if _, err := strconv.ParseUint(strings.TrimSuffix(minAvailable, "%"), 10, 32); err != nil {
+			return errors.Errorf("invalid value %q for label %s in service %s, must be a number or a percentage", minAvailable, compose.LabelPDBMinAvailable, name)
+		}
+	}
+
+	for _, obj := range *objects {
+		var replicas *int32
+		var selector *metav1.LabelSelector
+		switch t := obj.(type) {
+		case *appsv1.Deployment:
+			replicas, selector = t.Spec.Replicas, t.Spec.Selector
+		case *appsv1.StatefulSet:
+			replicas, selector = t.Spec.Replicas, t.Spec.Selector
+		case *Rollout:
+			replicas, selector = t.Spec.Replicas, t.Spec.Selector
+		default:
+			continue
+		}
+
+		// A budget on a single replica would block every node drain
+		if replicas == nil || *replicas <= 1 {
+			if hasLabel {
+				log.Warnf("PodDisruptionBudget for service %s is ignored, the service has a single replica", name)
+			}
+			return nil
+		}
+
+		*objects = append(*objects, k.InitPDB(name, service, selector.DeepCopy()))
+		return nil
+	}
+	return nil
+}
+
+// groupServiceConfig combines the labels, annotations and update config of the services of a group, the
+// autoscaling and disruption budget labels must agree between the services
+func groupServiceConfig(name string, group kobject.ServiceConfigGroup) (kobject.ServiceConfig, error) {
+	combined := kobject.ServiceConfig{Name: name, Labels: map[string]string{}, Annotations: map[string]string{}}
+	for _, service := range group {
+		if value, ok := service.Labels[compose.LabelPDBMinAvailable]; ok {
+			if existing, ok := combined.Labels[compose.LabelPDBMinAvailable]; ok && existing != value {
+				return combined, errors.Errorf("services of group %s set different values %q and %q for label %s", name, existing, value, compose.LabelPDBMinAvailable)
+			}
+		}
+		hpa := []struct {
+			label           string
+			combined, value *int32
+		}{
+			{compose.LabelHPAMinReplicas, &combined.HPAMinReplicas, &service.HPAMinReplicas},
+			{compose.LabelHPAMaxReplicas, &combined.HPAMaxReplicas, &service.HPAMaxReplicas},
+			{compose.LabelHPACPU, &combined.HPACPU, &service.HPACPU},
+			{compose.LabelHPAMemory, &combined.HPAMemory, &service.HPAMemory},
+		}
+		for _, h := range hpa {
+			if *h.value == 0 {
+				continue
+			}
+			if *h.combined != 0 && *h.combined != *h.value {
+				return combined, errors.Errorf("services of group %s set different values %d and %d for label %s", name, *h.combined, *h.value, h.label)
+			}
+			*h.combined = *h.value
+		}
+		for key, value := range service.Labels {
+			combined.Labels[key] = value
+		}
+		for key, value := range service.Annotations {
+			combined.Annotations[key] = value
+		}
+		if combined.DeployUpdateConfig.Parallelism == nil {
+			combined.DeployUpdateConfig.Parallelism = service.DeployUpdateConfig.Parallelism
+		}
+	}
+	return combined, nil
+}
+
 // Transform maps komposeObject to k8s objects
 // returns object that are already sorted in the way that Services are first
 func (k *Kubernetes) Transform(komposeObject kobject.KomposeObject, opt kobject.ConvertOptions) ([]runtime.Object, error) {
@@ -1455,7 +1876,13 @@ func (k *Kubernetes) Transform(komposeObject kobject.KomposeObject, opt kobject.
 	if opt.ServiceGroupMode != "" {
 		log.Debugf("Service group mode is: %s", opt.ServiceGroupMode)
 		komposeObjectToServiceConfigGroupMapping := KomposeObjectToServiceConfigGroupMapping(&komposeObject, opt)
-		for name, group := range komposeObjectToServiceConfigGroupMapping {
+		groupNames := make([]string, 0, len(komposeObjectToServiceConfigGroupMapping))
+		for name := range komposeObjectToServiceConfigGroupMapping {
+			groupNames = append(groupNames, name)
+		}
+		sort.Strings(groupNames)
+		for _, name := range groupNames {
+			group := komposeObjectToServiceConfigGroupMapping[name]
 			var objects []runtime.Object
 			podSpec := PodSpec{}
 
@@ -1477,6 +1904,9 @@ func (k *Kubernetes) Transform(komposeObject kobject.KomposeObject, opt kobject.
 			portsUses := map[string]bool{}
 
 			for _, service := range group {
+				if err := CheckCronJobSchedule(service.Name, service, opt); err != nil {
+					return nil, err
+				}
 				// first do ports check
 				ports := ConfigPorts(service)
 				for _, port := range ports {
@@ -1489,6 +1919,7 @@ func (k *Kubernetes) Transform(komposeObject kobject.KomposeObject, opt kobject.
 
 				log.Infof("Group Service %s to [%s]", service.Name, name)
 				service.WithKomposeAnnotation = opt.WithKomposeAnnotation
+				service.StableOutput = opt.StableOutput
 				podSpec.Append(AddContainer(service, opt))
 
 				if err := buildServiceImage(opt, service, service.Name); err != nil {
@@ -1552,6 +1983,22 @@ func (k *Kubernetes) Transform(komposeObject kobject.KomposeObject, opt kobject.
 						return nil, err
 					}
 				}
+
+			}
+
+			// The services of the group share one workload, so they get a single HorizontalPodAutoscaler
+			// and PodDisruptionBudget
+			groupService, err := groupServiceConfig(name, group)
+			if err != nil {
+				return nil, err
+			}
+			groupService.WithKomposeAnnotation = opt.WithKomposeAnnotation
+			groupService.StableOutput = opt.StableOutput
+			if err := k.configHorizontalPodAutoscalerForService(groupService, name, &objects); err != nil {
+				return nil, err
+			}
+			if err := k.configPodDisruptionBudgetForService(groupService, name, opt, &objects); err != nil {
+				return nil, err
 			}
 
 			allobjects = append(allobjects, objects...)
@@ -1565,17 +2012,21 @@ func (k *Kubernetes) Transform(komposeObject kobject.KomposeObject, opt kobject.
 		if service.InGroup {
 			continue
 		}
+		if err := CheckCronJobSchedule(name, service, opt); err != nil {
+			return nil, err
+		}
 
 		var objects []runtime.Object
 
 		service.WithKomposeAnnotation = opt.WithKomposeAnnotation
+		service.StableOutput = opt.StableOutput
 
 		if err := buildServiceImage(opt, service, name); err != nil {
 			return nil, err
 		}
 
 		// Generate pod only and nothing more
-		if (service.Restart == "no" || service.Restart == "on-failure") && !opt.IsPodController() {
+		if (service.Restart == "no" || service.Restart == "on-failure") && !opt.IsPodController() && service.CronJobSchedule == "" {
 			log.Infof("Create kubernetes pod instead of pod controller due to restart policy: %s", service.Restart)
 			pod := k.InitPod(name, service)
 			objects = append(objects, pod)
@@ -1595,17 +2046,77 @@ func (k *Kubernetes) Transform(komposeObject kobject.KomposeObject, opt kobject.
 				return nil, err
 			}
 		}
+		if err := k.configHorizontalPodAutoscalerForService(service, name, &objects); err != nil {
+			return nil, err
+		}
+		if err := k.configPodDisruptionBudgetForService(service, name, opt, &objects); err != nil {
+			return nil, err
+		}
 		allobjects = append(allobjects, objects...)
 	}
 
-	// sort all object so Services are first
-	k.SortServicesFirst(&allobjects)
+	// sort all objects in the order they have to be installed
+	k.SortObjectsByInstallOrder(&allobjects)
 	k.RemoveDupObjects(&allobjects)
 	transformer.AssignNamespaceToObjects(&allobjects, komposeObject.Namespace)
+	AssignCommonMetadata(allobjects, opt)
 	// k.FixWorkloadVersion(&allobjects)
 	return allobjects, nil
 }
 
+// AssignCommonMetadata adds the labels and annotations of --label and --annotation to the objects and to their
+// pod templates, the selectors are left unchanged
+func AssignCommonMetadata(objs []runtime.Object, opt kobject.ConvertOptions) {
+	if len(opt.Labels) == 0 && len(opt.Annotations) == 0 {
+		return
+	}
+	for _, obj := range objs {
+		if object, ok := obj.(metav1.Object); ok {
+			object.SetLabels(mergeMetadata(object.GetLabels(), opt.Labels))
+			object.SetAnnotations(mergeMetadata(object.GetAnnotations(), opt.Annotations))
+		}
+		if template := controllerPodTemplate(obj); template != nil {
+			template.Labels = mergeMetadata(template.Labels, opt.Labels)
+			template.Annotations = mergeMetadata(template.Annotations, opt.Annotations)
+		}
+	}
+}
+
+// mergeMetadata returns the labels or annotations with the common ones, which take precedence
+func mergeMetadata(values map[string]string, common map[string]string) map[string]string {
+	if len(common) == 0 {
+		return values
+	}
+	if values == nil {
+		values = map[string]string{}
+	}
+	for key, value := range common {
+		values[key] = value
+	}
+	return values
+}
+
+// controllerPodTemplate returns the pod template of a controller, or nil
+func controllerPodTemplate(obj runtime.Object) *api.PodTemplateSpec {
+	switch t := obj.(type) {
+	case *appsv1.Deployment:
+		return &t.Spec.Template
+	case *appsv1.DaemonSet:
+		return &t.Spec.Template
+	case *appsv1.StatefulSet:
+		return &t.Spec.Template
+	case *Rollout:
+		return &t.Spec.Template
+	case *batchv1.CronJob:
+		return &t.Spec.JobTemplate.Spec.Template
+	case *api.ReplicationController:
+		return t.Spec.Template
+	case *deployapi.DeploymentConfig:
+		return t.Spec.Template
+	}
+	return nil
+}
+
 // UpdateController updates the given object with the given pod template update function and ObjectMeta update function
 func (k *Kubernetes) UpdateController(obj runtime.Object, updateTemplate func(*api.PodTemplateSpec) error, updateMeta func(meta *metav1.ObjectMeta)) (err error) {
 	switch t := obj.(type) {
@@ -1627,6 +2138,22 @@ func (k *Kubernetes) UpdateController(obj runtime.Object, updateTemplate func(*a
 			return errors.Wrap(err, "updateTemplate failed")
 		}
 		updateMeta(&t.ObjectMeta)
+	case *Rollout:
+		err = updateTemplate(&t.Spec.Template)
+		if err != nil {
+			return errors.Wrap(err, "updateTemplate failed")
+		}
+		updateMeta(&t.ObjectMeta)
+	case *batchv1.CronJob:
+		err = updateTemplate(&t.Spec.JobTemplate.Spec.Template)
+		if err != nil {
+			return errors.Wrap(err, "updateTemplate failed")
+		}
+		// Pods of a Job can't restart always, fall back to the closest policy Jobs accept
+		if t.Spec.JobTemplate.Spec.Template.Spec.RestartPolicy == api.RestartPolicyAlways {
+			t.Spec.JobTemplate.Spec.Template.Spec.RestartPolicy = api.RestartPolicyOnFailure
+		}
+		updateMeta(&t.ObjectMeta)
 	case *deployapi.DeploymentConfig:
 		err = updateTemplate(t.Spec.Template)
 		if err != nil {
@@ -31,11 +31,15 @@ import (
 	deployapi "github.com/openshift/api/apps/v1"
 	"github.com/pkg/errors"
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	batchv1 "k8s.io/api/batch/v1"
 	api "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
 	networkingv1beta1 "k8s.io/api/networking/v1beta1"
+	policyv1 "k8s.io/api/policy/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
@@ -320,6 +324,110 @@ func TestKomposeConvertIngress(t *testing.T) {
 	}
 }
 
+func TestKomposeConvertHTTPRoute(t *testing.T) {
+	type route struct {
+		hostnames []interface{}
+		paths     []string
+	}
+	testCases := map[string]struct {
+		opt         kobject.ConvertOptions
+		labelValue  string
+		tlsSecret   string
+		gateway     string
+		namespace   string
+		sectionName string
+		routes      map[string]route
+	}{
+		"Flag with hostnames": {kobject.ConvertOptions{CreateD: true, ExposeMode: ExposeModeGateway, Gateway: "infra/public"}, "example.com,foo.example.com/api", "", "public", "infra", "", map[string]route{
+			"app-example-com":     {[]interface{}{"example.com"}, []string{"/"}},
+			"app-foo-example-com": {[]interface{}{"foo.example.com"}, []string{"/api"}},
+		}},
+		"Paths stay with their host": {kobject.ConvertOptions{CreateD: true, ExposeMode: ExposeModeGateway, Gateway: "public"}, "a.com/api,b.com/web,a.com/web", "", "public", "", "", map[string]route{
+			"app-a-com": {[]interface{}{"a.com"}, []string{"/api", "/web"}},
+			"app-b-com": {[]interface{}{"b.com"}, []string{"/web"}},
+		}},
+		"Single host keeps the service name": {kobject.ConvertOptions{CreateD: true, ExposeMode: ExposeModeGateway, Gateway: "public"}, "example.com,example.com/api", "", "public", "", "", map[string]route{
+			"app": {[]interface{}{"example.com"}, []string{"/", "/api"}},
+		}},
+		"Hosts with the same name are told apart": {kobject.ConvertOptions{CreateD: true, ExposeMode: ExposeModeGateway, Gateway: "public"}, "*.example.com,example.com,a.b,a-b", "", "public", "", "", map[string]route{
+			"app-example-com":          {[]interface{}{"*.example.com"}, []string{"/"}},
+			"app-example-com-431ceb26": {[]interface{}{"example.com"}, []string{"/"}},
+			"app-a-b":                  {[]interface{}{"a.b"}, []string{"/"}},
+			"app-a-b-2a89df63":         {[]interface{}{"a-b"}, []string{"/"}},
+		}},
+		"TLS secret is left to the Gateway": {kobject.ConvertOptions{CreateD: true, ExposeMode: ExposeModeGateway, Gateway: "public"}, "example.com", "example-secret", "public", "", "", map[string]route{
+			"app": {[]interface{}{"example.com"}, []string{"/"}},
+		}},
+		"Label set to true": {kobject.ConvertOptions{CreateD: true, ExposeMode: ExposeModeGateway, Gateway: "public"}, "true", "true", "public", "", "", map[string]route{
+			"app": {nil, []string{"/"}},
+		}},
+	}
+
+	for name, test := range testCases {
+		komposeObject := newKomposeObject()
+		config := komposeObject.ServiceConfigs["app"]
+		config.ExposeService = test.labelValue
+		config.ExposeServiceTLS = test.tlsSecret
+		komposeObject.ServiceConfigs["app"] = config
+
+		k := Kubernetes{Opt: test.opt}
+		objs, err := k.Transform(komposeObject, test.opt)
+		if err != nil {
+			t.Error(errors.Wrap(err, "k.Transform failed"))
+		}
+
+		routes := map[string]*unstructured.Unstructured{}
+		for _, obj := range objs {
+			if _, ok := obj.(*networkingv1.Ingress); ok {
+				t.Errorf("Case %v: expected no Ingress in gateway expose mode", name)
+			}
+			if us, ok := obj.(*unstructured.Unstructured); ok && us.GetKind() == "HTTPRoute" {
+				routes[us.GetName()] = us
+			}
+		}
+		if len(routes) != len(test.routes) {
+			t.Errorf("Case %v: expected %d HTTPRoutes, got %d", name, len(test.routes), len(routes))
+		}
+		for routeName, expected := range test.routes {
+			route, ok := routes[routeName]
+			if !ok {
+				t.Errorf("Case %v: expected the HTTPRoute %v to be generated", name, routeName)
+				continue
+			}
+			if route.GetAPIVersion() != "gateway.networking.k8s.io/v1" {
+				t.Errorf("Case %v: unexpected HTTPRoute %v/%v", name, route.GetAPIVersion(), route.GetName())
+			}
+			parentRefs, _, _ := unstructured.NestedSlice(route.Object, "spec", "parentRefs")
+			parentRef := parentRefs[0].(map[string]interface{})
+			if parentRef["name"] != test.gateway || (parentRef["namespace"] != nil && parentRef["namespace"] != test.namespace) || (test.namespace != "" && parentRef["namespace"] == nil) {
+				t.Errorf("Case %v: expected parent Gateway %v/%v, got %v", name, test.namespace, test.gateway, parentRef)
+			}
+			if sectionName, _ := parentRef["sectionName"].(string); sectionName != test.sectionName {
+				t.Errorf("Case %v: expected sectionName %q, got %q", name, test.sectionName, sectionName)
+			}
+			hostnames, _, _ := unstructured.NestedSlice(route.Object, "spec", "hostnames")
+			if !reflect.DeepEqual(hostnames, expected.hostnames) {
+				t.Errorf("Case %v: expected hostnames %v for %v, got %v", name, expected.hostnames, routeName, hostnames)
+			}
+			rules, _, _ := unstructured.NestedSlice(route.Object, "spec", "rules")
+			var paths []string
+			for _, r := range rules {
+				matches, _, _ := unstructured.NestedSlice(r.(map[string]interface{}), "matches")
+				path, _, _ := unstructured.NestedString(matches[0].(map[string]interface{}), "path", "value")
+				paths = append(paths, path)
+			}
+			if !reflect.DeepEqual(paths, expected.paths) {
+				t.Errorf("Case %v: expected paths %v for %v, got %v", name, expected.paths, routeName, paths)
+			}
+			backendRefs, _, _ := unstructured.NestedSlice(rules[0].(map[string]interface{}), "backendRefs")
+			backend := backendRefs[0].(map[string]interface{})
+			if backend["name"] != "app" || backend["port"] != int64(config.Port[0].HostPort) {
+				t.Errorf("Case %v: expected backend app:%v, got %v", name, config.Port[0].HostPort, backend)
+			}
+		}
+	}
+}
+
 func TestKomposeConvert(t *testing.T) {
 	replicas := 3
 	testCases := map[string]struct {
@@ -616,6 +724,280 @@ func TestRestartOnFailure(t *testing.T) {
 	}
 }
 
+func TestCronJobGeneration(t *testing.T) {
+	serviceConfig := newServiceConfig()
+	serviceConfig.Restart = "no"
+	serviceConfig.CronJobSchedule = "*/5 * * * *"
+	serviceConfig.CronJobConcurrencyPolicy = string(batchv1.ForbidConcurrent)
+	backoffLimit := int32(3)
+	serviceConfig.CronJobBackoffLimit = &backoffLimit
+	komposeObject := kobject.KomposeObject{
+		ServiceConfigs: map[string]kobject.ServiceConfig{"app": serviceConfig},
+	}
+	k := Kubernetes{}
+	objs, err := k.Transform(komposeObject, kobject.ConvertOptions{CreateD: true, Replicas: 1})
+	if err != nil {
+		t.Error(errors.Wrap(err, "k.Transform failed"))
+	}
+
+	found := false
+	for _, obj := range objs {
+		switch o := obj.(type) {
+		case *batchv1.CronJob:
+			found = true
+			if o.Spec.Schedule != serviceConfig.CronJobSchedule {
+				t.Errorf("Expected schedule %v, got %v", serviceConfig.CronJobSchedule, o.Spec.Schedule)
+			}
+			if o.Spec.ConcurrencyPolicy != batchv1.ForbidConcurrent {
+				t.Errorf("Expected concurrency policy %v, got %v", batchv1.ForbidConcurrent, o.Spec.ConcurrencyPolicy)
+			}
+			if *o.Spec.JobTemplate.Spec.BackoffLimit != backoffLimit {
+				t.Errorf("Expected backoff limit %v, got %v", backoffLimit, *o.Spec.JobTemplate.Spec.BackoffLimit)
+			}
+			template := o.Spec.JobTemplate.Spec.Template
+			if template.Spec.RestartPolicy != api.RestartPolicyNever {
+				t.Errorf("Expected restart policy %v, got %v", api.RestartPolicyNever, template.Spec.RestartPolicy)
+			}
+			if template.Spec.Containers[0].Image != serviceConfig.Image {
+				t.Errorf("Expected image %v, got %v", serviceConfig.Image, template.Spec.Containers[0].Image)
+			}
+			expectedLabels := transformer.ConfigLabelsWithNetwork("app", serviceConfig.Network)
+			if !equalStringMaps(expectedLabels, template.Labels) {
+				t.Errorf("Expected template labels %v, got %v", expectedLabels, template.Labels)
+			}
+		case *appsv1.Deployment, *api.Pod:
+			t.Errorf("Expected no %s for a scheduled service", obj.GetObjectKind().GroupVersionKind().Kind)
+		}
+	}
+	if !found {
+		t.Errorf("Expected a CronJob to be generated")
+	}
+}
+
+func TestCronJobWithoutSchedule(t *testing.T) {
+	testCases := map[string]struct {
+		labels map[string]string
+		opt    kobject.ConvertOptions
+	}{
+		"controller flag":  {nil, kobject.ConvertOptions{Controller: CronJobController, Replicas: 1}},
+		"controller label": {map[string]string{compose.LabelControllerType: CronJobController}, kobject.ConvertOptions{CreateD: true, Replicas: 1}},
+	}
+
+	for name, test := range testCases {
+		serviceConfig := newServiceConfig()
+		serviceConfig.Labels = test.labels
+		komposeObject := kobject.KomposeObject{
+			ServiceConfigs: map[string]kobject.ServiceConfig{"app": serviceConfig},
+		}
+		k := Kubernetes{}
+		if _, err := k.Transform(komposeObject, test.opt); err == nil {
+			t.Errorf("Case %v: expected an error for a cronjob without schedule", name)
+		}
+	}
+}
+
+func TestHorizontalPodAutoscalerGeneration(t *testing.T) {
+	serviceConfig := newServiceConfig()
+	serviceConfig.CPUReservation = 250
+	serviceConfig.HPAMinReplicas = 2
+	serviceConfig.HPAMaxReplicas = 5
+	serviceConfig.HPACPU = 60
+	komposeObject := kobject.KomposeObject{
+		ServiceConfigs: map[string]kobject.ServiceConfig{"app": serviceConfig},
+	}
+
+	testCases := map[string]struct {
+		opt  kobject.ConvertOptions
+		kind string
+	}{
+		"Deployment":  {kobject.ConvertOptions{CreateD: true, Replicas: 1}, "Deployment"},
+		"StatefulSet": {kobject.ConvertOptions{Controller: StatefulStateController, Replicas: 1}, "StatefulSet"},
+	}
+
+	for name, test := range testCases {
+		k := Kubernetes{}
+		objs, err := k.Transform(komposeObject, test.opt)
+		if err != nil {
+			t.Error(errors.Wrap(err, "k.Transform failed"))
+		}
+		found := false
+		for _, obj := range objs {
+			if hpa, ok := obj.(*autoscalingv2.HorizontalPodAutoscaler); ok {
+				found = true
+				if hpa.Spec.ScaleTargetRef.Kind != test.kind || hpa.Spec.ScaleTargetRef.Name != "app" {
+					t.Errorf("Case %v: expected target %v/app, got %v/%v", name, test.kind, hpa.Spec.ScaleTargetRef.Kind, hpa.Spec.ScaleTargetRef.Name)
+				}
+				if *hpa.Spec.MinReplicas != 2 || hpa.Spec.MaxReplicas != 5 {
+					t.Errorf("Case %v: expected replicas 2-5, got %v-%v", name, *hpa.Spec.MinReplicas, hpa.Spec.MaxReplicas)
+				}
+				if len(hpa.Spec.Metrics) != 1 || hpa.Spec.Metrics[0].Resource.Name != api.ResourceCPU || *hpa.Spec.Metrics[0].Resource.Target.AverageUtilization != 60 {
+					t.Errorf("Case %v: expected a single cpu metric targeting 60%%, got %#v", name, hpa.Spec.Metrics)
+				}
+			}
+		}
+		if !found {
+			t.Errorf("Case %v: expected a HorizontalPodAutoscaler to be generated", name)
+		}
+	}
+}
+
+func TestHorizontalPodAutoscalerWithoutRequests(t *testing.T) {
+	testCases := map[string]func(*kobject.ServiceConfig){
+		"cpu":    func(config *kobject.ServiceConfig) { config.HPACPU = 60 },
+		"memory": func(config *kobject.ServiceConfig) { config.HPAMemory, config.CPUReservation = 70, 250 },
+	}
+
+	for name, set := range testCases {
+		serviceConfig := newServiceConfig()
+		serviceConfig.HPAMaxReplicas = 5
+		set(&serviceConfig)
+		komposeObject := kobject.KomposeObject{
+			ServiceConfigs: map[string]kobject.ServiceConfig{"app": serviceConfig},
+		}
+		k := Kubernetes{}
+		if _, err := k.Transform(komposeObject, kobject.ConvertOptions{CreateD: true, Replicas: 1}); err == nil {
+			t.Errorf("Case %v: expected an error for an autoscaled service without %v reservation", name, name)
+		}
+	}
+}
+
+func TestPodDisruptionBudgetGeneration(t *testing.T) {
+	parallelism := uint64(2)
+
+	testCases := map[string]struct {
+		opt            kobject.ConvertOptions
+		replicas       int
+		labels         map[string]string
+		expectPDB      bool
+		minAvailable   string
+		maxUnavailable string
+	}{
+		"Flag uses update parallelism": {kobject.ConvertOptions{CreateD: true, GeneratePodDisruptionBudgets: true}, 3, nil, true, "", "2"},
+		"Label sets min available":     {kobject.ConvertOptions{CreateD: true}, 3, map[string]string{compose.LabelPDBMinAvailable: "50%"}, true, "50%", ""},
+		"Single replica is skipped":    {kobject.ConvertOptions{CreateD: true, GeneratePodDisruptionBudgets: true}, 1, nil, false, "", ""},
+		"Disabled by default":          {kobject.ConvertOptions{CreateD: true}, 3, nil, false, "", ""},
+	}
+
+	for name, test := range testCases {
+		serviceConfig := newServiceConfig()
+		serviceConfig.Replicas = test.replicas
+		serviceConfig.DeployUpdateConfig.Parallelism = &parallelism
+		serviceConfig.Labels = test.labels
+		komposeObject := kobject.KomposeObject{
+			ServiceConfigs: map[string]kobject.ServiceConfig{"app": serviceConfig},
+		}
+
+		k := Kubernetes{}
+		objs, err := k.Transform(komposeObject, test.opt)
+		if err != nil {
+			t.Error(errors.Wrap(err, "k.Transform failed"))
+		}
+		var pdb *policyv1.PodDisruptionBudget
+		for _, obj := range objs {
+			if p, ok := obj.(*policyv1.PodDisruptionBudget); ok {
+				pdb = p
+			}
+		}
+		if (pdb != nil) != test.expectPDB {
+			t.Errorf("Case %v: expected PodDisruptionBudget generated to be %v", name, test.expectPDB)
+			continue
+		}
+		if pdb == nil {
+			continue
+		}
+		if pdb.Spec.Selector.MatchLabels[transformer.Selector] != "app" {
+			t.Errorf("Case %v: expected selector on %v=app, got %v", name, transformer.Selector, pdb.Spec.Selector.MatchLabels)
+		}
+		if test.minAvailable != "" && (pdb.Spec.MinAvailable == nil || pdb.Spec.MinAvailable.String() != test.minAvailable) {
+			t.Errorf("Case %v: expected minAvailable %v, got %v", name, test.minAvailable, pdb.Spec.MinAvailable)
+		}
+		if test.maxUnavailable != "" && (pdb.Spec.MaxUnavailable == nil || pdb.Spec.MaxUnavailable.String() != test.maxUnavailable) {
+			t.Errorf("Case %v: expected maxUnavailable %v, got %v", name, test.maxUnavailable, pdb.Spec.MaxUnavailable)
+		}
+	}
+}
+
+func TestHPAAndPDBOnMultipleContainers(t *testing.T) {
+	groupName := "pod_group"
+
+	createConfigs := func(set1, set2 func(*kobject.ServiceConfig)) map[string]kobject.ServiceConfig {
+		createConfig := func(name string, set func(*kobject.ServiceConfig)) kobject.ServiceConfig {
+			config := newSimpleServiceConfig()
+			config.Labels = map[string]string{compose.LabelServiceGroup: groupName}
+			config.Name = name
+			config.ContainerName = ""
+			config.Replicas = 3
+			config.CPUReservation = 100
+			config.MemReservation = 64 << 20
+			set(&config)
+			return config
+		}
+		return map[string]kobject.ServiceConfig{"app1": createConfig("app1", set1), "app2": createConfig("app2", set2)}
+	}
+
+	testCases := map[string]struct {
+		komposeObject kobject.KomposeObject
+		expectError   bool
+		numMetrics    int
+	}{
+		"Labels of the services are combined": {
+			kobject.KomposeObject{
+				ServiceConfigs: createConfigs(
+					func(config *kobject.ServiceConfig) { config.HPAMaxReplicas, config.HPACPU = 5, 60 },
+					func(config *kobject.ServiceConfig) {
+						config.HPAMaxReplicas, config.HPAMemory = 5, 70
+						config.Labels[compose.LabelPDBMinAvailable] = "1"
+					},
+				),
+			}, false, 2},
+		"Conflicting labels are rejected": {
+			kobject.KomposeObject{
+				ServiceConfigs: createConfigs(
+					func(config *kobject.ServiceConfig) { config.HPAMaxReplicas = 5 },
+					func(config *kobject.ServiceConfig) { config.HPAMaxReplicas = 10 },
+				),
+			}, true, 0},
+	}
+
+	for name, test := range testCases {
+		k := Kubernetes{}
+		objs, err := k.Transform(test.komposeObject, kobject.ConvertOptions{ServiceGroupMode: "label", CreateD: true})
+		if test.expectError {
+			if err == nil {
+				t.Errorf("Case %v: expected an error", name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Error(errors.Wrap(err, "k.Transform failed"))
+		}
+
+		var hpas []*autoscalingv2.HorizontalPodAutoscaler
+		var pdbs []*policyv1.PodDisruptionBudget
+		for _, obj := range objs {
+			switch o := obj.(type) {
+			case *autoscalingv2.HorizontalPodAutoscaler:
+				hpas = append(hpas, o)
+			case *policyv1.PodDisruptionBudget:
+				pdbs = append(pdbs, o)
+			}
+		}
+		if len(hpas) != 1 || len(pdbs) != 1 {
+			t.Errorf("Case %v: expected a single HorizontalPodAutoscaler and PodDisruptionBudget, got %d and %d", name, len(hpas), len(pdbs))
+			continue
+		}
+		if hpas[0].Name != groupName || hpas[0].Spec.ScaleTargetRef.Name != groupName {
+			t.Errorf("Case %v: expected the HorizontalPodAutoscaler %v to target %v, got %v targeting %v", name, groupName, groupName, hpas[0].Name, hpas[0].Spec.ScaleTargetRef.Name)
+		}
+		if len(hpas[0].Spec.Metrics) != test.numMetrics {
+			t.Errorf("Case %v: expected %d metrics, got %#v", name, test.numMetrics, hpas[0].Spec.Metrics)
+		}
+		if pdbs[0].Name != groupName || pdbs[0].Spec.MinAvailable == nil || pdbs[0].Spec.MinAvailable.String() != "1" {
+			t.Errorf("Case %v: expected the PodDisruptionBudget %v with minAvailable 1, got %v with %v", name, groupName, pdbs[0].Name, pdbs[0].Spec.MinAvailable)
+		}
+	}
+}
+
 func TestInitPodSpec(t *testing.T) {
 	name := "foo"
 	k := Kubernetes{}
@@ -1119,3 +1501,38 @@ func TestNamespaceGeneration(t *testing.T) {
 		}
 	}
 }
+
+func TestAssignCommonMetadata(t *testing.T) {
+	opt := kobject.ConvertOptions{Labels: map[string]string{"team": "shop"}, Annotations: map[string]string{"owner": "shop@example.com"}}
+	selector := map[string]string{transformer.Selector: "web"}
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Labels: map[string]string{transformer.Selector: "web", "team": "compose"}},
+		Spec: appsv1.DeploymentSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{transformer.Selector: "web"}},
+			Template: api.PodTemplateSpec{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{transformer.Selector: "web"}}},
+		},
+	}
+	cronJob := &batchv1.CronJob{ObjectMeta: metav1.ObjectMeta{Name: "backup"}}
+	service := &api.Service{ObjectMeta: metav1.ObjectMeta{Name: "web"}, Spec: api.ServiceSpec{Selector: map[string]string{transformer.Selector: "web"}}}
+
+	AssignCommonMetadata([]runtime.Object{deployment, cronJob, service}, opt)
+
+	metadata := map[string]metav1.ObjectMeta{
+		"deployment":                 deployment.ObjectMeta,
+		"pod template of deployment": deployment.Spec.Template.ObjectMeta,
+		"cronjob":                    cronJob.ObjectMeta,
+		"pod template of cronjob":    cronJob.Spec.JobTemplate.Spec.Template.ObjectMeta,
+		"service":                    service.ObjectMeta,
+	}
+	for name, meta := range metadata {
+		if meta.Labels["team"] != "shop" || meta.Annotations["owner"] != "shop@example.com" {
+			t.Errorf("Case '%v' for TestAssignCommonMetadata fail, Expected the common labels and annotations, got %v and %v", name, meta.Labels, meta.Annotations)
+		}
+	}
+	if deployment.Labels[transformer.Selector] != "web" {
+		t.Errorf("Expected the labels of the deployment to be kept, got %v", deployment.Labels)
+	}
+	if !reflect.DeepEqual(deployment.Spec.Selector.MatchLabels, selector) || !reflect.DeepEqual(service.Spec.Selector, selector) {
+		t.Errorf("Expected the selectors to be left unchanged, got %v and %v", deployment.Spec.Selector.MatchLabels, service.Spec.Selector)
+	}
+}
@@ -0,0 +1,290 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/kubernetes/kompose/pkg/kobject"
+	"github.com/kubernetes/kompose/pkg/transformer"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// OutputFormatKustomize writes a kustomize base and one overlay per override compose file
+const OutputFormatKustomize = "kustomize"
+
+// Kustomization is the kustomization.yaml written in the base and overlay directories
+type Kustomization struct {
+	APIVersion string           `yaml:"apiVersion"`
+	Kind       string           `yaml:"kind"`
+	Resources  []string         `yaml:"resources,omitempty"`
+	Patches    []KustomizePatch `yaml:"patches,omitempty"`
+}
+
+// KustomizePatch references a strategic merge patch file of an overlay
+type KustomizePatch struct {
+	Path string `yaml:"path"`
+}
+
+// KustomizeOverlay holds the objects converted from the base compose file merged with an override file
+type KustomizeOverlay struct {
+	Name    string
+	Objects []runtime.Object
+}
+
+// KustomizeOverlayName returns the overlay directory name of an override compose file,
+// e.g. "prod" for "compose.prod.yaml" or "override" for "docker-compose.override.yml"
+func KustomizeOverlayName(file string) string {
+	name := filepath.Base(file)
+	name = strings.TrimSuffix(name, filepath.Ext(name))
+	for _, prefix := range []string{"docker-compose.", "compose."} {
+		if strings.HasPrefix(name, prefix) && len(name) > len(prefix) {
+			return strings.TrimPrefix(name, prefix)
+		}
+	}
+	return name
+}
+
+// PrintKustomize writes the base objects to base/ and every overlay to overlays/<name>/, with
+// only the objects added by the overlay and strategic merge patches for the ones that differ from the base
+func PrintKustomize(base []runtime.Object, overlays []KustomizeOverlay, opt kobject.ConvertOptions) error {
+	dirName := getDirName(opt)
+
+	baseDir := filepath.Join(dirName, "base")
+	baseFiles, err := printKustomizeObjects(base, baseDir, opt)
+	if err != nil {
+		return err
+	}
+	if err := writeKustomization(baseDir, Kustomization{Resources: baseFiles}, opt.YAMLIndent, diskWriter{}); err != nil {
+		return err
+	}
+
+	baseObjects := map[string]map[string]interface{}{}
+	var baseKeys []string
+	for _, obj := range base {
+		m, err := toUnstructuredMap(obj)
+		if err != nil {
+			return err
+		}
+		key := kustomizeObjectKey(m)
+		baseObjects[key] = m
+		baseKeys = append(baseKeys, key)
+	}
+
+	seen := map[string]bool{}
+	for _, overlay := range overlays {
+		if seen[overlay.Name] {
+			return errors.Errorf("more than one override file results in overlay %q", overlay.Name)
+		}
+		seen[overlay.Name] = true
+
+		overlayDir := filepath.Join(dirName, "overlays", overlay.Name)
+		kustomization := Kustomization{Resources: []string{"../../base"}}
+
+		var added []runtime.Object
+		var patches []map[string]interface{}
+		inOverlay := map[string]bool{}
+		for _, obj := range overlay.Objects {
+			m, err := toUnstructuredMap(obj)
+			if err != nil {
+				return err
+			}
+			key := kustomizeObjectKey(m)
+			inOverlay[key] = true
+
+			baseObject, ok := baseObjects[key]
+			if !ok {
+				added = append(added, obj)
+				continue
+			}
+			if patch := diffUnstructuredMaps(baseObject, m); len(patch) > 0 {
+				patches = append(patches, newKustomizePatch(m, patch))
+			}
+		}
+		// Objects that only exist in the base are deleted by the overlay
+		for _, key := range baseKeys {
+			if !inOverlay[key] {
+				patches = append(patches, newKustomizePatch(baseObjects[key], map[string]interface{}{"$patch": "delete"}))
+			}
+		}
+
+		addedFiles, err := printKustomizeObjects(added, overlayDir, opt)
+		if err != nil {
+			return err
+		}
+		kustomization.Resources = append(kustomization.Resources, addedFiles...)
+
+		for _, patch := range patches {
+			data, err := marshalWithIndent(patch, opt.YAMLIndent)
+			if err != nil {
+				return err
+			}
+			metadata := patch["metadata"].(map[string]interface{})
+			file, err := transformer.Print(metadata["name"].(string), overlayDir, strings.ToLower(patch["kind"].(string))+"-patch", data, false, false, nil, opt.Provider)
+			if err != nil {
+				return errors.Wrap(err, "transformer.Print failed")
+			}
+			kustomization.Patches = append(kustomization.Patches, KustomizePatch{Path: filepath.Base(file)})
+		}
+
+		if err := writeKustomization(overlayDir, kustomization, opt.YAMLIndent, diskWriter{}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// printKustomizeObjects writes one file per object in dir and returns the file names relative to dir
+func printKustomizeObjects(objects []runtime.Object, dir string, opt kobject.ConvertOptions) ([]string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, v := range objects {
+		data, err := marshal(v, false, opt.YAMLIndent)
+		if err != nil {
+			return nil, err
+		}
+		typeMeta, objectMeta := getTypeAndObjectMeta(v)
+		file, err := transformer.Print(objectMeta.Name, dir, strings.ToLower(typeMeta.Kind), data, false, false, nil, opt.Provider)
+		if err != nil {
+			return nil, errors.Wrap(err, "transformer.Print failed")
+		}
+		files = append(files, filepath.Base(file))
+	}
+	return files, nil
+}
+
+func writeKustomization(dir string, kustomization Kustomization, indent int, out outputWriter) error {
+	kustomization.APIVersion = "kustomize.config.k8s.io/v1beta1"
+	kustomization.Kind = "Kustomization"
+
+	if err := out.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	var data bytes.Buffer
+	encoder := yaml.NewEncoder(&data)
+	encoder.SetIndent(indent)
+	if err := encoder.Encode(kustomization); err != nil {
+		return errors.Wrap(err, "failed to marshal kustomization.yaml")
+	}
+	file := filepath.Join(dir, "kustomization.yaml")
+	if err := out.WriteFile(file, data.Bytes(), 0644); err != nil {
+		return errors.Wrapf(err, "failed to write %s", file)
+	}
+	if _, ok := out.(diskWriter); ok {
+		log.Printf("Kustomization file %q created", file)
+	}
+	return nil
+}
+
+// newKustomizePatch adds the fields kustomize needs to find the patched object
+func newKustomizePatch(object map[string]interface{}, patch map[string]interface{}) map[string]interface{} {
+	patch["apiVersion"] = object["apiVersion"]
+	patch["kind"] = object["kind"]
+
+	objectMetadata, _ := object["metadata"].(map[string]interface{})
+	metadata, ok := patch["metadata"].(map[string]interface{})
+	if !ok {
+		metadata = map[string]interface{}{}
+	}
+	metadata["name"] = objectMetadata["name"]
+	if namespace, ok := objectMetadata["namespace"]; ok {
+		metadata["namespace"] = namespace
+	}
+	patch["metadata"] = metadata
+	return patch
+}
+
+// diffUnstructuredMaps returns a merge patch turning base into overlay. Lists are not compared
+// item by item, a list that differs from the base is written whole. Kustomize merges the items of
+// the lists of objects by key (containers, env, ports...), so these lists replace the ones of the base.
+func diffUnstructuredMaps(base, overlay map[string]interface{}) map[string]interface{} {
+	patch := map[string]interface{}{}
+	for key, value := range overlay {
+		baseValue, ok := base[key]
+		if !ok {
+			patch[key] = value
+			continue
+		}
+		baseMap, baseIsMap := baseValue.(map[string]interface{})
+		overlayMap, overlayIsMap := value.(map[string]interface{})
+		if baseIsMap && overlayIsMap {
+			if sub := diffUnstructuredMaps(baseMap, overlayMap); len(sub) > 0 {
+				patch[key] = sub
+			}
+			continue
+		}
+		if !reflect.DeepEqual(baseValue, value) {
+			patch[key] = replaceList(baseValue, value)
+		}
+	}
+	for key := range base {
+		if _, ok := overlay[key]; !ok {
+			patch[key] = nil
+		}
+	}
+	return patch
+}
+
+// replaceList adds the $patch: replace directive to a list of objects, otherwise the items of the base
+// list left out of the overlay would be kept by the strategic merge
+func replaceList(base, overlay interface{}) interface{} {
+	list, ok := overlay.([]interface{})
+	if !ok {
+		return overlay
+	}
+	baseList, _ := base.([]interface{})
+	objects := false
+	for _, item := range append(append([]interface{}{}, baseList...), list...) {
+		if _, ok := item.(map[string]interface{}); ok {
+			objects = true
+			break
+		}
+	}
+	if !objects {
+		return overlay
+	}
+	return append(append([]interface{}{}, list...), map[string]interface{}{"$patch": "replace"})
+}
+
+func kustomizeObjectKey(object map[string]interface{}) string {
+	metadata, _ := object["metadata"].(map[string]interface{})
+	return fmt.Sprintf("%v/%v/%v/%v", object["apiVersion"], object["kind"], metadata["namespace"], metadata["name"])
+}
+
+func toUnstructuredMap(obj runtime.Object) (map[string]interface{}, error) {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, errors.Wrap(err, "json.Marshal failed")
+	}
+	m := map[string]interface{}{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, errors.Wrap(err, "json.Unmarshal failed")
+	}
+	return m, nil
+}
@@ -0,0 +1,177 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/kubernetes/kompose/pkg/kobject"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+)
+
+func TestKustomizeOverlayName(t *testing.T) {
+	testCases := map[string]string{
+		"compose.prod.yaml":                    "prod",
+		"docker-compose.override.yml":          "override",
+		"/tmp/staging.yaml":                    "staging",
+		"compose.yaml":                         "compose",
+		"deploy/docker-compose.eu-west-1.yaml": "eu-west-1",
+	}
+
+	for file, want := range testCases {
+		if got := KustomizeOverlayName(file); got != want {
+			t.Errorf("Expected overlay name %q for %q, got %q", want, file, got)
+		}
+	}
+}
+
+func TestDiffUnstructuredMaps(t *testing.T) {
+	base := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"replicas": 1.0,
+			"strategy": map[string]interface{}{"type": "Recreate"},
+			"paused":   true,
+		},
+		"metadata": map[string]interface{}{"name": "web"},
+	}
+	overlay := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"replicas": 3.0,
+			"strategy": map[string]interface{}{"type": "Recreate"},
+		},
+		"metadata": map[string]interface{}{"name": "web"},
+	}
+	expected := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"replicas": 3.0,
+			"paused":   nil,
+		},
+	}
+
+	if patch := diffUnstructuredMaps(base, overlay); !reflect.DeepEqual(patch, expected) {
+		t.Errorf("Expected patch %v, got %v", expected, patch)
+	}
+	if patch := diffUnstructuredMaps(base, base); len(patch) != 0 {
+		t.Errorf("Expected no patch for identical objects, got %v", patch)
+	}
+}
+
+func TestDiffUnstructuredMapsReplacesLists(t *testing.T) {
+	deployment := func(env ...string) map[string]interface{} {
+		var vars []interface{}
+		for _, name := range env {
+			vars = append(vars, map[string]interface{}{"name": name, "value": "1"})
+		}
+		return map[string]interface{}{
+			"spec": map[string]interface{}{
+				"template": map[string]interface{}{
+					"spec": map[string]interface{}{
+						"containers": []interface{}{
+							map[string]interface{}{"name": "web", "image": "nginx", "env": vars, "args": []interface{}{"a", "b"}},
+						},
+					},
+				},
+			},
+		}
+	}
+	base, overlay := deployment("A", "DEBUG"), deployment("A")
+	overlay["spec"].(map[string]interface{})["template"].(map[string]interface{})["spec"].(map[string]interface{})["containers"].([]interface{})[0].(map[string]interface{})["args"] = []interface{}{"a"}
+
+	// Apply the patch the way kustomize does, the env var removed by the overlay must be gone
+	patched, err := strategicpatch.StrategicMergeMapPatch(base, diffUnstructuredMaps(base, overlay), appsv1.Deployment{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, _ := json.Marshal(overlay)
+	if data, _ := json.Marshal(patched); string(data) != string(expected) {
+		t.Errorf("Expected the patched base to be the overlay %s, got %s", expected, data)
+	}
+}
+
+func TestPrintKustomize(t *testing.T) {
+	k := Kubernetes{}
+	opt := kobject.ConvertOptions{CreateD: true, Replicas: 1, YAMLIndent: 2, OutFile: t.TempDir()}
+
+	transform := func(replicas int, services ...string) []runtime.Object {
+		komposeObject := kobject.KomposeObject{ServiceConfigs: map[string]kobject.ServiceConfig{}}
+		for _, name := range services {
+			serviceConfig := newServiceConfig()
+			serviceConfig.Name = name
+			serviceConfig.Replicas = replicas
+			komposeObject.ServiceConfigs[name] = serviceConfig
+		}
+		objects, err := k.Transform(komposeObject, opt)
+		if err != nil {
+			t.Fatal(errors.Wrap(err, "k.Transform failed"))
+		}
+		return objects
+	}
+
+	base := transform(1, "app", "db")
+	overlays := []KustomizeOverlay{{Name: "prod", Objects: transform(3, "app", "cache")}}
+	if err := PrintKustomize(base, overlays, opt); err != nil {
+		t.Fatal(errors.Wrap(err, "PrintKustomize failed"))
+	}
+
+	var kustomization Kustomization
+	data, err := os.ReadFile(filepath.Join(opt.OutFile, "overlays", "prod", "kustomization.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := yaml.Unmarshal(data, &kustomization); err != nil {
+		t.Fatal(err)
+	}
+
+	expectedResources := []string{"../../base", "cache-service.yaml", "cache-deployment.yaml"}
+	if !reflect.DeepEqual(kustomization.Resources, expectedResources) {
+		t.Errorf("Expected overlay resources %v, got %v", expectedResources, kustomization.Resources)
+	}
+	expectedPatches := []KustomizePatch{
+		{Path: "app-deployment-patch.yaml"},
+		{Path: "db-service-patch.yaml"},
+		{Path: "db-deployment-patch.yaml"},
+	}
+	if !reflect.DeepEqual(kustomization.Patches, expectedPatches) {
+		t.Errorf("Expected overlay patches %v, got %v", expectedPatches, kustomization.Patches)
+	}
+
+	patch := map[string]interface{}{}
+	data, err = os.ReadFile(filepath.Join(opt.OutFile, "overlays", "prod", "db-deployment-patch.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := yaml.Unmarshal(data, &patch); err != nil {
+		t.Fatal(err)
+	}
+	if patch["$patch"] != "delete" || patch["kind"] != "Deployment" {
+		t.Errorf("Expected a delete patch for the db deployment, got %v", patch)
+	}
+
+	for _, file := range []string{"kustomization.yaml", "app-service.yaml", "app-deployment.yaml", "db-service.yaml", "db-deployment.yaml"} {
+		if _, err := os.Stat(filepath.Join(opt.OutFile, "base", file)); err != nil {
+			t.Errorf("Expected %s to be written in base: %v", file, err)
+		}
+	}
+}
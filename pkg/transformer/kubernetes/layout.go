@@ -0,0 +1,262 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/kubernetes/kompose/pkg/kobject"
+	"github.com/kubernetes/kompose/pkg/transformer"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	// LayoutFlat writes the files of all the objects in the output directory
+	LayoutFlat = "flat"
+	// LayoutPerService writes the objects of each service in <out>/<service>/, and the objects
+	// shared by several services in <out>/_shared/, each directory with its kustomization.yaml
+	LayoutPerService = "per-service"
+
+	// SharedDirectory holds the objects of the per-service layout not used by a single service
+	SharedDirectory = "_shared"
+)
+
+// podSpecPaths are the paths of the pod spec in the workloads, by kind
+var podSpecPaths = map[string][]string{
+	"Pod":     {"spec"},
+	"CronJob": {"spec", "jobTemplate", "spec", "template", "spec"},
+	"Job":     {"spec", "template", "spec"},
+}
+
+// nestedMap returns the map at path in m, or nil
+func nestedMap(m map[string]interface{}, path ...string) map[string]interface{} {
+	for _, key := range path {
+		next, ok := m[key].(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		m = next
+	}
+	return m
+}
+
+// nestedString returns the string at path in m, or an empty string
+func nestedString(m map[string]interface{}, path ...string) string {
+	parent := nestedMap(m, path[:len(path)-1]...)
+	if parent == nil {
+		return ""
+	}
+	s, _ := parent[path[len(path)-1]].(string)
+	return s
+}
+
+// nestedMaps returns the maps of the list at path in m
+func nestedMaps(m map[string]interface{}, path ...string) []map[string]interface{} {
+	parent := nestedMap(m, path[:len(path)-1]...)
+	if parent == nil {
+		return nil
+	}
+	list, _ := parent[path[len(path)-1]].([]interface{})
+	var maps []map[string]interface{}
+	for _, item := range list {
+		if item, ok := item.(map[string]interface{}); ok {
+			maps = append(maps, item)
+		}
+	}
+	return maps
+}
+
+// podTemplate returns the pod spec and the pod labels of a workload, or nil if object has no pods
+func podTemplate(object map[string]interface{}) (map[string]interface{}, map[string]interface{}) {
+	kind, _ := object["kind"].(string)
+	if path, ok := podSpecPaths[kind]; ok {
+		parent := path[:len(path)-1]
+		return nestedMap(object, path...), nestedMap(object, append(parent, "metadata", "labels")...)
+	}
+	return nestedMap(object, "spec", "template", "spec"), nestedMap(object, "spec", "template", "metadata", "labels")
+}
+
+// podReferences returns the objects referenced by a pod spec, as kind/name keys
+func podReferences(podSpec map[string]interface{}) []string {
+	var references []string
+	add := func(kind, name string) {
+		if name != "" {
+			references = append(references, kind+"/"+name)
+		}
+	}
+
+	add("ServiceAccount", nestedString(podSpec, "serviceAccountName"))
+	for _, secret := range nestedMaps(podSpec, "imagePullSecrets") {
+		add("Secret", nestedString(secret, "name"))
+	}
+	for _, volume := range nestedMaps(podSpec, "volumes") {
+		add("PersistentVolumeClaim", nestedString(volume, "persistentVolumeClaim", "claimName"))
+		add("ConfigMap", nestedString(volume, "configMap", "name"))
+		add("Secret", nestedString(volume, "secret", "secretName"))
+	}
+	containers := append(nestedMaps(podSpec, "initContainers"), nestedMaps(podSpec, "containers")...)
+	for _, container := range containers {
+		for _, envFrom := range nestedMaps(container, "envFrom") {
+			add("ConfigMap", nestedString(envFrom, "configMapRef", "name"))
+			add("Secret", nestedString(envFrom, "secretRef", "name"))
+		}
+		for _, env := range nestedMaps(container, "env") {
+			add("ConfigMap", nestedString(env, "valueFrom", "configMapKeyRef", "name"))
+			add("Secret", nestedString(env, "valueFrom", "secretKeyRef", "name"))
+		}
+	}
+	return references
+}
+
+// selects returns true if all the labels of selector are pod labels
+func selects(selector, labels map[string]interface{}) bool {
+	if len(selector) == 0 {
+		return false
+	}
+	for key, value := range selector {
+		if labels[key] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// serviceDirectories returns the directory of each object in the per-service layout. The workloads
+// are in the directory of their service, with the objects of the same name (Service, Ingress,
+// HorizontalPodAutoscaler...). The objects referenced or selected by the pods of a single service
+// are in its directory too, the other objects are in SharedDirectory.
+func serviceDirectories(objects []runtime.Object) ([]string, error) {
+	maps := make([]map[string]interface{}, len(objects))
+	keys := make([]string, len(objects))
+	for i, obj := range objects {
+		m, err := toUnstructuredMap(obj)
+		if err != nil {
+			return nil, err
+		}
+		maps[i] = m
+		keys[i] = nestedString(m, "kind") + "/" + nestedString(m, "metadata", "name")
+	}
+
+	services := map[string]bool{}
+	podLabels := map[string]map[string]interface{}{}
+	users := map[string]map[string]bool{}
+	for _, m := range maps {
+		podSpec, labels := podTemplate(m)
+		if podSpec == nil {
+			continue
+		}
+		service := nestedString(m, "metadata", "name")
+		services[service] = true
+		podLabels[service] = labels
+		for _, reference := range podReferences(podSpec) {
+			if users[reference] == nil {
+				users[reference] = map[string]bool{}
+			}
+			users[reference][service] = true
+		}
+	}
+
+	directories := make([]string, len(objects))
+	for i, m := range maps {
+		name := nestedString(m, "metadata", "name")
+		kind := nestedString(m, "kind")
+		if services[name] && kind != "PersistentVolumeClaim" && kind != "ConfigMap" && kind != "Secret" && kind != "ServiceAccount" {
+			directories[i] = name
+			continue
+		}
+
+		objectUsers := users[keys[i]]
+		if kind == "NetworkPolicy" {
+			objectUsers = map[string]bool{}
+			for service, labels := range podLabels {
+				if selects(nestedMap(m, "spec", "podSelector", "matchLabels"), labels) {
+					objectUsers[service] = true
+				}
+			}
+		}
+		directories[i] = SharedDirectory
+		if len(objectUsers) == 1 {
+			for service := range objectUsers {
+				directories[i] = service
+			}
+		}
+	}
+	return directories, nil
+}
+
+// printPerService writes the objects in the per-service layout under dirName, with a kustomization.yaml
+// in every directory and one in dirName referencing all of them
+func printPerService(objects []runtime.Object, dirName string, opt kobject.ConvertOptions, out outputWriter) error {
+	directories, err := serviceDirectories(objects)
+	if err != nil {
+		return err
+	}
+
+	objectsByDirectory := map[string][]runtime.Object{}
+	for i, obj := range objects {
+		objectsByDirectory[directories[i]] = append(objectsByDirectory[directories[i]], obj)
+	}
+	var names []string
+	for name := range objectsByDirectory {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		dir := filepath.Join(dirName, name)
+		if err := out.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+
+		var kustomization Kustomization
+		for i, obj := range objectsByDirectory[name] {
+			versionedObject, err := convertToVersion(obj)
+			if err != nil {
+				return err
+			}
+			data, err := marshal(versionedObject, opt.GenerateJSON, opt.YAMLIndent)
+			if err != nil {
+				return err
+			}
+			typeMeta, objectMeta := getTypeAndObjectMeta(obj)
+			fileName := objectMeta.Name
+			if opt.NumberedFiles {
+				fileName = numberedFileName(fileName, i, len(objectsByDirectory[name]))
+			}
+			file, err := printObjectFile(out, fileName, dir, strings.ToLower(typeMeta.Kind), data, opt)
+			if err != nil {
+				return err
+			}
+			kustomization.Resources = append(kustomization.Resources, filepath.Base(file))
+		}
+		if err := writeKustomization(dir, kustomization, opt.YAMLIndent, out); err != nil {
+			return err
+		}
+	}
+	return writeKustomization(dirName, Kustomization{Resources: names}, opt.YAMLIndent, out)
+}
+
+// printObjectFile writes data to the file of the object name in dir and returns the path of the file
+func printObjectFile(out outputWriter, name, dir, kind string, data []byte, opt kobject.ConvertOptions) (string, error) {
+	if _, ok := out.(diskWriter); ok {
+		return transformer.Print(name, dir, kind, data, false, opt.GenerateJSON, nil, opt.Provider)
+	}
+	file := filepath.Join(dir, transformer.FileName(name, kind, opt.GenerateJSON))
+	return file, out.WriteFile(file, data, 0644)
+}
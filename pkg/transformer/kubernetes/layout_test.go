@@ -0,0 +1,163 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kubernetes/kompose/pkg/kobject"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func newLayoutObjects() []runtime.Object {
+	meta := func(kind, apiVersion, name string) (metav1.TypeMeta, metav1.ObjectMeta) {
+		return metav1.TypeMeta{Kind: kind, APIVersion: apiVersion}, metav1.ObjectMeta{Name: name}
+	}
+	deployment := func(name string, labels map[string]string, spec corev1.PodSpec) *appsv1.Deployment {
+		d := &appsv1.Deployment{}
+		d.TypeMeta, d.ObjectMeta = meta("Deployment", "apps/v1", name)
+		d.Spec.Template.Labels = labels
+		d.Spec.Template.Spec = spec
+		return d
+	}
+	object := func(obj runtime.Object, kind, apiVersion, name string) runtime.Object {
+		typeMeta, objectMeta := meta(kind, apiVersion, name)
+		switch o := obj.(type) {
+		case *corev1.Service:
+			o.TypeMeta, o.ObjectMeta = typeMeta, objectMeta
+		case *corev1.Secret:
+			o.TypeMeta, o.ObjectMeta = typeMeta, objectMeta
+		case *corev1.ConfigMap:
+			o.TypeMeta, o.ObjectMeta = typeMeta, objectMeta
+		case *corev1.PersistentVolumeClaim:
+			o.TypeMeta, o.ObjectMeta = typeMeta, objectMeta
+		case *corev1.Namespace:
+			o.TypeMeta, o.ObjectMeta = typeMeta, objectMeta
+		}
+		return obj
+	}
+
+	web := deployment("web", map[string]string{"io.kompose.network/front": "true"}, corev1.PodSpec{
+		Containers: []corev1.Container{{
+			Name: "web",
+			EnvFrom: []corev1.EnvFromSource{{
+				ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "web-env"}},
+			}},
+			Env: []corev1.EnvVar{{
+				Name: "TOKEN",
+				ValueFrom: &corev1.EnvVarSource{
+					SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "token"}, Key: "token"},
+				},
+			}},
+		}},
+	})
+	db := deployment("db", map[string]string{"io.kompose.network/front": "true", "io.kompose.network/back": "true"}, corev1.PodSpec{
+		Containers: []corev1.Container{{Name: "db"}},
+		Volumes: []corev1.Volume{
+			{Name: "data", VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "data"}}},
+			{Name: "token", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "token"}}},
+		},
+	})
+	backup := &batchv1.CronJob{}
+	backup.TypeMeta, backup.ObjectMeta = meta("CronJob", "batch/v1", "backup")
+	backup.Spec.JobTemplate.Spec.Template.Spec.Volumes = []corev1.Volume{
+		{Name: "config", VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: "backup-config"}}}},
+	}
+	networkPolicy := func(name string) *networkingv1.NetworkPolicy {
+		np := &networkingv1.NetworkPolicy{}
+		np.TypeMeta, np.ObjectMeta = meta("NetworkPolicy", "networking.k8s.io/v1", name)
+		np.Spec.PodSelector.MatchLabels = map[string]string{"io.kompose.network/" + name: "true"}
+		return np
+	}
+
+	return []runtime.Object{
+		object(&corev1.Namespace{}, "Namespace", "v1", "app"),
+		object(&corev1.Secret{}, "Secret", "v1", "token"),
+		object(&corev1.ConfigMap{}, "ConfigMap", "v1", "web-env"),
+		object(&corev1.ConfigMap{}, "ConfigMap", "v1", "backup-config"),
+		object(&corev1.PersistentVolumeClaim{}, "PersistentVolumeClaim", "v1", "data"),
+		object(&corev1.Service{}, "Service", "v1", "web"),
+		web,
+		db,
+		backup,
+		networkPolicy("front"),
+		networkPolicy("back"),
+	}
+}
+
+func TestServiceDirectories(t *testing.T) {
+	expected := []string{
+		SharedDirectory, // Namespace app
+		SharedDirectory, // Secret token, used by web and db
+		"web",           // ConfigMap web-env
+		"backup",        // ConfigMap backup-config
+		"db",            // PersistentVolumeClaim data
+		"web",           // Service web
+		"web",           // Deployment web
+		"db",            // Deployment db
+		"backup",        // CronJob backup
+		SharedDirectory, // NetworkPolicy front, selects web and db
+		"db",            // NetworkPolicy back
+	}
+
+	directories, err := serviceDirectories(newLayoutObjects())
+	if err != nil {
+		t.Fatalf("serviceDirectories failed: %v", err)
+	}
+	for i := range expected {
+		if directories[i] != expected[i] {
+			t.Errorf("Case '%v' for TestServiceDirectories fail, Expected '%v' , got '%v'", i, expected[i], directories[i])
+		}
+	}
+}
+
+func TestPrintListPerService(t *testing.T) {
+	dir := t.TempDir()
+	opt := kobject.ConvertOptions{
+		OutFile:       dir + string(os.PathSeparator),
+		Layout:        LayoutPerService,
+		NumberedFiles: true,
+		YAMLIndent:    2,
+	}
+	if err := PrintList(newLayoutObjects(), opt); err != nil {
+		t.Fatalf("PrintList failed: %v", err)
+	}
+
+	expected := []string{
+		"kustomization.yaml",
+		"_shared/kustomization.yaml",
+		"_shared/01-app-namespace.yaml",
+		"_shared/03-front-networkpolicy.yaml",
+		"web/kustomization.yaml",
+		"web/01-web-env-configmap.yaml",
+		"web/03-web-deployment.yaml",
+		"db/02-db-deployment.yaml",
+		"backup/01-backup-config-configmap.yaml",
+	}
+	for _, file := range expected {
+		if _, err := os.Stat(filepath.Join(dir, file)); err != nil {
+			t.Errorf("Expected %s in the per-service layout: %v", file, err)
+		}
+	}
+}
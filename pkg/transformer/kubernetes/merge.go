@@ -0,0 +1,331 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/kubernetes/kompose/pkg/kobject"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// MergeStateFile is the file next to the manifests where --merge records the objects kompose generated,
+// the base of the three-way merge of the next conversion
+const MergeStateFile = ".kompose-state.json"
+
+// mergeState is the content of the MergeStateFile
+type mergeState struct {
+	Objects []map[string]interface{} `json:"objects"`
+}
+
+// mergesDirectory returns true if the objects are merged with the manifests of a directory, false for a single file
+func mergesDirectory(opt kobject.ConvertOptions) (bool, error) {
+	isDirVal, err := isDir(opt.OutFile)
+	if err != nil {
+		return false, err
+	}
+	return isDirVal || strings.HasSuffix(opt.OutFile, "/") || opt.Layout == LayoutPerService, nil
+}
+
+// mergeStatePath returns the path of the MergeStateFile of the --out file or directory
+func mergeStatePath(opt kobject.ConvertOptions) (string, error) {
+	directory, err := mergesDirectory(opt)
+	if err != nil {
+		return "", err
+	}
+	if directory {
+		return filepath.Join(opt.OutFile, MergeStateFile), nil
+	}
+	// The state of a single file is named after it, several files can be written to the same directory
+	return filepath.Join(filepath.Dir(opt.OutFile), "."+filepath.Base(opt.OutFile)+MergeStateFile), nil
+}
+
+// printMerged merges the objects with the manifests of --out before writing them: the fields changed by hand
+// since the last conversion are kept, the fields kompose generates are updated. The objects added by hand are
+// kept, the objects kompose generated the last time and doesn't generate anymore are removed. The generated
+// objects are recorded in the MergeStateFile for the next conversion.
+func printMerged(objects []runtime.Object, opt kobject.ConvertOptions) error {
+	statePath, err := mergeStatePath(opt)
+	if err != nil {
+		return errors.Wrap(err, "isDir failed")
+	}
+	directory, err := mergesDirectory(opt)
+	if err != nil {
+		return errors.Wrap(err, "isDir failed")
+	}
+
+	base := map[string]map[string]interface{}{}
+	data, err := os.ReadFile(statePath)
+	if err == nil {
+		var state mergeState
+		if err := json.Unmarshal(data, &state); err != nil {
+			return errors.Wrapf(err, "failed to parse %s", statePath)
+		}
+		for _, object := range state.Objects {
+			base[objectKey(object)] = object
+		}
+	} else if !os.IsNotExist(err) {
+		return errors.Wrapf(err, "failed to read %s", statePath)
+	}
+
+	current := map[string]map[string]interface{}{}
+	var manifests []manifest
+	if _, err := os.Stat(opt.OutFile); err == nil {
+		manifests, err = readManifests(opt.OutFile)
+		if err != nil {
+			return err
+		}
+		for _, m := range manifests {
+			current[objectKey(m.object)] = m.object
+		}
+	}
+	if len(base) == 0 && len(current) > 0 {
+		log.Warnf("%s does not exist, the changes made by hand to the fields kompose generates are overwritten", statePath)
+	}
+
+	var state mergeState
+	merged := make([]runtime.Object, 0, len(objects))
+	generatedKeys := map[string]bool{}
+	for _, obj := range objects {
+		object, err := toUnstructuredMap(obj)
+		if err != nil {
+			return err
+		}
+		state.Objects = append(state.Objects, object)
+
+		key := objectKey(object)
+		generatedKeys[key] = true
+		if _, ok := current[key]; !ok {
+			merged = append(merged, obj)
+			continue
+		}
+		// The generated object is copied as it is part of the state
+		generated, err := toUnstructuredMap(obj)
+		if err != nil {
+			return err
+		}
+		m := threeWayMerge{}
+		result := m.maps("", base[key], current[key], generated)
+		for _, conflict := range m.conflicts {
+			log.Warnf("%s: %s was changed by hand and by kompose, the change by hand is overwritten", objectDescription(object), conflict)
+		}
+		merged = append(merged, &unstructured.Unstructured{Object: result})
+	}
+
+	added, removedFiles := manualChanges(manifests, base, generatedKeys, directory)
+	merged = append(merged, added...)
+	for _, file := range removedFiles {
+		if err := os.Remove(file); err != nil {
+			return errors.Wrapf(err, "failed to remove %s", file)
+		}
+	}
+
+	opt.Merge = false
+	if err := PrintList(merged, opt); err != nil {
+		return err
+	}
+
+	data, err = json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal the merge state")
+	}
+	if err := os.WriteFile(statePath, append(data, '\n'), 0644); err != nil {
+		return errors.Wrapf(err, "failed to write %s", statePath)
+	}
+	return nil
+}
+
+// manualChanges returns the objects of the manifests added by hand, neither generated the last time (base) nor now,
+// to write with the generated objects. The objects generated the last time and not anymore are dropped.
+// The files of a directory are rewritten one object per file: the objects added by hand to a file of their own
+// are left in it, and the files holding objects that aren't generated anymore are returned to be removed.
+func manualChanges(manifests []manifest, base map[string]map[string]interface{}, generated map[string]bool, directory bool) ([]runtime.Object, []string) {
+	manual := func(object map[string]interface{}) bool {
+		key := objectKey(object)
+		_, inBase := base[key]
+		return !inBase && !generated[key]
+	}
+	// The files holding only objects added by hand
+	manualFiles := map[string]bool{}
+	for _, m := range manifests {
+		if _, ok := manualFiles[m.file]; !ok {
+			manualFiles[m.file] = true
+		}
+		if !manual(m.object) {
+			manualFiles[m.file] = false
+		}
+	}
+
+	var added []runtime.Object
+	var removedFiles []string
+	removed := map[string]bool{}
+	for _, m := range manifests {
+		key := objectKey(m.object)
+		switch {
+		case manual(m.object):
+			if directory && manualFiles[m.file] {
+				continue
+			}
+			log.Debugf("%s was added by hand, it is kept", objectDescription(m.object))
+			added = append(added, &unstructured.Unstructured{Object: m.object})
+		case !generated[key]:
+			log.Infof("%s is not generated anymore, it is removed", objectDescription(m.object))
+			if directory && !removed[m.file] {
+				removed[m.file] = true
+				removedFiles = append(removedFiles, m.file)
+			}
+		}
+	}
+	return added, removedFiles
+}
+
+// threeWayMerge merges the value kompose generated the last time (base), the value of the manifest (current)
+// and the value kompose generates now (generated). The changes made to the manifest since the last conversion
+// are kept, unless kompose changes the same field: the paths of these fields are recorded as conflicts.
+type threeWayMerge struct {
+	conflicts []string
+}
+
+// values merges values that may be missing, the merged value is missing when the second result is false
+func (m *threeWayMerge) values(path string, base, current, generated interface{}, inBase, inCurrent, inGenerated bool) (interface{}, bool) {
+	baseMap, baseIsMap := base.(map[string]interface{})
+	currentMap, currentIsMap := current.(map[string]interface{})
+	generatedMap, generatedIsMap := generated.(map[string]interface{})
+	if inCurrent && inGenerated && currentIsMap && generatedIsMap && (!inBase || baseIsMap) {
+		return m.maps(path, baseMap, currentMap, generatedMap), true
+	}
+	if inCurrent && inGenerated {
+		if list, ok := m.namedLists(path, base, current, generated); ok {
+			return list, true
+		}
+	}
+
+	switch {
+	case inBase && inCurrent && reflect.DeepEqual(base, current):
+		// Not changed by hand
+		return generated, inGenerated
+	case inBase && inGenerated && reflect.DeepEqual(base, generated):
+		// Not changed by kompose
+		return current, inCurrent
+	case !inBase && !inGenerated:
+		// Added by hand
+		return current, inCurrent
+	case inCurrent == inGenerated && reflect.DeepEqual(current, generated):
+		return generated, inGenerated
+	}
+	// Without a base, the fields kompose generates can't be told apart from the changes by hand
+	if inBase {
+		m.conflicts = append(m.conflicts, path)
+	}
+	return generated, inGenerated
+}
+
+// maps merges the fields of maps
+func (m *threeWayMerge) maps(path string, base, current, generated map[string]interface{}) map[string]interface{} {
+	var keys []string
+	seen := map[string]bool{}
+	for _, fields := range []map[string]interface{}{base, current, generated} {
+		for key := range fields {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys)
+
+	merged := map[string]interface{}{}
+	for _, key := range keys {
+		fieldPath := key
+		if path != "" {
+			fieldPath = path + "." + key
+		}
+		b, inBase := base[key]
+		c, inCurrent := current[key]
+		g, inGenerated := generated[key]
+		if value, ok := m.values(fieldPath, b, c, g, inBase, inCurrent, inGenerated); ok {
+			merged[key] = value
+		}
+	}
+	return merged
+}
+
+// namedLists merges the lists whose items are objects with a unique name, like the containers, their ports,
+// environment variables and volume mounts, item by item. It returns false for the other lists, merged as values.
+func (m *threeWayMerge) namedLists(path string, base, current, generated interface{}) ([]interface{}, bool) {
+	_, baseItems, baseOK := namedItems(base)
+	currentOrder, currentItems, currentOK := namedItems(current)
+	generatedOrder, generatedItems, generatedOK := namedItems(generated)
+	if !baseOK || !currentOK || !generatedOK || len(currentOrder) == 0 || len(generatedOrder) == 0 {
+		return nil, false
+	}
+
+	merged := []interface{}{}
+	add := func(name string) {
+		b, inBase := baseItems[name]
+		c, inCurrent := currentItems[name]
+		g, inGenerated := generatedItems[name]
+		if value, ok := m.values(fmt.Sprintf("%s[%s]", path, name), b, c, g, inBase, inCurrent, inGenerated); ok {
+			merged = append(merged, value)
+		}
+	}
+	// The generated items in their order, then the items added by hand
+	for _, name := range generatedOrder {
+		add(name)
+	}
+	for _, name := range currentOrder {
+		if _, ok := generatedItems[name]; !ok {
+			add(name)
+		}
+	}
+	return merged, true
+}
+
+// namedItems returns the names and the items of a list of objects with a unique name, false for the other values
+func namedItems(value interface{}) ([]string, map[string]interface{}, bool) {
+	if value == nil {
+		return nil, nil, true
+	}
+	list, ok := value.([]interface{})
+	if !ok {
+		return nil, nil, false
+	}
+	var names []string
+	items := map[string]interface{}{}
+	for _, item := range list {
+		object, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, nil, false
+		}
+		name, ok := object["name"].(string)
+		if _, duplicate := items[name]; !ok || duplicate {
+			return nil, nil, false
+		}
+		names = append(names, name)
+		items[name] = object
+	}
+	return names, items, true
+}
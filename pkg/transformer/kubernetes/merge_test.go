@@ -0,0 +1,180 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/kubernetes/kompose/pkg/kobject"
+	appsv1 "k8s.io/api/apps/v1"
+)
+
+func TestThreeWayMerge(t *testing.T) {
+	fromJSON := func(s string) map[string]interface{} {
+		if s == "" {
+			return nil
+		}
+		m := map[string]interface{}{}
+		if err := json.Unmarshal([]byte(s), &m); err != nil {
+			t.Fatalf("invalid JSON %s: %v", s, err)
+		}
+		return m
+	}
+	testCases := map[string]struct {
+		base, current, generated string
+		expected                 string
+		conflicts                []string
+	}{
+		"generated field updated":     {`{"a":1}`, `{"a":1}`, `{"a":2}`, `{"a":2}`, nil},
+		"field changed by hand":       {`{"a":1}`, `{"a":3}`, `{"a":1}`, `{"a":3}`, nil},
+		"field added by hand":         {`{"a":1}`, `{"a":1,"b":1}`, `{"a":2}`, `{"a":2,"b":1}`, nil},
+		"field removed by hand":       {`{"a":1,"b":1}`, `{"a":1}`, `{"a":1,"b":1}`, `{"a":1}`, nil},
+		"field removed by kompose":    {`{"a":1,"b":1}`, `{"a":1,"b":1}`, `{"a":1}`, `{"a":1}`, nil},
+		"changed by hand and kompose": {`{"a":1}`, `{"a":3}`, `{"a":2}`, `{"a":2}`, []string{"a"}},
+		"no base":                     {``, `{"a":3,"b":1}`, `{"a":2}`, `{"a":2,"b":1}`, nil},
+		"named list": {
+			`{"c":[{"name":"web","image":"v1"}]}`,
+			`{"c":[{"name":"web","image":"v1","limits":"1Gi"},{"name":"sidecar"}]}`,
+			`{"c":[{"name":"web","image":"v2"}]}`,
+			`{"c":[{"name":"web","image":"v2","limits":"1Gi"},{"name":"sidecar"}]}`,
+			nil,
+		},
+		"list": {`{"l":[1,2]}`, `{"l":[1,2,3]}`, `{"l":[1]}`, `{"l":[1]}`, []string{"l"}},
+	}
+
+	for name, test := range testCases {
+		m := threeWayMerge{}
+		merged := m.maps("", fromJSON(test.base), fromJSON(test.current), fromJSON(test.generated))
+		if !reflect.DeepEqual(merged, fromJSON(test.expected)) {
+			t.Errorf("Case '%v' for TestThreeWayMerge fail, Expected '%v', got '%v'", name, test.expected, merged)
+		}
+		if !reflect.DeepEqual(m.conflicts, test.conflicts) {
+			t.Errorf("Case '%v' for TestThreeWayMerge fail, Expected conflicts %v, got %v", name, test.conflicts, m.conflicts)
+		}
+	}
+}
+
+func TestPrintListMerge(t *testing.T) {
+	dir := t.TempDir()
+	opt := kobject.ConvertOptions{OutFile: dir + string(os.PathSeparator), YAMLIndent: 2, Merge: true}
+	if err := PrintList(newDiffObjects(1), opt); err != nil {
+		t.Fatalf("PrintList failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, MergeStateFile)); err != nil {
+		t.Fatalf("Expected the merge state to be written: %v", err)
+	}
+
+	// Add a limit by hand, then regenerate with new replicas and image
+	file := filepath.Join(dir, "web-deployment.yaml")
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data = []byte(strings.Replace(string(data), "resources: {}", "resources:\n            limits:\n              memory: 256Mi", 1))
+	if err := os.WriteFile(file, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	objects := newDiffObjects(3)
+	objects[0].(*appsv1.Deployment).Spec.Template.Spec.Containers[0].Image = "nginx:2"
+	if err := PrintList(objects, opt); err != nil {
+		t.Fatalf("PrintList failed: %v", err)
+	}
+
+	data, err = os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{"replicas: 3", "image: nginx:2", "memory: 256Mi", "kompose.version: 1.0.0"} {
+		if !strings.Contains(string(data), expected) {
+			t.Errorf("Expected '%v' in the merged manifest:\n%s", expected, data)
+		}
+	}
+
+	// The merge state is skipped when the manifests are read
+	manifests, err := readManifests(opt.OutFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(manifests) != 2 {
+		t.Errorf("Expected the merge state not to be read as a manifest, got %d manifests", len(manifests))
+	}
+}
+
+func TestPrintListMergeFile(t *testing.T) {
+	opt := kobject.ConvertOptions{OutFile: filepath.Join(t.TempDir(), "out.yaml"), YAMLIndent: 2, Merge: true}
+	if err := PrintList(newDiffObjects(1), opt); err != nil {
+		t.Fatalf("PrintList failed: %v", err)
+	}
+
+	// Add a ConfigMap by hand, then stop generating the Service
+	data, err := os.ReadFile(opt.OutFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data = append(data, []byte("---\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: manual\ndata:\n  key: value\n")...)
+	if err := os.WriteFile(opt.OutFile, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := PrintList(newDiffObjects(1)[:1], opt); err != nil {
+		t.Fatalf("PrintList failed: %v", err)
+	}
+
+	manifests, err := readManifests(opt.OutFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var descriptions []string
+	for _, m := range manifests {
+		descriptions = append(descriptions, objectDescription(m.object))
+	}
+	expected := []string{"Deployment web", "ConfigMap manual"}
+	if !reflect.DeepEqual(descriptions, expected) {
+		t.Errorf("Expected the Deployment and the ConfigMap added by hand, got %v", descriptions)
+	}
+}
+
+func TestPrintListMergeDirectory(t *testing.T) {
+	dir := t.TempDir()
+	opt := kobject.ConvertOptions{OutFile: dir + string(os.PathSeparator), YAMLIndent: 2, Merge: true}
+	if err := PrintList(newDiffObjects(1), opt); err != nil {
+		t.Fatalf("PrintList failed: %v", err)
+	}
+
+	// Add a ConfigMap by hand in a file of its own, then stop generating the Service
+	manual := filepath.Join(dir, "manual-configmap.yaml")
+	if err := os.WriteFile(manual, []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: manual\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := PrintList(newDiffObjects(1)[:1], opt); err != nil {
+		t.Fatalf("PrintList failed: %v", err)
+	}
+
+	if _, err := os.Stat(manual); err != nil {
+		t.Errorf("Expected the file added by hand to be kept: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "web-service.yaml")); !os.IsNotExist(err) {
+		t.Errorf("Expected the file of the Service not generated anymore to be removed, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "web-deployment.yaml")); err != nil {
+		t.Errorf("Expected the file of the Deployment to be kept: %v", err)
+	}
+}
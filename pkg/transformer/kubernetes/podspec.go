@@ -181,14 +181,12 @@ func SetVolumeNames(volumes []api.Volume) mapset.Set {
 // SetVolumes method returns a method that adds the volumes to the pod spec
 func SetVolumes(volumes []api.Volume) PodSpecOption {
 	return func(podSpec *PodSpec) {
-		volumesSet := SetVolumeNames(volumes)
+		// Walk the volumes in order instead of the set difference, sets are iterated in a random order
 		containerVolumesSet := SetVolumeNames(podSpec.Volumes)
-		for diffVolumeName := range volumesSet.Difference(containerVolumesSet).Iter() {
-			for _, volume := range volumes {
-				if volume.Name == diffVolumeName {
-					podSpec.Volumes = append(podSpec.Volumes, volume)
-					break
-				}
+		for _, volume := range volumes {
+			if !containerVolumesSet.Contains(volume.Name) {
+				containerVolumesSet.Add(volume.Name)
+				podSpec.Volumes = append(podSpec.Volumes, volume)
 			}
 		}
 	}
@@ -207,15 +205,12 @@ func SetVolumeMountPaths(volumesMount []api.VolumeMount) mapset.Set {
 // SetVolumeMounts returns a function which adds the volume mounts option to the pod spec
 func SetVolumeMounts(volumesMount []api.VolumeMount) PodSpecOption {
 	return func(podSpec *PodSpec) {
-		volumesMountSet := SetVolumeMountPaths(volumesMount)
 		for i := range podSpec.Containers {
 			containerVolumeMountsSet := SetVolumeMountPaths(podSpec.Containers[i].VolumeMounts)
-			for diffVolumeMountPath := range volumesMountSet.Difference(containerVolumeMountsSet).Iter() {
-				for _, volumeMount := range volumesMount {
-					if volumeMount.MountPath == diffVolumeMountPath {
-						podSpec.Containers[i].VolumeMounts = append(podSpec.Containers[i].VolumeMounts, volumeMount)
-						break
-					}
+			for _, volumeMount := range volumesMount {
+				if !containerVolumeMountsSet.Contains(volumeMount.MountPath) {
+					containerVolumeMountsSet.Add(volumeMount.MountPath)
+					podSpec.Containers[i].VolumeMounts = append(podSpec.Containers[i].VolumeMounts, volumeMount)
 				}
 			}
 		}
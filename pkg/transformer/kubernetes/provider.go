@@ -0,0 +1,75 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"fmt"
+
+	"github.com/kubernetes/kompose/pkg/kobject"
+	"github.com/kubernetes/kompose/pkg/transformer"
+	"github.com/pkg/errors"
+)
+
+// ProviderFlags are the convert flags that only apply to the Kubernetes provider and the providers built on it
+var ProviderFlags = []string{"chart", "daemon-set", "replication-controller", "deployment", "expose-mode", "gateway"}
+
+// OutputFormats are the values of --output-format of the Kubernetes provider and the providers built on it
+var OutputFormats = []string{OutputFormatKustomize, OutputFormatYAMLList}
+
+func init() {
+	transformer.RegisterProvider(transformer.Provider{
+		Name: "kubernetes",
+		New: func(opt kobject.ConvertOptions) transformer.Transformer {
+			return &Kubernetes{Opt: opt}
+		},
+		Flags:         ProviderFlags,
+		OutputFormats: OutputFormats,
+		Validate:      ValidateOptions,
+		SetDefaults:   SetDefaultOptions,
+	})
+}
+
+// ValidateOptions checks that the convert options only use the Kubernetes controllers and builds
+func ValidateOptions(opt kobject.ConvertOptions) error {
+	if opt.Controller == "deploymentconfig" {
+		return errors.New("--controller=deploymentConfig is an OpenShift only flag")
+	}
+	if opt.Build == "build-config" {
+		return fmt.Errorf("build-config is not a valid --build parameter with provider %s", opt.Provider)
+	}
+	return nil
+}
+
+// SetDefaultOptions generates a Deployment when no controller is set, only one kind of controller can be
+// generated in a single output
+func SetDefaultOptions(opt *kobject.ConvertOptions) error {
+	if !opt.CreateD && !opt.CreateDS && !opt.CreateRC && opt.Controller == "" {
+		opt.CreateD = true
+	}
+	if len(opt.OutFile) != 0 || opt.ToStdout {
+		count := 0
+		for _, create := range []bool{opt.CreateD, opt.CreateDS, opt.CreateRC} {
+			if create {
+				count++
+			}
+		}
+		if count > 1 {
+			return errors.New("only one kind of Kubernetes resource can be generated when --out or --stdout is specified")
+		}
+	}
+	return nil
+}
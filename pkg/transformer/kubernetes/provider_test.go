@@ -0,0 +1,66 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/kubernetes/kompose/pkg/kobject"
+)
+
+func TestSetDefaultOptions(t *testing.T) {
+	testCases := map[string]struct {
+		opt             kobject.ConvertOptions
+		expectedCreateD bool
+		expectedError   bool
+	}{
+		"no controller":          {kobject.ConvertOptions{}, true, false},
+		"controller set":         {kobject.ConvertOptions{Controller: "daemonset"}, false, false},
+		"daemonset":              {kobject.ConvertOptions{CreateDS: true}, false, false},
+		"two kinds in a file":    {kobject.ConvertOptions{CreateD: true, CreateDS: true, OutFile: "out.yaml"}, true, true},
+		"two kinds in directory": {kobject.ConvertOptions{CreateD: true, CreateRC: true}, true, false},
+	}
+
+	for name, test := range testCases {
+		opt := test.opt
+		err := SetDefaultOptions(&opt)
+		if (err != nil) != test.expectedError {
+			t.Errorf("Case '%v' for TestSetDefaultOptions fail, Expected error %v, got %v", name, test.expectedError, err)
+		}
+		if opt.CreateD != test.expectedCreateD {
+			t.Errorf("Case '%v' for TestSetDefaultOptions fail, Expected CreateD %v, got %v", name, test.expectedCreateD, opt.CreateD)
+		}
+	}
+}
+
+func TestValidateOptions(t *testing.T) {
+	testCases := map[string]struct {
+		opt           kobject.ConvertOptions
+		expectedError bool
+	}{
+		"deployment":       {kobject.ConvertOptions{Controller: DeploymentController}, false},
+		"deploymentconfig": {kobject.ConvertOptions{Controller: "deploymentconfig"}, true},
+		"build-config":     {kobject.ConvertOptions{Build: "build-config", Provider: "kubernetes"}, true},
+	}
+
+	for name, test := range testCases {
+		err := ValidateOptions(test.opt)
+		if (err != nil) != test.expectedError {
+			t.Errorf("Case '%v' for TestValidateOptions fail, Expected error %v, got %v", name, test.expectedError, err)
+		}
+	}
+}
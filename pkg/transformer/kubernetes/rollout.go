@@ -0,0 +1,210 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"time"
+
+	"github.com/kubernetes/kompose/pkg/kobject"
+	"github.com/kubernetes/kompose/pkg/transformer"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cast"
+	api "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// Rollout is an argoproj.io/v1alpha1 Rollout of Argo Rollouts, only the fields set by kompose are defined
+type Rollout struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              RolloutSpec `json:"spec"`
+}
+
+// RolloutSpec is the spec of a Rollout
+type RolloutSpec struct {
+	Replicas *int32                `json:"replicas,omitempty"`
+	Selector *metav1.LabelSelector `json:"selector"`
+	Template api.PodTemplateSpec   `json:"template"`
+	Strategy RolloutStrategy       `json:"strategy"`
+}
+
+// RolloutStrategy holds either a canary or a blue-green strategy
+type RolloutStrategy struct {
+	Canary    *CanaryStrategy    `json:"canary,omitempty"`
+	BlueGreen *BlueGreenStrategy `json:"blueGreen,omitempty"`
+}
+
+// CanaryStrategy shifts the pods to the new version by steps
+type CanaryStrategy struct {
+	MaxSurge       *intstr.IntOrString `json:"maxSurge,omitempty"`
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+	Steps          []CanaryStep        `json:"steps,omitempty"`
+}
+
+// CanaryStep either sets the percentage of pods running the new version or pauses the rollout
+type CanaryStep struct {
+	SetWeight *int32        `json:"setWeight,omitempty"`
+	Pause     *RolloutPause `json:"pause,omitempty"`
+}
+
+// RolloutPause pauses a rollout for a duration
+type RolloutPause struct {
+	Duration *intstr.IntOrString `json:"duration,omitempty"`
+}
+
+// BlueGreenStrategy starts the new version next to the old one and switches the active service to it
+type BlueGreenStrategy struct {
+	ActiveService         string `json:"activeService"`
+	AutoPromotionEnabled  *bool  `json:"autoPromotionEnabled,omitempty"`
+	ScaleDownDelaySeconds *int32 `json:"scaleDownDelaySeconds,omitempty"`
+}
+
+// DeepCopyObject implements runtime.Object
+func (r *Rollout) DeepCopyObject() runtime.Object {
+	out := &Rollout{TypeMeta: r.TypeMeta}
+	r.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	if r.Spec.Replicas != nil {
+		replicas := *r.Spec.Replicas
+		out.Spec.Replicas = &replicas
+	}
+	out.Spec.Selector = r.Spec.Selector.DeepCopy()
+	r.Spec.Template.DeepCopyInto(&out.Spec.Template)
+
+	if canary := r.Spec.Strategy.Canary; canary != nil {
+		out.Spec.Strategy.Canary = &CanaryStrategy{}
+		if canary.MaxSurge != nil {
+			v := *canary.MaxSurge
+			out.Spec.Strategy.Canary.MaxSurge = &v
+		}
+		if canary.MaxUnavailable != nil {
+			v := *canary.MaxUnavailable
+			out.Spec.Strategy.Canary.MaxUnavailable = &v
+		}
+		for _, step := range canary.Steps {
+			var s CanaryStep
+			if step.SetWeight != nil {
+				v := *step.SetWeight
+				s.SetWeight = &v
+			}
+			if step.Pause != nil {
+				s.Pause = &RolloutPause{}
+				if step.Pause.Duration != nil {
+					v := *step.Pause.Duration
+					s.Pause.Duration = &v
+				}
+			}
+			out.Spec.Strategy.Canary.Steps = append(out.Spec.Strategy.Canary.Steps, s)
+		}
+	}
+
+	if blueGreen := r.Spec.Strategy.BlueGreen; blueGreen != nil {
+		out.Spec.Strategy.BlueGreen = &BlueGreenStrategy{ActiveService: blueGreen.ActiveService}
+		if blueGreen.AutoPromotionEnabled != nil {
+			v := *blueGreen.AutoPromotionEnabled
+			out.Spec.Strategy.BlueGreen.AutoPromotionEnabled = &v
+		}
+		if blueGreen.ScaleDownDelaySeconds != nil {
+			v := *blueGreen.ScaleDownDelaySeconds
+			out.Spec.Strategy.BlueGreen.ScaleDownDelaySeconds = &v
+		}
+	}
+	return out
+}
+
+// InitRollout initializes an Argo Rollouts Rollout object
+func (k *Kubernetes) InitRollout(name string, service kobject.ServiceConfig, replicas int) *Rollout {
+	var podSpec api.PodSpec
+	if len(service.Configs) > 0 {
+		podSpec = k.InitPodSpecWithConfigMap(name, service.Image, service)
+	} else {
+		podSpec = k.InitPodSpec(name, service.Image, service.ImagePullSecret)
+	}
+
+	rp := int32(replicas)
+
+	rollout := &Rollout{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Rollout",
+			APIVersion: "argoproj.io/v1alpha1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: transformer.ConfigAllLabels(name, &service),
+		},
+		Spec: RolloutSpec{
+			Replicas: &rp,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: transformer.ConfigLabels(name),
+			},
+			Template: api.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels:      transformer.ConfigLabels(name),
+					Annotations: transformer.ConfigAnnotations(service),
+				},
+				Spec: podSpec,
+			},
+			Strategy: GetRolloutStrategy(name, service, replicas),
+		},
+	}
+	return rollout
+}
+
+// GetRolloutStrategy maps deploy.update_config to the strategy of a Rollout.
+// With order start-first the new version is started next to the old one, as a blue-green
+// rollout switching the service of the compose service once the new pods are available.
+// Otherwise the pods are replaced by canary steps of parallelism pods, paused for delay.
+func GetRolloutStrategy(name string, service kobject.ServiceConfig, replicas int) RolloutStrategy {
+	config := service.DeployUpdateConfig
+
+	if config.Order == "start-first" {
+		if len(service.Port) > 0 {
+			blueGreen := &BlueGreenStrategy{ActiveService: name}
+			autoPromotion := true
+			blueGreen.AutoPromotionEnabled = &autoPromotion
+			if config.Delay != 0 {
+				delay := int32(time.Duration(config.Delay).Seconds())
+				blueGreen.ScaleDownDelaySeconds = &delay
+			}
+			return RolloutStrategy{BlueGreen: blueGreen}
+		}
+		log.Warnf("Service %s has no port to switch for a blue-green rollout, using a canary rollout", name)
+	}
+
+	canary := &CanaryStrategy{}
+	if config.Parallelism != nil && *config.Parallelism > 0 {
+		parallelism := cast.ToInt(*config.Parallelism)
+		if config.Order == "stop-first" {
+			maxUnavailable, maxSurge := intstr.FromInt(parallelism), intstr.FromInt(0)
+			canary.MaxUnavailable, canary.MaxSurge = &maxUnavailable, &maxSurge
+		}
+
+		// One step for each batch of parallelism pods, the last batch completes the rollout
+		for updated := parallelism; updated < replicas; updated += parallelism {
+			weight := int32(updated * 100 / replicas)
+			canary.Steps = append(canary.Steps, CanaryStep{SetWeight: &weight})
+			if config.Delay != 0 {
+				duration := intstr.FromString(time.Duration(config.Delay).String())
+				canary.Steps = append(canary.Steps, CanaryStep{Pause: &RolloutPause{Duration: &duration}})
+			}
+		}
+	} else if config.Delay != 0 {
+		log.Warnf("Service %s sets an update delay without parallelism, the delay is ignored by the canary rollout", name)
+	}
+	return RolloutStrategy{Canary: canary}
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/compose-spec/compose-go/types"
+	"github.com/kubernetes/kompose/pkg/kobject"
+	"github.com/kubernetes/kompose/pkg/loader/compose"
+	"github.com/pkg/errors"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+)
+
+func TestGetRolloutStrategy(t *testing.T) {
+	parallelism := uint64(2)
+	ports := []kobject.Ports{{HostPort: 8080, ContainerPort: 80}}
+
+	testCases := map[string]struct {
+		config   types.UpdateConfig
+		ports    []kobject.Ports
+		replicas int
+		expected string
+	}{
+		"No update config": {types.UpdateConfig{}, ports, 3, `{"canary":{}}`},
+		"Parallelism and delay": {
+			types.UpdateConfig{Parallelism: &parallelism, Delay: types.Duration(10 * time.Second)}, ports, 5,
+			`{"canary":{"steps":[{"setWeight":40},{"pause":{"duration":"10s"}},{"setWeight":80},{"pause":{"duration":"10s"}}]}}`,
+		},
+		"Stop first": {
+			types.UpdateConfig{Parallelism: &parallelism, Order: "stop-first"}, ports, 4,
+			`{"canary":{"maxSurge":0,"maxUnavailable":2,"steps":[{"setWeight":50}]}}`,
+		},
+		"Start first": {
+			types.UpdateConfig{Order: "start-first", Delay: types.Duration(time.Minute)}, ports, 2,
+			`{"blueGreen":{"activeService":"web","autoPromotionEnabled":true,"scaleDownDelaySeconds":60}}`,
+		},
+		"Start first without port": {types.UpdateConfig{Order: "start-first"}, nil, 2, `{"canary":{}}`},
+	}
+
+	for name, test := range testCases {
+		t.Log("Test case:", name)
+		service := kobject.ServiceConfig{Name: "web", Port: test.ports, DeployUpdateConfig: test.config}
+		data, err := json.Marshal(GetRolloutStrategy("web", service, test.replicas))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != test.expected {
+			t.Errorf("Expected strategy %s, got %s", test.expected, data)
+		}
+	}
+}
+
+func TestKomposeConvertRollout(t *testing.T) {
+	service := newServiceConfig()
+	service.Replicas = 3
+	service.CPUReservation = 100
+	service.Labels = map[string]string{compose.LabelControllerType: RolloutController}
+	service.HPAMaxReplicas = 5
+	komposeObject := kobject.KomposeObject{
+		ServiceConfigs: map[string]kobject.ServiceConfig{"app": service},
+	}
+	k := Kubernetes{}
+	objects, err := k.Transform(komposeObject, kobject.ConvertOptions{CreateD: true, Replicas: 1, GeneratePodDisruptionBudgets: true})
+	if err != nil {
+		t.Fatal(errors.Wrap(err, "k.Transform failed"))
+	}
+
+	var rollout *Rollout
+	var hpa *autoscalingv2.HorizontalPodAutoscaler
+	pdb := false
+	for _, obj := range objects {
+		switch o := obj.(type) {
+		case *Rollout:
+			rollout = o
+		case *autoscalingv2.HorizontalPodAutoscaler:
+			hpa = o
+		}
+		if obj.GetObjectKind().GroupVersionKind().Kind == "PodDisruptionBudget" {
+			pdb = true
+		}
+		if obj.GetObjectKind().GroupVersionKind().Kind == "Deployment" {
+			t.Errorf("Expected the controller type label to replace the Deployment")
+		}
+	}
+
+	if rollout == nil {
+		t.Fatalf("Expected a Rollout, got %v", objects)
+	}
+	if *rollout.Spec.Replicas != 3 {
+		t.Errorf("Expected 3 replicas, got %d", *rollout.Spec.Replicas)
+	}
+	if len(rollout.Spec.Template.Spec.Containers) != 1 || rollout.Spec.Template.Spec.Containers[0].Image != "image" {
+		t.Errorf("Expected the pod template to be filled, got %v", rollout.Spec.Template.Spec.Containers)
+	}
+	if hpa == nil || hpa.Spec.ScaleTargetRef.Kind != "Rollout" || hpa.Spec.ScaleTargetRef.APIVersion != "argoproj.io/v1alpha1" {
+		t.Errorf("Expected a HorizontalPodAutoscaler targeting the Rollout, got %v", hpa)
+	}
+	if !pdb {
+		t.Errorf("Expected a PodDisruptionBudget for the replicated Rollout")
+	}
+	if copied, ok := rollout.DeepCopyObject().(*Rollout); !ok || copied == rollout || copied.Spec.Template.Spec.Containers[0].Image != "image" {
+		t.Errorf("Expected DeepCopyObject to return a copy of the Rollout")
+	}
+}
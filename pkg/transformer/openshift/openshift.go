@@ -280,9 +280,15 @@ func (o *OpenShift) Transform(komposeObject kobject.KomposeObject, opt kobject.C
 		}
 	}
 
+	// owners records the compose service of the objects, to name their Template parameters
+	owners := map[runtime.Object]string{}
+
 	sortedKeys := kubernetes.SortedKeys(komposeObject)
 	for _, name := range sortedKeys {
 		service := komposeObject.ServiceConfigs[name]
+		if err := kubernetes.CheckCronJobSchedule(name, service, opt); err != nil {
+			return nil, err
+		}
 		var objects []runtime.Object
 
 		//replicas
@@ -326,7 +332,7 @@ func (o *OpenShift) Transform(komposeObject kobject.KomposeObject, opt kobject.C
 		}
 
 		// Generate pod only and nothing more
-		if service.Restart == "no" || service.Restart == "on-failure" {
+		if (service.Restart == "no" || service.Restart == "on-failure") && service.CronJobSchedule == "" {
 			// Error out if Controller Object is specified with restart: 'on-failure'
 			if opt.IsDeploymentConfigFlag {
 				return nil, errors.New("Controller object cannot be specified with restart: 'on-failure'")
@@ -336,7 +342,7 @@ func (o *OpenShift) Transform(komposeObject kobject.KomposeObject, opt kobject.C
 		} else {
 			objects = o.CreateWorkloadAndConfigMapObjects(name, service, opt)
 
-			if opt.CreateDeploymentConfig {
+			if opt.CreateDeploymentConfig && service.CronJobSchedule == "" {
 				objects = append(objects, o.initDeploymentConfig(name, service, replica)) // OpenShift DeploymentConfigs
 				// create ImageStream after deployment (creating IS will trigger new deployment)
 				objects = append(objects, o.initImageStream(name, service, opt))
@@ -422,14 +428,26 @@ func (o *OpenShift) Transform(komposeObject kobject.KomposeObject, opt kobject.C
 			return nil, errors.Wrap(err, "Error transforming Kubernetes objects")
 		}
 
+		for _, obj := range objects {
+			owners[obj] = name
+		}
 		allobjects = append(allobjects, objects...)
 	}
 
 	// sort all object so Services are first
-	o.SortServicesFirst(&allobjects)
+	o.SortObjectsByInstallOrder(&allobjects)
 	o.RemoveDupObjects(&allobjects)
 	transformer.AssignNamespaceToObjects(&allobjects, komposeObject.Namespace)
+	kubernetes.AssignCommonMetadata(allobjects, opt)
 	// o.FixWorkloadVersion(&allobjects)
 
+	if opt.OutputFormat == OutputFormatTemplate {
+		template, err := o.initTemplate(komposeObject, allobjects, owners, opt)
+		if err != nil {
+			return nil, errors.Wrap(err, "initTemplate failed")
+		}
+		return []runtime.Object{template}, nil
+	}
+
 	return allobjects, nil
 }
@@ -28,6 +28,7 @@ import (
 	"github.com/kubernetes/kompose/pkg/transformer"
 	"github.com/kubernetes/kompose/pkg/transformer/kubernetes"
 	deployapi "github.com/openshift/api/apps/v1"
+	templateapi "github.com/openshift/api/template/v1"
 	"github.com/pkg/errors"
 	api "k8s.io/api/core/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -491,3 +492,79 @@ func TestNamespaceGeneration(t *testing.T) {
 		}
 	}
 }
+
+func TestTemplateOutputFormat(t *testing.T) {
+	service := newServiceConfig()
+	service.Image = "nginx:1.25"
+	service.Replicas = 2
+	service.ExposeService = "web.example.com"
+	komposeObject := kobject.KomposeObject{
+		ServiceConfigs:      map[string]kobject.ServiceConfig{"web": service},
+		UnresolvedVariables: []string{"DB_PASSWORD"},
+	}
+	o := OpenShift{}
+	objs, err := o.Transform(komposeObject, kobject.ConvertOptions{OutputFormat: OutputFormatTemplate, CreateDeploymentConfig: true, Replicas: 1})
+	if err != nil {
+		t.Fatal(errors.Wrap(err, "o.Transform failed"))
+	}
+	if len(objs) != 1 {
+		t.Fatalf("Expected a single Template, got %d objects", len(objs))
+	}
+	template, ok := objs[0].(*templateapi.Template)
+	if !ok {
+		t.Fatalf("Expected a Template, got %T", objs[0])
+	}
+
+	parameters := map[string]templateapi.Parameter{}
+	for _, parameter := range template.Parameters {
+		parameters[parameter.Name] = parameter
+	}
+	expected := map[string]templateapi.Parameter{
+		"DB_PASSWORD":    {Required: true},
+		"WEB_IMAGE_TAG":  {Value: "1.25"},
+		"WEB_REPLICAS":   {Value: "2"},
+		"WEB_ROUTE_HOST": {Value: "web.example.com"},
+	}
+	for name, want := range expected {
+		got, ok := parameters[name]
+		if !ok {
+			t.Errorf("Expected parameter %s, got %v", name, template.Parameters)
+			continue
+		}
+		if got.Value != want.Value || got.Required != want.Required {
+			t.Errorf("Expected parameter %s to have value %q and required %v, got %q and %v", name, want.Value, want.Required, got.Value, got.Required)
+		}
+	}
+
+	var objects []string
+	for _, raw := range template.Objects {
+		objects = append(objects, string(raw.Raw))
+	}
+	all := strings.Join(objects, "\n")
+	for _, reference := range []string{`"replicas":"${{WEB_REPLICAS}}"`, `"host":"${WEB_ROUTE_HOST}"`, `"name":"nginx:${WEB_IMAGE_TAG}"`, `"name":"web:${WEB_IMAGE_TAG}"`} {
+		if !strings.Contains(all, reference) {
+			t.Errorf("Expected the Template objects to contain %s, got %s", reference, all)
+		}
+	}
+}
+
+func TestTemplateSecretParameters(t *testing.T) {
+	object := map[string]interface{}{
+		"kind":     "Secret",
+		"metadata": map[string]interface{}{"name": "db-credentials"},
+		"data":     map[string]interface{}{"password": "czNjcjN0"},
+	}
+	params := &templateParameters{names: map[string]bool{}}
+	parameterizeSecret(object, params)
+
+	if _, ok := object["data"]; ok {
+		t.Errorf("Expected the secret data to be removed, got %v", object["data"])
+	}
+	expected := map[string]interface{}{"password": "${DB_CREDENTIALS_PASSWORD}"}
+	if !reflect.DeepEqual(object["stringData"], expected) {
+		t.Errorf("Expected stringData %v, got %v", expected, object["stringData"])
+	}
+	if len(params.parameters) != 1 || !params.parameters[0].Required || params.parameters[0].Value != "" {
+		t.Errorf("Expected a single required parameter without value, got %v", params.parameters)
+	}
+}
@@ -0,0 +1,53 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package openshift
+
+import (
+	"github.com/kubernetes/kompose/pkg/kobject"
+	"github.com/kubernetes/kompose/pkg/transformer"
+	"github.com/kubernetes/kompose/pkg/transformer/kubernetes"
+	"github.com/pkg/errors"
+)
+
+func init() {
+	transformer.RegisterProvider(transformer.Provider{
+		Name: "openshift",
+		New: func(opt kobject.ConvertOptions) transformer.Transformer {
+			// OpenShift inherits from Kubernetes
+			return &OpenShift{Kubernetes: kubernetes.Kubernetes{Opt: opt}}
+		},
+		Flags:         []string{"deployment-config", "build-repo", "build-branch"},
+		OutputFormats: append([]string{OutputFormatTemplate}, kubernetes.OutputFormats...),
+		Validate:      validateOptions,
+		SetDefaults:   setDefaultOptions,
+	})
+}
+
+// validateOptions checks that the convert options only use the OpenShift controllers
+func validateOptions(opt kobject.ConvertOptions) error {
+	switch opt.Controller {
+	case kubernetes.DaemonSetController, "replicationcontroller", kubernetes.DeploymentController, kubernetes.RolloutController:
+		return errors.New("--controller= daemonset, replicationcontroller, deployment or rollout is a Kubernetes only flag")
+	}
+	return nil
+}
+
+// setDefaultOptions generates a DeploymentConfig, the only controller of the OpenShift provider
+func setDefaultOptions(opt *kobject.ConvertOptions) error {
+	opt.CreateDeploymentConfig = true
+	return nil
+}
@@ -0,0 +1,235 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package openshift
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kubernetes/kompose/pkg/kobject"
+	"github.com/kubernetes/kompose/pkg/transformer"
+	templateapi "github.com/openshift/api/template/v1"
+	"github.com/pkg/errors"
+	kapi "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// OutputFormatTemplate wraps all the generated objects in a single OpenShift Template
+const OutputFormatTemplate = "template"
+
+var invalidParameterChars = regexp.MustCompile(`[^A-Z0-9_]`)
+
+var invalidTemplateNameChars = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// templateParameters collects the parameters of a Template, a parameter shared by
+// several objects (e.g. the replicas of a Deployment and of a DeploymentConfig) is added once
+type templateParameters struct {
+	parameters []templateapi.Parameter
+	names      map[string]bool
+}
+
+// add adds the parameter if it is not known yet and returns its name
+func (p *templateParameters) add(parameter templateapi.Parameter) string {
+	parameter.Name = parameterName(parameter.Name)
+	if !p.names[parameter.Name] {
+		p.names[parameter.Name] = true
+		p.parameters = append(p.parameters, parameter)
+	}
+	return parameter.Name
+}
+
+// parameterName returns name in the form accepted for Template parameters, e.g. WEB_IMAGE_TAG for web-image-tag
+func parameterName(name string) string {
+	return invalidParameterChars.ReplaceAllString(strings.ToUpper(name), "_")
+}
+
+// templateName returns the name of the Template, the name of the directory of the compose files
+func templateName(opt kobject.ConvertOptions) string {
+	name := "kompose"
+	if len(opt.InputFiles) == 0 {
+		return name
+	}
+	if dir, err := transformer.GetComposeFileDir(opt.InputFiles); err == nil {
+		if base := strings.Trim(invalidTemplateNameChars.ReplaceAllString(strings.ToLower(filepath.Base(dir)), "-"), "-"); base != "" {
+			name = base
+		}
+	}
+	return name
+}
+
+// initTemplate wraps objects in a Template. Image tags, replicas, route hostnames and secret values
+// become parameters, and the unresolved compose variables become required parameters.
+// owners maps the objects generated for a compose service to the name of the service.
+func (o *OpenShift) initTemplate(komposeObject kobject.KomposeObject, objects []runtime.Object, owners map[runtime.Object]string, opt kobject.ConvertOptions) (*templateapi.Template, error) {
+	params := &templateParameters{names: map[string]bool{}}
+
+	for _, name := range komposeObject.UnresolvedVariables {
+		params.add(templateapi.Parameter{
+			Name:        name,
+			Description: fmt.Sprintf("Value of the %s variable of the compose file", name),
+			Required:    true,
+		})
+	}
+
+	template := &templateapi.Template{
+		TypeMeta: kapi.TypeMeta{
+			Kind:       "Template",
+			APIVersion: "template.openshift.io/v1",
+		},
+		ObjectMeta: kapi.ObjectMeta{
+			Name: templateName(opt),
+		},
+		Objects: []runtime.RawExtension{},
+	}
+
+	for _, obj := range objects {
+		data, err := json.Marshal(obj)
+		if err != nil {
+			return nil, errors.Wrap(err, "json.Marshal failed")
+		}
+		object := map[string]interface{}{}
+		if err := json.Unmarshal(data, &object); err != nil {
+			return nil, errors.Wrap(err, "json.Unmarshal failed")
+		}
+
+		if name, ok := owners[obj]; ok {
+			parameterizeServiceObject(object, name, komposeObject.ServiceConfigs[name], params)
+		} else if object["kind"] == "Secret" {
+			parameterizeSecret(object, params)
+		}
+
+		raw, err := json.Marshal(object)
+		if err != nil {
+			return nil, errors.Wrap(err, "json.Marshal failed")
+		}
+		template.Objects = append(template.Objects, runtime.RawExtension{Raw: raw})
+	}
+
+	template.Parameters = params.parameters
+	return template, nil
+}
+
+// parameterizeServiceObject replaces the image tag, the replicas and the route hostname of an object
+// generated for the compose service name with parameters
+func parameterizeServiceObject(object map[string]interface{}, name string, service kobject.ServiceConfig, params *templateParameters) {
+	spec, _ := object["spec"].(map[string]interface{})
+	if spec == nil {
+		return
+	}
+
+	if replicas, ok := spec["replicas"].(float64); ok {
+		spec["replicas"] = "${{" + params.add(templateapi.Parameter{
+			Name:        name + "_REPLICAS",
+			Description: fmt.Sprintf("Number of replicas of %s", name),
+			Value:       strconv.FormatFloat(replicas, 'f', -1, 64),
+		}) + "}}"
+	}
+
+	if object["kind"] == "Route" {
+		host, _ := spec["host"].(string)
+		spec["host"] = "${" + params.add(templateapi.Parameter{
+			Name:        name + "_ROUTE_HOST",
+			Description: fmt.Sprintf("Hostname of the route of %s, leave empty to have one generated", name),
+			Value:       host,
+		}) + "}"
+	}
+
+	image := service.Image
+	if image == "" {
+		image = name
+	}
+	tag := GetImageTag(image)
+	if strings.Contains(image, "@") || strings.Contains(tag, "${") {
+		// Images referenced by digest have no tag to change, and a tag from a compose variable already is a parameter
+		return
+	}
+	repository := strings.TrimSuffix(image, ":"+tag)
+	tagParameter := "${" + params.add(templateapi.Parameter{
+		Name:        name + "_IMAGE_TAG",
+		Description: fmt.Sprintf("Tag of the %s image of %s", repository, name),
+		Value:       tag,
+	}) + "}"
+
+	if object["kind"] == "ImageStream" {
+		tags, _ := spec["tags"].([]interface{})
+		for _, t := range tags {
+			if t, ok := t.(map[string]interface{}); ok && t["name"] == tag {
+				t["name"] = tagParameter
+			}
+		}
+	}
+	replaceImageReferences(object, map[string]string{
+		image:            repository + ":" + tagParameter,
+		name + ":" + tag: name + ":" + tagParameter,
+	})
+}
+
+// parameterizeSecret moves the values of a Secret to stringData, each of them being a required parameter
+func parameterizeSecret(object map[string]interface{}, params *templateParameters) {
+	data, _ := object["data"].(map[string]interface{})
+	if len(data) == 0 {
+		return
+	}
+	metadata, _ := object["metadata"].(map[string]interface{})
+	name, _ := metadata["name"].(string)
+
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	stringData := map[string]interface{}{}
+	for _, key := range keys {
+		stringData[key] = "${" + params.add(templateapi.Parameter{
+			Name:        name + "_" + key,
+			Description: fmt.Sprintf("Value of the %s key of the %s secret", key, name),
+			Required:    true,
+		}) + "}"
+	}
+	delete(object, "data")
+	object["stringData"] = stringData
+}
+
+// replaceImageReferences replaces the images of the containers and the names of the DockerImage
+// and ImageStreamTag references found in value that are keys of replacements
+func replaceImageReferences(value interface{}, replacements map[string]string) {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		field := "image"
+		if value["kind"] == "DockerImage" || value["kind"] == "ImageStreamTag" {
+			field = "name"
+		}
+		if s, ok := value[field].(string); ok {
+			if replacement, ok := replacements[s]; ok {
+				value[field] = replacement
+			}
+		}
+		for _, v := range value {
+			replaceImageReferences(v, replacements)
+		}
+	case []interface{}:
+		for _, v := range value {
+			replaceImageReferences(v, replacements)
+		}
+	}
+}
@@ -0,0 +1,255 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package transformer
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// Patch is a document of the files of --patch, applied to the converted objects matching its target.
+// The patch is a strategic merge patch when it is an object, and a JSON 6902 patch when it is a list of operations.
+type Patch struct {
+	Target PatchTarget     `json:"target"`
+	Patch  json.RawMessage `json:"patch"`
+
+	// source is the file of the patch, and its index in the file, for the messages
+	source string
+}
+
+// PatchTarget selects the objects a patch applies to, the empty fields match every object
+type PatchTarget struct {
+	Kind          string `json:"kind,omitempty"`
+	Name          string `json:"name,omitempty"`
+	LabelSelector string `json:"labelSelector,omitempty"`
+
+	selector labels.Selector
+}
+
+// isJSONPatch returns true if the patch is a JSON 6902 patch
+func (p Patch) isJSONPatch() bool {
+	return bytes.HasPrefix(bytes.TrimSpace(p.Patch), []byte("["))
+}
+
+// ReadPatches reads the patches of the files, and of the YAML and JSON files of the directories in the
+// order of their names. A file holds one patch per document.
+func ReadPatches(paths []string) ([]Patch, error) {
+	var patches []Patch
+	for _, path := range paths {
+		files, err := patchFiles(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			filePatches, err := readPatchFile(file)
+			if err != nil {
+				return nil, err
+			}
+			patches = append(patches, filePatches...)
+		}
+	}
+	return patches, nil
+}
+
+// patchFiles returns the file, or the YAML and JSON files of the directory read recursively
+func patchFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read the patches")
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+	var files []string
+	err = filepath.Walk(path, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		switch strings.ToLower(filepath.Ext(file)) {
+		case ".yaml", ".yml", ".json":
+			if !info.IsDir() {
+				files = append(files, file)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read the patches of %s", path)
+	}
+	return files, nil
+}
+
+// readPatchFile reads the patches of the documents of the file and checks them
+func readPatchFile(file string) ([]Patch, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read the patches")
+	}
+
+	var patches []Patch
+	decoder := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), 4096)
+	for i := 1; ; i++ {
+		var patch Patch
+		if err := decoder.Decode(&patch); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse the patch %d of %s", i, file)
+		}
+		patch.source = file
+		if i > 1 {
+			patch.source = file + "#" + strconv.Itoa(i)
+		}
+
+		trimmed := bytes.TrimSpace(patch.Patch)
+		if len(trimmed) == 0 || bytes.Equal(trimmed, []byte("null")) {
+			return nil, errors.Errorf("the patch %s has no patch", patch.source)
+		}
+		if patch.isJSONPatch() {
+			operations, err := jsonpatch.DecodePatch(patch.Patch)
+			if err == nil {
+				err = validateOperations(operations)
+			}
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid JSON 6902 patch %s", patch.source)
+			}
+		} else if !bytes.HasPrefix(trimmed, []byte("{")) {
+			return nil, errors.Errorf("the patch %s is neither a strategic merge patch nor a JSON 6902 patch", patch.source)
+		}
+		patch.Target.selector = labels.Everything()
+		if patch.Target.LabelSelector != "" {
+			patch.Target.selector, err = labels.Parse(patch.Target.LabelSelector)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid label selector of the patch %s", patch.source)
+			}
+		}
+		patches = append(patches, patch)
+	}
+	return patches, nil
+}
+
+// validateOperations checks the operations of a JSON 6902 patch, jsonpatch only checks them when it applies them
+func validateOperations(operations jsonpatch.Patch) error {
+	for i, operation := range operations {
+		if _, err := operation.Path(); err != nil {
+			return errors.Wrapf(err, "operation %d", i+1)
+		}
+		switch operation.Kind() {
+		case "add", "remove", "replace", "test":
+		case "move", "copy":
+			if _, err := operation.From(); err != nil {
+				return errors.Wrapf(err, "operation %d", i+1)
+			}
+		default:
+			return errors.Errorf("operation %d: unknown op %q", i+1, operation.Kind())
+		}
+	}
+	return nil
+}
+
+// matches returns true if the object is a target of the patch
+func (t PatchTarget) matches(object runtime.Object) bool {
+	if t.Kind != "" && !strings.EqualFold(t.Kind, object.GetObjectKind().GroupVersionKind().Kind) {
+		return false
+	}
+	accessor, err := meta.Accessor(object)
+	if err != nil {
+		return false
+	}
+	if t.Name != "" && t.Name != accessor.GetName() {
+		return false
+	}
+	return t.selector.Matches(labels.Set(accessor.GetLabels()))
+}
+
+// ApplyPatches applies the patches of the files and directories of paths to the objects matching their targets,
+// in the order of the patches
+func ApplyPatches(objects []runtime.Object, paths []string) ([]runtime.Object, error) {
+	patches, err := ReadPatches(paths)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, patch := range patches {
+		matched := false
+		for i, object := range objects {
+			if !patch.Target.matches(object) {
+				continue
+			}
+			matched = true
+			objects[i], err = applyPatch(object, patch)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if !matched {
+			log.Warnf("The patch %s matches no converted object", patch.source)
+		}
+	}
+	return objects, nil
+}
+
+// applyPatch returns the patched copy of the object, of the same type
+func applyPatch(object runtime.Object, patch Patch) (runtime.Object, error) {
+	accessor, _ := meta.Accessor(object)
+	name := object.GetObjectKind().GroupVersionKind().Kind + " " + accessor.GetName()
+
+	data, err := json.Marshal(object)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to marshal %s", name)
+	}
+	_, unstructuredObject := object.(runtime.Unstructured)
+	switch {
+	case patch.isJSONPatch():
+		operations, _ := jsonpatch.DecodePatch(patch.Patch)
+		data, err = operations.Apply(data)
+	case unstructuredObject:
+		// The kinds without a Go type have no patch strategy, their lists are replaced
+		data, err = jsonpatch.MergePatch(data, patch.Patch)
+	default:
+		data, err = strategicpatch.StrategicMergePatch(data, patch.Patch, object)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to apply the patch %s to %s", patch.source, name)
+	}
+
+	var patched runtime.Object
+	if unstructuredObject {
+		patched = &unstructured.Unstructured{}
+	} else {
+		patched = reflect.New(reflect.TypeOf(object).Elem()).Interface().(runtime.Object)
+	}
+	if err := json.Unmarshal(data, patched); err != nil {
+		return nil, errors.Wrapf(err, "the patch %s makes %s invalid", patch.source, name)
+	}
+	return patched, nil
+}
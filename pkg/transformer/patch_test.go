@@ -0,0 +1,135 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package transformer
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	api "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const sidecarPatch = `target:
+  kind: Deployment
+  labelSelector: io.kompose.service=web
+patch:
+  spec:
+    template:
+      spec:
+        containers:
+          - name: proxy
+            image: envoy
+`
+
+const tolerationPatch = `target:
+  kind: deployment
+patch:
+  - op: add
+    path: /spec/template/spec/tolerations
+    value: [{key: dedicated, operator: Exists}]
+---
+target:
+  kind: HTTPRoute
+  name: web
+patch:
+  metadata:
+    labels:
+      tier: frontend
+`
+
+func writePatches(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func newDeployment(name string) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		TypeMeta:   metav1.TypeMeta{Kind: "Deployment", APIVersion: "apps/v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{Selector: name}},
+		Spec: appsv1.DeploymentSpec{
+			Template: api.PodTemplateSpec{
+				Spec: api.PodSpec{Containers: []api.Container{{Name: name, Image: name}}},
+			},
+		},
+	}
+}
+
+func TestApplyPatches(t *testing.T) {
+	dir := writePatches(t, map[string]string{"a-sidecar.yaml": sidecarPatch, "b-tolerations.yml": tolerationPatch, "README.md": "not a patch"})
+	route := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "gateway.networking.k8s.io/v1",
+		"kind":       "HTTPRoute",
+		"metadata":   map[string]interface{}{"name": "web"},
+	}}
+	objects := []runtime.Object{newDeployment("web"), newDeployment("db"), route}
+
+	objects, err := ApplyPatches(objects, []string{dir})
+	if err != nil {
+		t.Fatalf("ApplyPatches failed: %v", err)
+	}
+
+	web := objects[0].(*appsv1.Deployment)
+	var containers []string
+	for _, container := range web.Spec.Template.Spec.Containers {
+		containers = append(containers, container.Name)
+	}
+	if !reflect.DeepEqual(containers, []string{"proxy", "web"}) {
+		t.Errorf("Expected the sidecar to be merged with the containers of web, got %v", containers)
+	}
+	db := objects[1].(*appsv1.Deployment)
+	if len(db.Spec.Template.Spec.Containers) != 1 {
+		t.Errorf("Expected the sidecar patch to leave db unchanged, got %v", db.Spec.Template.Spec.Containers)
+	}
+	for _, deployment := range []*appsv1.Deployment{web, db} {
+		expected := []api.Toleration{{Key: "dedicated", Operator: api.TolerationOpExists}}
+		if !reflect.DeepEqual(deployment.Spec.Template.Spec.Tolerations, expected) {
+			t.Errorf("Expected the tolerations %v on %s, got %v", expected, deployment.Name, deployment.Spec.Template.Spec.Tolerations)
+		}
+	}
+	if labels := objects[2].(*unstructured.Unstructured).GetLabels(); labels["tier"] != "frontend" {
+		t.Errorf("Expected the HTTPRoute to be patched, got the labels %v", labels)
+	}
+}
+
+func TestReadPatchesError(t *testing.T) {
+	testCases := map[string]string{
+		"no patch":           "target:\n  kind: Service\n",
+		"scalar patch":       "target:\n  kind: Service\npatch: replicas\n",
+		"invalid operation":  "patch:\n  - op: move\n",
+		"invalid selector":   "target:\n  labelSelector: 'a in (b'\npatch: {}\n",
+		"invalid yaml":       "target: [\n",
+		"unknown operations": "patch:\n  - {op: rename, path: /a}\n",
+	}
+
+	for name, content := range testCases {
+		dir := writePatches(t, map[string]string{"patch.yaml": content})
+		if _, err := ReadPatches([]string{filepath.Join(dir, "patch.yaml")}); err == nil {
+			t.Errorf("Case '%v' for TestReadPatchesError fail, Expected an error", name)
+		}
+	}
+}
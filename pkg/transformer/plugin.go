@@ -0,0 +1,135 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package transformer
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"os/exec"
+	"reflect"
+
+	"github.com/kubernetes/kompose/pkg/kobject"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// PluginInput is the JSON document written to the stdin of the plugins of --plugin: the converted objects
+// as a v1 List, and the kompose object they were converted from
+type PluginInput struct {
+	APIVersion    string                `json:"apiVersion"`
+	Kind          string                `json:"kind"`
+	Items         []runtime.Object      `json:"items"`
+	KomposeObject kobject.KomposeObject `json:"komposeObject"`
+}
+
+// RunPlugins runs the plugins in order, each one reads the objects on its stdin and writes the objects to
+// convert on its stdout, as a List or as documents, in YAML or JSON. The objects of the kinds given to the
+// plugin keep their Go type, the other ones are unstructured.
+func RunPlugins(objects []runtime.Object, komposeObject kobject.KomposeObject, plugins []string) ([]runtime.Object, error) {
+	for _, plugin := range plugins {
+		var err error
+		objects, err = runPlugin(plugin, objects, komposeObject)
+		if err != nil {
+			return nil, errors.Wrapf(err, "plugin %s failed", plugin)
+		}
+	}
+	return objects, nil
+}
+
+// runPlugin pipes the objects to the plugin and reads the objects it writes, its stderr is the one of kompose
+func runPlugin(plugin string, objects []runtime.Object, komposeObject kobject.KomposeObject) ([]runtime.Object, error) {
+	input, err := json.Marshal(PluginInput{APIVersion: "v1", Kind: "List", Items: objects, KomposeObject: komposeObject})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal the objects")
+	}
+
+	log.Debugf("Running the plugin %s", plugin)
+	var stdout bytes.Buffer
+	cmd := exec.Command(plugin)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return nil, err
+	}
+	if len(bytes.TrimSpace(stdout.Bytes())) == 0 {
+		return nil, errors.New("no objects were written to stdout")
+	}
+
+	types := map[schema.GroupVersionKind]reflect.Type{}
+	for _, object := range objects {
+		if _, ok := object.(runtime.Unstructured); !ok {
+			types[object.GetObjectKind().GroupVersionKind()] = reflect.TypeOf(object).Elem()
+		}
+	}
+
+	var output []runtime.Object
+	decoder := yaml.NewYAMLOrJSONDecoder(&stdout, 4096)
+	for {
+		document := &unstructured.Unstructured{}
+		if err := decoder.Decode(&document.Object); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, errors.Wrap(err, "failed to parse the objects written to stdout")
+		}
+		if len(document.Object) == 0 {
+			continue
+		}
+		var items []*unstructured.Unstructured
+		if document.IsList() {
+			err = document.EachListItem(func(item runtime.Object) error {
+				items = append(items, item.(*unstructured.Unstructured))
+				return nil
+			})
+		} else {
+			items = append(items, document)
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse the objects written to stdout")
+		}
+		for _, item := range items {
+			object, err := typedObject(item, types)
+			if err != nil {
+				return nil, err
+			}
+			output = append(output, object)
+		}
+	}
+	return output, nil
+}
+
+// typedObject returns the object as a value of the Go type of its kind, or unstructured for the unknown kinds
+func typedObject(item *unstructured.Unstructured, types map[schema.GroupVersionKind]reflect.Type) (runtime.Object, error) {
+	if item.GetKind() == "" || item.GetAPIVersion() == "" {
+		return nil, errors.Errorf("the object %q written to stdout has no apiVersion or kind", item.GetName())
+	}
+	objectType, ok := types[item.GroupVersionKind()]
+	if !ok {
+		return item, nil
+	}
+	object := reflect.New(objectType).Interface().(runtime.Object)
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(item.Object, object); err != nil {
+		return nil, errors.Wrapf(err, "invalid %s %q written to stdout", item.GetKind(), item.GetName())
+	}
+	return object, nil
+}
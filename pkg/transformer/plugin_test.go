@@ -0,0 +1,109 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package transformer
+
+import (
+	"os"
+	"path/filepath"
+	goruntime "runtime"
+	"strings"
+	"testing"
+
+	"github.com/kubernetes/kompose/pkg/kobject"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// writePlugin writes a shell script plugin to a temporary directory and returns its path
+func writePlugin(t *testing.T, script string) string {
+	plugin := filepath.Join(t.TempDir(), "plugin")
+	if err := os.WriteFile(plugin, []byte("#!/bin/sh\n"+script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return plugin
+}
+
+func TestRunPlugins(t *testing.T) {
+	if goruntime.GOOS == "windows" {
+		t.Skip("the test plugins are shell scripts")
+	}
+	komposeObject := kobject.KomposeObject{ServiceConfigs: map[string]kobject.ServiceConfig{"web": {Image: "nginx"}}}
+	// The input of the plugins is a List, it is written back as is
+	identity := writePlugin(t, "cat\n")
+	// The service names are read from the kompose object of the input
+	replace := writePlugin(t, `grep -q '"ServiceConfigs":{"web"' || exit 1
+cat <<EOF
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+        - name: web
+          image: mirror.example.com/nginx
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: web-mirror
+EOF
+`)
+
+	objects, err := RunPlugins([]runtime.Object{newDeployment("web")}, komposeObject, []string{identity, replace})
+	if err != nil {
+		t.Fatalf("RunPlugins failed: %v", err)
+	}
+	if len(objects) != 2 {
+		t.Fatalf("Expected the 2 objects written by the last plugin, got %v", objects)
+	}
+	deployment, ok := objects[0].(*appsv1.Deployment)
+	if !ok {
+		t.Fatalf("Expected the Deployment to keep its Go type, got %T", objects[0])
+	}
+	if image := deployment.Spec.Template.Spec.Containers[0].Image; image != "mirror.example.com/nginx" {
+		t.Errorf("Expected the image written by the plugin, got %s", image)
+	}
+	if configMap, ok := objects[1].(*unstructured.Unstructured); !ok || configMap.GetName() != "web-mirror" {
+		t.Errorf("Expected the ConfigMap added by the plugin as an unstructured object, got %#v", objects[1])
+	}
+}
+
+func TestRunPluginsError(t *testing.T) {
+	if goruntime.GOOS == "windows" {
+		t.Skip("the test plugins are shell scripts")
+	}
+	testCases := map[string]struct {
+		script   string
+		expected string
+	}{
+		"exit status": {"echo failed >&2\nexit 2\n", "exit status 2"},
+		"no output":   {"cat > /dev/null\n", "no objects were written to stdout"},
+		"no kind":     {`echo '{"apiVersion": "v1", "metadata": {"name": "web"}}'` + "\n", "has no apiVersion or kind"},
+		"invalid":     {"echo '{'\n", "failed to parse the objects"},
+	}
+
+	for name, test := range testCases {
+		plugin := writePlugin(t, test.script)
+		_, err := RunPlugins([]runtime.Object{newDeployment("web")}, kobject.KomposeObject{}, []string{plugin})
+		if err == nil || !strings.Contains(err.Error(), test.expected) || !strings.Contains(err.Error(), plugin) {
+			t.Errorf("Case '%v' for TestRunPluginsError fail, Expected an error of the plugin with %q, got %v", name, test.expected, err)
+		}
+	}
+}
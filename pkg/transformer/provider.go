@@ -0,0 +1,97 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package transformer
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/kubernetes/kompose/pkg/kobject"
+)
+
+// Provider is a target of the conversion, selected with --provider. The providers join the registry with
+// RegisterProvider when their package is initialized.
+type Provider struct {
+	// Name is the value of --provider selecting the provider
+	Name string
+	// New returns the transformer of the provider for the convert options
+	New func(opt kobject.ConvertOptions) Transformer
+	// Flags are the names of the convert flags that only apply to the provider, they are rejected with the other providers
+	Flags []string
+	// OutputFormats are the values of --output-format the provider writes, they are rejected with the other providers
+	OutputFormats []string
+	// Validate checks the convert options for the provider once the flags are parsed, it may be nil
+	Validate func(opt kobject.ConvertOptions) error
+	// SetDefaults sets the options the provider derives from the others before the conversion,
+	// e.g. the controller generated by default, it may be nil
+	SetDefaults func(opt *kobject.ConvertOptions) error
+}
+
+var (
+	providersMutex sync.RWMutex
+	// providers are the registered providers, in the order of their registration
+	providers []Provider
+)
+
+// RegisterProvider makes a provider available to --provider. It panics if the provider has no name or transformer,
+// or if its name is already registered.
+func RegisterProvider(provider Provider) {
+	providersMutex.Lock()
+	defer providersMutex.Unlock()
+
+	if provider.Name == "" || provider.New == nil {
+		panic("transformer: RegisterProvider needs a name and a transformer")
+	}
+	for _, p := range providers {
+		if p.Name == provider.Name {
+			panic(fmt.Sprintf("transformer: provider %s is already registered", provider.Name))
+		}
+	}
+	providers = append(providers, provider)
+}
+
+// GetProvider returns the provider registered under name
+func GetProvider(name string) (Provider, error) {
+	providersMutex.RLock()
+	defer providersMutex.RUnlock()
+
+	for _, p := range providers {
+		if p.Name == name {
+			return p, nil
+		}
+	}
+	return Provider{}, fmt.Errorf("%s is an unsupported provider. Supported providers are: %s", name, providerNames())
+}
+
+// Providers returns the registered providers, in the order of their registration
+func Providers() []Provider {
+	providersMutex.RLock()
+	defer providersMutex.RUnlock()
+	return append([]Provider(nil), providers...)
+}
+
+// providerNames returns the quoted names of the providers, e.g. 'kubernetes', 'openshift'
+func providerNames() string {
+	names := ""
+	for i, p := range providers {
+		if i > 0 {
+			names += ", "
+		}
+		names += "'" + p.Name + "'"
+	}
+	return names
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package transformer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kubernetes/kompose/pkg/kobject"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// nomad is the transformer of a provider registered by the tests
+type nomad struct{}
+
+func (n *nomad) Transform(komposeObject kobject.KomposeObject, opt kobject.ConvertOptions) ([]runtime.Object, error) {
+	return nil, nil
+}
+
+func TestRegisterProvider(t *testing.T) {
+	RegisterProvider(Provider{
+		Name:  "nomad",
+		New:   func(opt kobject.ConvertOptions) Transformer { return &nomad{} },
+		Flags: []string{"datacenter"},
+	})
+
+	provider, err := GetProvider("nomad")
+	if err != nil {
+		t.Fatalf("GetProvider failed: %v", err)
+	}
+	if _, ok := provider.New(kobject.ConvertOptions{}).(*nomad); !ok {
+		t.Errorf("Expected the transformer of the nomad provider")
+	}
+	if providers := Providers(); providers[len(providers)-1].Name != "nomad" {
+		t.Errorf("Expected the providers in the order of their registration, got %v", providers)
+	}
+	if _, err := GetProvider("swarm"); err == nil || !strings.Contains(err.Error(), "'nomad'") {
+		t.Errorf("Expected an error listing the supported providers, got %v", err)
+	}
+
+	testCases := map[string]Provider{
+		"no name":        {New: provider.New},
+		"no transformer": {Name: "swarm"},
+		"registered":     provider,
+	}
+	for name, test := range testCases {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Case '%v' for TestRegisterProvider fail, Expected RegisterProvider to panic", name)
+				}
+			}()
+			RegisterProvider(test)
+		}()
+	}
+}
@@ -19,9 +19,9 @@ package transformer
 import (
 	"fmt"
 	"os"
-	"os/exec"
 	"path"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	dockerlib "github.com/fsouza/go-dockerclient"
@@ -33,6 +33,7 @@ import (
 	api "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
 // Selector used as labels and selector
@@ -255,30 +256,57 @@ func ConfigAnnotations(service kobject.ServiceConfig) map[string]string {
 		return annotations
 	}
 
-	annotations["kompose.cmd"] = strings.Join(os.Args, " ")
-	versionCmd := exec.Command("kompose", "version")
-	out, err := versionCmd.Output()
-	if err != nil {
-		errors.Wrap(err, "Failed to get kompose version")
+	// The command line changes with the invocation, e.g. the path to kompose or the order of the flags
+	if !service.StableOutput {
+		annotations["kompose.cmd"] = strings.Join(os.Args, " ")
+	}
+	annotations["kompose.version"] = version.VERSION + " (" + version.GITCOMMIT + ")"
+
+	return annotations
+}
+
+// ValidateCommonMetadata checks the labels and annotations added to every object, the io.kompose. labels
+// are set by kompose and used by its selectors
+func ValidateCommonMetadata(labels, annotations map[string]string) error {
+	for _, key := range sortedKeys(labels) {
+		if strings.HasPrefix(key, "io.kompose.") {
+			return errors.Errorf("the label %s is set by kompose", key)
+		}
+		if errs := validation.IsQualifiedName(key); len(errs) != 0 {
+			return errors.Errorf("invalid label %s: %s", key, strings.Join(errs, ", "))
+		}
+		if errs := validation.IsValidLabelValue(labels[key]); len(errs) != 0 {
+			return errors.Errorf("invalid value of the label %s: %s", key, strings.Join(errs, ", "))
+		}
+	}
+	for _, key := range sortedKeys(annotations) {
+		if errs := validation.IsQualifiedName(key); len(errs) != 0 {
+			return errors.Errorf("invalid annotation %s: %s", key, strings.Join(errs, ", "))
+		}
 	}
-	annotations["kompose.version"] = strings.Trim(string(out), " \n")
+	return nil
+}
 
-	// If the version is blank (couldn't retrieve the kompose version for whatever reason)
-	if annotations["kompose.version"] == "" {
-		annotations["kompose.version"] = version.VERSION + " (" + version.GITCOMMIT + ")"
+func sortedKeys(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
 	}
+	sort.Strings(keys)
+	return keys
+}
 
-	return annotations
+// FileName returns the name of the file of an object, e.g. web-deployment.yaml for the deployment web
+func FileName(name, trailing string, generateJSON bool) string {
+	if generateJSON {
+		return fmt.Sprintf("%s-%s.json", name, trailing)
+	}
+	return fmt.Sprintf("%s-%s.yaml", name, trailing)
 }
 
 // Print either prints to stdout or to file/s
 func Print(name, path string, trailing string, data []byte, toStdout, generateJSON bool, f *os.File, provider string) (string, error) {
-	file := ""
-	if generateJSON {
-		file = fmt.Sprintf("%s-%s.json", name, trailing)
-	} else {
-		file = fmt.Sprintf("%s-%s.yaml", name, trailing)
-	}
+	file := FileName(name, trailing, generateJSON)
 	if toStdout {
 		fmt.Fprintf(os.Stdout, "%s\n", string(data))
 		return "", nil
@@ -305,6 +333,8 @@ func formatProviderName(provider string) string {
 		return "OpenShift"
 	} else if strings.EqualFold(provider, "kubernetes") {
 		return "Kubernetes"
+	} else if strings.EqualFold(provider, "knative") {
+		return "Knative"
 	}
 	return provider
 }
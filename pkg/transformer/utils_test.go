@@ -348,3 +348,25 @@ func TestGetComposeFileDir(t *testing.T) {
 		t.Errorf("Expected $PWD/foobar, got %v", output)
 	}
 }
+
+func TestValidateCommonMetadata(t *testing.T) {
+	testCases := map[string]struct {
+		labels      map[string]string
+		annotations map[string]string
+		expectError bool
+	}{
+		"valid":                     {map[string]string{"team": "shop", "example.com/cost-center": ""}, map[string]string{"owner": "shop team <shop@example.com>"}, false},
+		"kompose label":             {map[string]string{"io.kompose.service": "web"}, nil, true},
+		"invalid label key":         {map[string]string{"team/": "shop"}, nil, true},
+		"invalid label value":       {map[string]string{"team": "shop team"}, nil, true},
+		"invalid annotation":        {nil, map[string]string{"-owner": "shop"}, true},
+		"no labels nor annotations": {nil, nil, false},
+	}
+
+	for name, test := range testCases {
+		err := ValidateCommonMetadata(test.labels, test.annotations)
+		if (err != nil) != test.expectError {
+			t.Errorf("Case '%v' for TestValidateCommonMetadata fail, Expected error %v, got %v", name, test.expectError, err)
+		}
+	}
+}
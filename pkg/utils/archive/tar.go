@@ -18,6 +18,7 @@ package archive
 
 import (
 	"archive/tar"
+	"compress/gzip"
 	"io"
 	"os"
 	"path/filepath"
@@ -25,7 +26,8 @@ import (
 )
 
 /*
-CreateTarball creates a tarball for source and dumps it to target path
+CreateTarball creates a tarball for source and dumps it to target path.
+The tarball is gzip compressed when target ends with ".tgz" or ".tar.gz".
 
 Function modified and added from https://github.com/mholt/archiver/blob/master/tar.go
 */
@@ -36,8 +38,8 @@ func CreateTarball(source, target string) error {
 	}
 	defer tarfile.Close()
 
-	tarball := tar.NewWriter(tarfile)
-	defer tarball.Close()
+	tarball, closeTarball := newTarWriter(tarfile, target)
+	defer closeTarball()
 
 	info, err := os.Stat(source)
 	if err != nil {
@@ -88,3 +90,20 @@ func CreateTarball(source, target string) error {
 			return err
 		})
 }
+
+// newTarWriter returns a tar writer to out, gzip compressed when target ends with ".tgz" or ".tar.gz",
+// and the function closing it
+func newTarWriter(out io.Writer, target string) (*tar.Writer, func() error) {
+	if !strings.HasSuffix(target, ".tgz") && !strings.HasSuffix(target, ".tar.gz") {
+		tarball := tar.NewWriter(out)
+		return tarball, tarball.Close
+	}
+	gz := gzip.NewWriter(out)
+	tarball := tar.NewWriter(gz)
+	return tarball, func() error {
+		if err := tarball.Close(); err != nil {
+			return err
+		}
+		return gz.Close()
+	}
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// modTime is the modification time of the archived files, fixed so that the same files
+// always give the same archive. It is the earliest time a zip archive can hold.
+var modTime = time.Date(1980, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// IsArchive returns true if name is a tar, gzip compressed tar or zip archive
+func IsArchive(name string) bool {
+	for _, extension := range []string{".tar", ".tar.gz", ".tgz", ".zip"} {
+		if strings.HasSuffix(name, extension) {
+			return true
+		}
+	}
+	return false
+}
+
+type archiveFile struct {
+	name string
+	data []byte
+	mode os.FileMode
+}
+
+// Writer collects files in memory and writes them to target as a tar, gzip compressed tar
+// or zip archive, depending on the extension of target, when it is closed
+type Writer struct {
+	target string
+	files  []archiveFile
+}
+
+// NewWriter returns a Writer of the archive target
+func NewWriter(target string) *Writer {
+	return &Writer{target: target}
+}
+
+// MkdirAll does nothing, the directories of an archive are the paths of its files
+func (w *Writer) MkdirAll(path string, perm os.FileMode) error {
+	return nil
+}
+
+// WriteFile adds the file name to the archive
+func (w *Writer) WriteFile(name string, data []byte, perm os.FileMode) error {
+	w.files = append(w.files, archiveFile{
+		name: path.Clean(filepath.ToSlash(name)),
+		data: data,
+		mode: perm,
+	})
+	return nil
+}
+
+// Close writes the archive to its target
+func (w *Writer) Close() error {
+	var buf bytes.Buffer
+	var err error
+	if strings.HasSuffix(w.target, ".zip") {
+		err = w.writeZip(&buf)
+	} else {
+		err = w.writeTar(&buf)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to create the archive %s", w.target)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(w.target), os.ModePerm); err != nil {
+		return errors.Wrap(err, "failed to create directories")
+	}
+	return os.WriteFile(w.target, buf.Bytes(), 0644)
+}
+
+func (w *Writer) writeTar(buf *bytes.Buffer) error {
+	tarball, closeTarball := newTarWriter(buf, w.target)
+	for _, file := range w.files {
+		header := &tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     file.name,
+			Mode:     int64(file.mode),
+			Size:     int64(len(file.data)),
+			ModTime:  modTime,
+		}
+		if err := tarball.WriteHeader(header); err != nil {
+			return err
+		}
+		if _, err := tarball.Write(file.data); err != nil {
+			return err
+		}
+	}
+	return closeTarball()
+}
+
+func (w *Writer) writeZip(buf *bytes.Buffer) error {
+	archive := zip.NewWriter(buf)
+	for _, file := range w.files {
+		header := &zip.FileHeader{
+			Name:     file.name,
+			Method:   zip.Deflate,
+			Modified: modTime,
+		}
+		header.SetMode(file.mode)
+		writer, err := archive.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+		if _, err := writer.Write(file.data); err != nil {
+			return err
+		}
+	}
+	return archive.Close()
+}
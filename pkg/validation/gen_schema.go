@@ -0,0 +1,189 @@
+//go:build ignore
+
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// gen_schema writes schemas/openapi.json, the definitions of the objects kompose generates in the OpenAPI format,
+// reflected from the API types kompose is built with: only the fields, their types and the required ones, without
+// the enums, patterns and formats of the values. Run it with go generate after updating k8s.io/api.
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"reflect"
+	"strings"
+
+	appsv1 "github.com/openshift/api/apps/v1"
+	buildv1 "github.com/openshift/api/build/v1"
+	imagev1 "github.com/openshift/api/image/v1"
+	routev1 "github.com/openshift/api/route/v1"
+	templatev1 "github.com/openshift/api/template/v1"
+	k8sappsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// objects are the objects kompose generates, with their group and version
+var objects = map[schema.GroupVersion][]runtime.Object{
+	{Version: "v1"}: {
+		&corev1.ConfigMap{}, &corev1.Namespace{}, &corev1.PersistentVolumeClaim{}, &corev1.Pod{},
+		&corev1.ReplicationController{}, &corev1.Secret{}, &corev1.Service{}, &corev1.ServiceAccount{},
+	},
+	{Group: "apps", Version: "v1"}:                  {&k8sappsv1.DaemonSet{}, &k8sappsv1.Deployment{}, &k8sappsv1.StatefulSet{}},
+	{Group: "autoscaling", Version: "v2"}:           {&autoscalingv2.HorizontalPodAutoscaler{}},
+	{Group: "batch", Version: "v1"}:                 {&batchv1.CronJob{}, &batchv1.Job{}},
+	{Group: "networking.k8s.io", Version: "v1"}:     {&networkingv1.Ingress{}, &networkingv1.NetworkPolicy{}},
+	{Group: "policy", Version: "v1"}:                {&policyv1.PodDisruptionBudget{}},
+	{Group: "apps.openshift.io", Version: "v1"}:     {&appsv1.DeploymentConfig{}},
+	{Group: "build.openshift.io", Version: "v1"}:    {&buildv1.BuildConfig{}},
+	{Group: "image.openshift.io", Version: "v1"}:    {&imagev1.ImageStream{}},
+	{Group: "route.openshift.io", Version: "v1"}:    {&routev1.Route{}},
+	{Group: "template.openshift.io", Version: "v1"}: {&templatev1.Template{}},
+}
+
+type property struct {
+	Type                 string               `json:"type,omitempty"`
+	Format               string               `json:"format,omitempty"`
+	Ref                  string               `json:"$ref,omitempty"`
+	Items                *property            `json:"items,omitempty"`
+	AdditionalProperties *property            `json:"additionalProperties,omitempty"`
+	Properties           map[string]*property `json:"properties,omitempty"`
+	Required             []string             `json:"required,omitempty"`
+	IntOrString          bool                 `json:"x-kubernetes-int-or-string,omitempty"`
+	GroupVersionKind     []map[string]string  `json:"x-kubernetes-group-version-kind,omitempty"`
+}
+
+type openAPISchemaType interface {
+	OpenAPISchemaType() []string
+}
+
+type openAPISchemaFormat interface {
+	OpenAPISchemaFormat() string
+}
+
+var definitions = map[string]*property{}
+
+// definitionName returns the name of the definition of a type like the Kubernetes OpenAPI,
+// e.g. io.k8s.api.apps.v1.Deployment for the Deployment of k8s.io/api/apps/v1
+func definitionName(t reflect.Type) string {
+	parts := strings.Split(t.PkgPath(), "/")
+	domain := strings.Split(parts[0], ".")
+	for i, j := 0, len(domain)-1; i < j; i, j = i+1, j-1 {
+		domain[i], domain[j] = domain[j], domain[i]
+	}
+	return strings.Join(append(domain, append(parts[1:], t.Name())...), ".")
+}
+
+func schemaOf(t reflect.Type) *property {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if v, ok := reflect.New(t).Elem().Interface().(openAPISchemaType); ok {
+		p := &property{Type: v.OpenAPISchemaType()[0]}
+		if f, ok := v.(openAPISchemaFormat); ok {
+			p.Format = f.OpenAPISchemaFormat()
+		}
+		if p.Format == "int-or-string" {
+			p.Type, p.Format, p.IntOrString = "", "", true
+		}
+		return p
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &property{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &property{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &property{Type: "number"}
+	case reflect.String:
+		return &property{Type: "string"}
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &property{Type: "string", Format: "byte"}
+		}
+		return &property{Type: "array", Items: schemaOf(t.Elem())}
+	case reflect.Map:
+		return &property{Type: "object", AdditionalProperties: schemaOf(t.Elem())}
+	case reflect.Interface:
+		return &property{}
+	case reflect.Struct:
+		name := definitionName(t)
+		if _, ok := definitions[name]; !ok {
+			// RawExtension and FieldsV1 are free-form objects
+			definitions[name] = &property{Type: "object"}
+			if t.NumField() > 0 && t.Field(0).Name != "Raw" {
+				definitions[name] = structSchema(t)
+			}
+		}
+		return &property{Ref: "#/definitions/" + name}
+	}
+	log.Fatalf("unsupported type %s", t)
+	return nil
+}
+
+func structSchema(t reflect.Type) *property {
+	p := &property{Type: "object", Properties: map[string]*property{}}
+	var add func(t reflect.Type)
+	add = func(t reflect.Type) {
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			tag := strings.Split(field.Tag.Get("json"), ",")
+			if tag[0] == "-" || !field.IsExported() {
+				continue
+			}
+			if field.Anonymous && tag[0] == "" {
+				add(field.Type)
+				continue
+			}
+			p.Properties[tag[0]] = schemaOf(field.Type)
+			if len(tag) == 1 && field.Type.Kind() != reflect.Ptr {
+				p.Required = append(p.Required, tag[0])
+			}
+		}
+	}
+	add(t)
+	return p
+}
+
+func main() {
+	for gv, objs := range objects {
+		for _, obj := range objs {
+			t := reflect.TypeOf(obj).Elem()
+			schemaOf(t)
+			definitions[definitionName(t)].GroupVersionKind = []map[string]string{{"group": gv.Group, "version": gv.Version, "kind": t.Name()}}
+		}
+	}
+
+	data, err := json.MarshalIndent(map[string]interface{}{
+		"swagger":     "2.0",
+		"info":        map[string]string{"title": "Kubernetes", "version": "v1.28.1"},
+		"definitions": definitions,
+	}, "", " ")
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile("schemas/openapi.json", append(data, '\n'), 0644); err != nil {
+		log.Fatal(err)
+	}
+}
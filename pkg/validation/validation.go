@@ -0,0 +1,467 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package validation checks the objects kompose generates offline, against the structure of their API types
+// bundled with kompose and the rules the API server applies to names, labels, quantities and required fields.
+package validation
+
+import (
+	_ "embed" // the OpenAPI definitions are embedded
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	apivalidation "k8s.io/apimachinery/pkg/api/validation"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+//go:generate go run gen_schema.go
+
+//go:embed schemas/openapi.json
+var openAPIDefinitions []byte
+
+const (
+	// SchemaKubernetesVersion is the version of the Kubernetes API types the bundled definitions are generated from,
+	// the fields are always checked against these definitions whatever the minimum version of Kubernetes is.
+	// The definitions only hold the fields, their types and the required ones, not the enums, patterns and formats.
+	SchemaKubernetesVersion = "1.28"
+
+	// minKubernetesMinor is the oldest minor version of Kubernetes the API versions can be checked for
+	minKubernetesMinor = 16
+	// maxKubernetesMinor is the minor version of SchemaKubernetesVersion
+	maxKubernetesMinor = 28
+)
+
+// introducedIn is the minor version of Kubernetes that started serving the API versions kompose generates,
+// the API versions missing here are served by all the versions from minKubernetesMinor. The API versions kompose
+// generates are all still served by SchemaKubernetesVersion, so their removal isn't checked.
+var introducedIn = map[schema.GroupVersionKind]int{
+	{Group: "autoscaling", Version: "v2", Kind: "HorizontalPodAutoscaler"}: 23,
+	{Group: "batch", Version: "v1", Kind: "CronJob"}:                       21,
+	{Group: "networking.k8s.io", Version: "v1", Kind: "Ingress"}:           19,
+	{Group: "policy", Version: "v1", Kind: "PodDisruptionBudget"}:          21,
+}
+
+// nameValidators are the rules of the API server for the names of the objects, the other objects are DNS-1123 subdomains
+var nameValidators = map[string]apivalidation.ValidateNameFunc{
+	"Namespace": apivalidation.ValidateNamespaceName,
+	"Service":   apivalidation.NameIsDNS1035Label,
+}
+
+// maxCronJobNameLength leaves room for the suffix of the names of the jobs of a CronJob
+const maxCronJobNameLength = 52
+
+// podTemplatePaths are the paths of the pod templates in the workloads, by kind
+var podTemplatePaths = map[string][]string{
+	"CronJob": {"spec", "jobTemplate", "spec", "template"},
+}
+
+// Error is a validation failure of an object generated for a service
+type Error struct {
+	Service string
+	Kind    string
+	Name    string
+	Err     *field.Error
+}
+
+func (e Error) Error() string {
+	return fmt.Sprintf("%s %q: %s", e.Kind, e.Name, e.Err.Error())
+}
+
+// property is a schema of the OpenAPI definitions
+type property struct {
+	Type                 string               `json:"type"`
+	Ref                  string               `json:"$ref"`
+	Items                *property            `json:"items"`
+	AdditionalProperties *property            `json:"additionalProperties"`
+	Properties           map[string]*property `json:"properties"`
+	Required             []string             `json:"required"`
+	IntOrString          bool                 `json:"x-kubernetes-int-or-string"`
+	GroupVersionKind     []struct {
+		Group   string `json:"group"`
+		Version string `json:"version"`
+		Kind    string `json:"kind"`
+	} `json:"x-kubernetes-group-version-kind"`
+}
+
+// validator validates the objects, minor is the oldest minor version of Kubernetes that must serve their API versions
+type validator struct {
+	minor       int
+	definitions map[string]*property
+	kinds       map[schema.GroupVersionKind]*property
+}
+
+// ParseKubernetesVersion returns the minor version of a Kubernetes version like 1.28, v1.28 or 1.28.3
+func ParseKubernetesVersion(version string) (int, error) {
+	parts := strings.Split(strings.TrimPrefix(version, "v"), ".")
+	if len(parts) < 2 || len(parts) > 3 || parts[0] != "1" {
+		return 0, errors.Errorf("invalid Kubernetes version %q, expected a version like %s", version, SchemaKubernetesVersion)
+	}
+	minor, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, errors.Errorf("invalid Kubernetes version %q, expected a version like %s", version, SchemaKubernetesVersion)
+	}
+	if minor < minKubernetesMinor || minor > maxKubernetesMinor {
+		return 0, errors.Errorf("unsupported Kubernetes version %q, the API versions can be checked for Kubernetes 1.%d to 1.%d", version, minKubernetesMinor, maxKubernetesMinor)
+	}
+	return minor, nil
+}
+
+// Validate checks the objects against the bundled definitions, the naming rules and the required values of the API server, and
+// that minKubernetesVersion already serves their API versions. It returns the failures of every object, the objects
+// without definition (custom resources) only have their metadata checked.
+func Validate(objects []runtime.Object, minKubernetesVersion string) ([]Error, error) {
+	minor, err := ParseKubernetesVersion(minKubernetesVersion)
+	if err != nil {
+		return nil, err
+	}
+	v := &validator{minor: minor, kinds: map[schema.GroupVersionKind]*property{}}
+	var definitions struct {
+		Definitions map[string]*property `json:"definitions"`
+	}
+	if err := json.Unmarshal(openAPIDefinitions, &definitions); err != nil {
+		return nil, errors.Wrap(err, "failed to read the OpenAPI definitions")
+	}
+	v.definitions = definitions.Definitions
+	for _, definition := range v.definitions {
+		for _, gvk := range definition.GroupVersionKind {
+			v.kinds[schema.GroupVersionKind{Group: gvk.Group, Version: gvk.Version, Kind: gvk.Kind}] = definition
+		}
+	}
+
+	var result []Error
+	for _, obj := range objects {
+		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to convert the object")
+		}
+		u := &unstructured.Unstructured{Object: content}
+		service := u.GetLabels()["io.kompose.service"]
+		if service == "" {
+			service = u.GetName()
+		}
+		for _, err := range v.validate(u) {
+			result = append(result, Error{Service: service, Kind: u.GetKind(), Name: u.GetName(), Err: err})
+		}
+	}
+	return result, nil
+}
+
+// validate returns the failures of an object
+func (v *validator) validate(u *unstructured.Unstructured) field.ErrorList {
+	var allErrs field.ErrorList
+	gvk := u.GroupVersionKind()
+	if introduced, ok := introducedIn[gvk]; ok && v.minor < introduced {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("apiVersion"), u.GetAPIVersion(),
+			fmt.Sprintf("%s %s is served from Kubernetes 1.%d, not by Kubernetes 1.%d", u.GetAPIVersion(), gvk.Kind, introduced, v.minor)))
+	}
+	if definition, ok := v.kinds[gvk]; ok {
+		allErrs = append(allErrs, v.validateSchema(u.Object, definition, nil)...)
+	}
+
+	allErrs = append(allErrs, validateObjectMeta(u.Object, gvk.Kind, field.NewPath("metadata"))...)
+	if selector, ok, _ := unstructured.NestedStringMap(u.Object, "spec", "selector", "matchLabels"); ok {
+		allErrs = append(allErrs, metav1validation.ValidateLabels(selector, field.NewPath("spec", "selector", "matchLabels"))...)
+	}
+
+	switch gvk.Kind {
+	case "CronJob":
+		if len(u.GetName()) > maxCronJobNameLength {
+			allErrs = append(allErrs, field.TooLongMaxLength(field.NewPath("metadata", "name"), u.GetName(), maxCronJobNameLength))
+		}
+		allErrs = append(allErrs, requiredStrings(u.Object, nil, []string{"spec", "schedule"})...)
+	case "HorizontalPodAutoscaler":
+		allErrs = append(allErrs, requiredStrings(u.Object, nil, []string{"spec", "scaleTargetRef", "kind"}, []string{"spec", "scaleTargetRef", "name"})...)
+		if maxReplicas, _, _ := unstructured.NestedInt64(u.Object, "spec", "maxReplicas"); maxReplicas < 1 {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "maxReplicas"), maxReplicas, "must be greater than or equal to 1"))
+		}
+	case "Service":
+		allErrs = append(allErrs, validateServicePorts(u.Object)...)
+	case "PersistentVolumeClaim":
+		allErrs = append(allErrs, validateStorageRequest(u.Object, field.NewPath("spec"))...)
+	case "StatefulSet":
+		templates, _, _ := unstructured.NestedSlice(u.Object, "spec", "volumeClaimTemplates")
+		for i, template := range templates {
+			if template, ok := template.(map[string]interface{}); ok {
+				allErrs = append(allErrs, validateStorageRequest(template, field.NewPath("spec", "volumeClaimTemplates").Index(i).Child("spec"))...)
+			}
+		}
+	}
+
+	if gvk.Kind == "Pod" {
+		allErrs = append(allErrs, validatePodSpec(u.Object, nil)...)
+		return allErrs
+	}
+	templatePath, ok := podTemplatePaths[gvk.Kind]
+	if !ok {
+		templatePath = []string{"spec", "template"}
+	}
+	if template, ok, _ := unstructured.NestedMap(u.Object, templatePath...); ok {
+		path := field.NewPath(templatePath[0], templatePath[1:]...)
+		allErrs = append(allErrs, validateObjectMeta(template, "", path.Child("metadata"))...)
+		allErrs = append(allErrs, validatePodSpec(template, path)...)
+	}
+	return allErrs
+}
+
+// validateSchema returns the failures of a value against its OpenAPI schema
+func (v *validator) validateSchema(value interface{}, p *property, path *field.Path) field.ErrorList {
+	if p.Ref != "" {
+		p = v.definitions[strings.TrimPrefix(p.Ref, "#/definitions/")]
+	}
+	if value == nil || p == nil {
+		return nil
+	}
+	if p.IntOrString {
+		switch value.(type) {
+		case string, int64, float64:
+			return nil
+		}
+		return field.ErrorList{field.TypeInvalid(path, value, "must be an integer or a string")}
+	}
+
+	var allErrs field.ErrorList
+	switch p.Type {
+	case "object":
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return field.ErrorList{field.TypeInvalid(path, value, "must be an object")}
+		}
+		for _, name := range p.Required {
+			if _, ok := m[name]; !ok && !(path == nil && name == "status") {
+				allErrs = append(allErrs, field.Required(path.Child(name), ""))
+			}
+		}
+		for _, name := range sortedKeys(m) {
+			item := m[name]
+			// The API server ignores the status of the created objects
+			if path == nil && name == "status" {
+				continue
+			}
+			switch {
+			case p.Properties[name] != nil:
+				allErrs = append(allErrs, v.validateSchema(item, p.Properties[name], path.Child(name))...)
+			case p.AdditionalProperties != nil:
+				allErrs = append(allErrs, v.validateSchema(item, p.AdditionalProperties, path.Key(name))...)
+			case len(p.Properties) > 0:
+				allErrs = append(allErrs, field.Forbidden(path.Child(name), "unknown field"))
+			}
+		}
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			return field.ErrorList{field.TypeInvalid(path, value, "must be an array")}
+		}
+		for i, item := range items {
+			allErrs = append(allErrs, v.validateSchema(item, p.Items, path.Index(i))...)
+		}
+	case "string":
+		if _, ok := value.(string); !ok {
+			allErrs = append(allErrs, field.TypeInvalid(path, value, "must be a string"))
+		}
+	case "integer":
+		switch n := value.(type) {
+		case int64:
+		case float64:
+			if n != float64(int64(n)) {
+				allErrs = append(allErrs, field.TypeInvalid(path, value, "must be an integer"))
+			}
+		default:
+			allErrs = append(allErrs, field.TypeInvalid(path, value, "must be an integer"))
+		}
+	case "number":
+		switch value.(type) {
+		case int64, float64:
+		default:
+			allErrs = append(allErrs, field.TypeInvalid(path, value, "must be a number"))
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			allErrs = append(allErrs, field.TypeInvalid(path, value, "must be a boolean"))
+		}
+	}
+	return allErrs
+}
+
+// sortedKeys returns the keys of m in order, for the failures to be reported in the same order every time
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// validateObjectMeta returns the failures of the name, the namespace, the labels and the annotations of an object,
+// the name is not checked when kind is empty
+func validateObjectMeta(object map[string]interface{}, kind string, path *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if kind != "" {
+		validateName, ok := nameValidators[kind]
+		if !ok {
+			validateName = apivalidation.NameIsDNSSubdomain
+		}
+		name, _, _ := unstructured.NestedString(object, "metadata", "name")
+		if name == "" {
+			allErrs = append(allErrs, field.Required(path.Child("name"), ""))
+		}
+		for _, msg := range validateName(name, false) {
+			if name != "" {
+				allErrs = append(allErrs, field.Invalid(path.Child("name"), name, msg))
+			}
+		}
+	}
+	if namespace, _, _ := unstructured.NestedString(object, "metadata", "namespace"); namespace != "" {
+		for _, msg := range apivalidation.ValidateNamespaceName(namespace, false) {
+			allErrs = append(allErrs, field.Invalid(path.Child("namespace"), namespace, msg))
+		}
+	}
+	labels, _, _ := unstructured.NestedStringMap(object, "metadata", "labels")
+	allErrs = append(allErrs, metav1validation.ValidateLabels(labels, path.Child("labels"))...)
+	annotations, _, _ := unstructured.NestedStringMap(object, "metadata", "annotations")
+	allErrs = append(allErrs, apivalidation.ValidateAnnotations(annotations, path.Child("annotations"))...)
+	return allErrs
+}
+
+// validatePodSpec returns the failures of the containers of the pod template at path
+func validatePodSpec(template map[string]interface{}, path *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	for _, kind := range []string{"initContainers", "containers"} {
+		containers, _, _ := unstructured.NestedSlice(template, "spec", kind)
+		for i, container := range containers {
+			container, ok := container.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			containerPath := path.Child("spec", kind).Index(i)
+			name, _, _ := unstructured.NestedString(container, "name")
+			for _, msg := range validation.IsDNS1123Label(name) {
+				allErrs = append(allErrs, field.Invalid(containerPath.Child("name"), name, msg))
+			}
+			allErrs = append(allErrs, requiredStrings(container, containerPath, []string{"image"})...)
+			ports, _, _ := unstructured.NestedSlice(container, "ports")
+			for j, port := range ports {
+				if port, ok := port.(map[string]interface{}); ok {
+					allErrs = append(allErrs, validatePortName(port, containerPath.Child("ports").Index(j), validation.IsValidPortName)...)
+				}
+			}
+			allErrs = append(allErrs, validateResources(container, containerPath.Child("resources"))...)
+		}
+	}
+	return allErrs
+}
+
+// requiredStrings returns the failures of the string fields of object that the API server requires not to be empty,
+// the definitions only check that the required fields are set
+func requiredStrings(object map[string]interface{}, path *field.Path, fields ...[]string) field.ErrorList {
+	var allErrs field.ErrorList
+	for _, f := range fields {
+		if value, _, _ := unstructured.NestedString(object, f...); strings.TrimSpace(value) == "" {
+			fieldPath := path
+			for _, name := range f {
+				fieldPath = fieldPath.Child(name)
+			}
+			allErrs = append(allErrs, field.Required(fieldPath, ""))
+		}
+	}
+	return allErrs
+}
+
+// validateServicePorts returns the failures of the ports of a Service, they need names when there are several
+func validateServicePorts(service map[string]interface{}) field.ErrorList {
+	var allErrs field.ErrorList
+	ports, _, _ := unstructured.NestedSlice(service, "spec", "ports")
+	for i, port := range ports {
+		port, ok := port.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		path := field.NewPath("spec", "ports").Index(i)
+		if name, _, _ := unstructured.NestedString(port, "name"); name == "" && len(ports) > 1 {
+			allErrs = append(allErrs, field.Required(path.Child("name"), "the ports of a Service with several ports must be named"))
+		}
+		number, _, _ := unstructured.NestedInt64(port, "port")
+		for _, msg := range validation.IsValidPortNum(int(number)) {
+			allErrs = append(allErrs, field.Invalid(path.Child("port"), number, msg))
+		}
+		allErrs = append(allErrs, validatePortName(port, path, validation.IsDNS1123Label)...)
+	}
+	return allErrs
+}
+
+// validatePortName returns the failures of the name of a port, if it has one. The ports of the containers
+// have IANA service names, the ports of a Service have DNS-1123 labels.
+func validatePortName(port map[string]interface{}, path *field.Path, validateName func(string) []string) field.ErrorList {
+	var allErrs field.ErrorList
+	if name, _, _ := unstructured.NestedString(port, "name"); name != "" {
+		for _, msg := range validateName(name) {
+			allErrs = append(allErrs, field.Invalid(path.Child("name"), name, msg))
+		}
+	}
+	return allErrs
+}
+
+// validateResources returns the failures of the resources of a container, the requests can't exceed the limits
+func validateResources(container map[string]interface{}, path *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	requests, _, _ := unstructured.NestedStringMap(container, "resources", "requests")
+	limits, _, _ := unstructured.NestedStringMap(container, "resources", "limits")
+	for _, name := range sortedKeys(requests) {
+		value := requests[name]
+		request, err := resource.ParseQuantity(value)
+		if err != nil {
+			allErrs = append(allErrs, field.Invalid(path.Child("requests").Key(name), value, err.Error()))
+			continue
+		}
+		if limitValue, ok := limits[name]; ok {
+			if limit, err := resource.ParseQuantity(limitValue); err == nil && request.Cmp(limit) > 0 {
+				allErrs = append(allErrs, field.Invalid(path.Child("requests").Key(name), value, fmt.Sprintf("must be less than or equal to %s limit of %s", name, limitValue)))
+			}
+		}
+	}
+	return allErrs
+}
+
+// validateStorageRequest returns the failures of the storage requested by the spec of a PersistentVolumeClaim. A
+// quantity like 1.5 parses as 1500m, but the storage is a positive whole number of bytes for the quotas.
+func validateStorageRequest(claim map[string]interface{}, path *field.Path) field.ErrorList {
+	path = path.Child("resources", "requests").Key("storage")
+	value, ok, _ := unstructured.NestedString(claim, "spec", "resources", "requests", "storage")
+	if !ok {
+		return nil
+	}
+	storage, err := resource.ParseQuantity(value)
+	if err != nil {
+		return field.ErrorList{field.Invalid(path, value, err.Error())}
+	}
+	if storage.Sign() <= 0 {
+		return field.ErrorList{field.Invalid(path, value, "must be greater than zero")}
+	}
+	if storage.MilliValue()%1000 != 0 {
+		return field.ErrorList{field.Invalid(path, value, "must be a whole number of bytes")}
+	}
+	return nil
+}
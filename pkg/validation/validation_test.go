@@ -0,0 +1,196 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validation
+
+import (
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func newDeployment(name string, labels map[string]string, container corev1.Container) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		TypeMeta:   metav1.TypeMeta{Kind: "Deployment", APIVersion: "apps/v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+		Spec: appsv1.DeploymentSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec:       corev1.PodSpec{Containers: []corev1.Container{container}},
+			},
+		},
+	}
+}
+
+func newPersistentVolumeClaim(name, storage string) *corev1.PersistentVolumeClaim {
+	return &corev1.PersistentVolumeClaim{
+		TypeMeta:   metav1.TypeMeta{Kind: "PersistentVolumeClaim", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(storage)},
+			},
+		},
+	}
+}
+
+func TestValidate(t *testing.T) {
+	labels := map[string]string{"io.kompose.service": "web"}
+	web := corev1.Container{Name: "web", Image: "nginx", Ports: []corev1.ContainerPort{{Name: "http", ContainerPort: 80}}}
+	cronJob := &batchv1.CronJob{
+		TypeMeta:   metav1.TypeMeta{Kind: "CronJob", APIVersion: "batch/v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: "backup"},
+		Spec:       batchv1.CronJobSpec{Schedule: "0 3 * * *"},
+	}
+	service := &corev1.Service{
+		TypeMeta:   metav1.TypeMeta{Kind: "Service", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: "front_end"},
+		Spec:       corev1.ServiceSpec{Ports: []corev1.ServicePort{{Name: "8080", Port: 8080}, {Port: 8443}}},
+	}
+	hpa := &autoscalingv2.HorizontalPodAutoscaler{
+		TypeMeta:   metav1.TypeMeta{Kind: "HorizontalPodAutoscaler", APIVersion: "autoscaling/v2"},
+		ObjectMeta: metav1.ObjectMeta{Name: "web"},
+		Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{Kind: "Deployment", Name: "web", APIVersion: "apps/v1"},
+			MaxReplicas:    3,
+		},
+	}
+	rollout := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "argoproj.io/v1alpha1",
+		"kind":       "Rollout",
+		"metadata":   map[string]interface{}{"name": "web"},
+		"spec":       map[string]interface{}{"anything": true},
+	}}
+	unknownField := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]interface{}{"name": "web-env"},
+		"data":       map[string]interface{}{"KEY": int64(1)},
+		"spec":       map[string]interface{}{},
+	}}
+
+	testCases := map[string]struct {
+		object               runtime.Object
+		minKubernetesVersion string
+		expected             []string
+	}{
+		"valid deployment":     {newDeployment("web", labels, web), SchemaKubernetesVersion, nil},
+		"long deployment name": {newDeployment(strings.Repeat("a", 64), labels, web), SchemaKubernetesVersion, nil},
+		"invalid label value": {
+			newDeployment("web", map[string]string{"io.kompose.service": "web", "tier": "front end"}, web),
+			SchemaKubernetesVersion,
+			[]string{`Deployment "web": metadata.labels: Invalid value: "front end"`, `spec.selector.matchLabels: Invalid value: "front end"`, `spec.template.metadata.labels: Invalid value: "front end"`},
+		},
+		"invalid container name": {
+			newDeployment("web", labels, corev1.Container{Name: "appFoo", Image: "nginx"}),
+			SchemaKubernetesVersion,
+			[]string{`spec.template.spec.containers[0].name: Invalid value: "appFoo"`},
+		},
+		"requests over limits": {
+			newDeployment("web", labels, corev1.Container{Name: "web", Image: "nginx", Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1Gi")},
+				Limits:   corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("512Mi")},
+			}}),
+			SchemaKubernetesVersion,
+			[]string{`spec.template.spec.containers[0].resources.requests[memory]: Invalid value: "1Gi": must be less than or equal to memory limit`},
+		},
+		"missing image": {
+			newDeployment("web", labels, corev1.Container{Name: "web", Image: " "}),
+			SchemaKubernetesVersion,
+			[]string{`spec.template.spec.containers[0].image: Required value`},
+		},
+		"valid storage":      {newPersistentVolumeClaim("data", "1Gi"), SchemaKubernetesVersion, nil},
+		"fractional storage": {newPersistentVolumeClaim("data", "1.5"), SchemaKubernetesVersion, []string{`spec.resources.requests[storage]: Invalid value: "1500m": must be a whole number of bytes`}},
+		"zero storage":       {newPersistentVolumeClaim("data", "0"), SchemaKubernetesVersion, []string{`spec.resources.requests[storage]: Invalid value: "0": must be greater than zero`}},
+		"invalid service": {
+			service,
+			SchemaKubernetesVersion,
+			[]string{`Service "front_end": metadata.name: Invalid value: "front_end"`, `spec.ports[1].name: Required value`},
+		},
+		"invalid service port": {
+			&corev1.Service{TypeMeta: service.TypeMeta, ObjectMeta: metav1.ObjectMeta{Name: "web"}, Spec: corev1.ServiceSpec{Ports: []corev1.ServicePort{{Port: 0}}}},
+			SchemaKubernetesVersion,
+			[]string{`spec.ports[0].port: Invalid value: 0: must be between 1 and 65535`},
+		},
+		"valid hpa": {hpa, SchemaKubernetesVersion, nil},
+		"invalid hpa": {
+			&autoscalingv2.HorizontalPodAutoscaler{TypeMeta: hpa.TypeMeta, ObjectMeta: hpa.ObjectMeta},
+			SchemaKubernetesVersion,
+			[]string{`spec.scaleTargetRef.kind: Required value`, `spec.scaleTargetRef.name: Required value`, `spec.maxReplicas: Invalid value: 0: must be greater than or equal to 1`},
+		},
+		"cronjob served":     {cronJob, "1.21", nil},
+		"cronjob not served": {cronJob, "v1.20.4", []string{`apiVersion: Invalid value: "batch/v1": batch/v1 CronJob is served from Kubernetes 1.21, not by Kubernetes 1.20`}},
+		"long cronjob name": {
+			&batchv1.CronJob{TypeMeta: cronJob.TypeMeta, ObjectMeta: metav1.ObjectMeta{Name: strings.Repeat("a", 53)}},
+			SchemaKubernetesVersion,
+			[]string{"metadata.name: Too long: may not be longer than 52", "spec.schedule: Required value"},
+		},
+		"custom resource": {rollout, SchemaKubernetesVersion, nil},
+		"schema": {
+			unknownField,
+			SchemaKubernetesVersion,
+			[]string{`data[KEY]: Invalid value: 1: must be a string`, `spec: Forbidden: unknown field`},
+		},
+	}
+
+	for name, test := range testCases {
+		failures, err := Validate([]runtime.Object{test.object}, test.minKubernetesVersion)
+		if err != nil {
+			t.Fatalf("Case '%v' for TestValidate fail, Validate failed: %v", name, err)
+		}
+		if len(failures) != len(test.expected) {
+			t.Errorf("Case '%v' for TestValidate fail, Expected %d failures, got %v", name, len(test.expected), failures)
+			continue
+		}
+		for i, failure := range failures {
+			if !strings.Contains(failure.Error(), test.expected[i]) {
+				t.Errorf("Case '%v' for TestValidate fail, Expected '%v' in '%v'", name, test.expected[i], failure)
+			}
+		}
+	}
+}
+
+func TestParseKubernetesVersion(t *testing.T) {
+	testCases := map[string]struct {
+		version  string
+		expected int
+		valid    bool
+	}{
+		"default":     {SchemaKubernetesVersion, 28, true},
+		"prefixed":    {"v1.25", 25, true},
+		"patch":       {"1.21.3", 21, true},
+		"too old":     {"1.15", 0, false},
+		"too new":     {"1.29", 0, false},
+		"not numeric": {"1.x", 0, false},
+		"major only":  {"1", 0, false},
+	}
+
+	for name, test := range testCases {
+		minor, err := ParseKubernetesVersion(test.version)
+		if (err == nil) != test.valid || minor != test.expected {
+			t.Errorf("Case '%v' for TestParseKubernetesVersion fail, Expected %v (valid: %v), got %v (%v)", name, test.expected, test.valid, minor, err)
+		}
+	}
+}
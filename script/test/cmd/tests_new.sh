@@ -78,6 +78,49 @@ dst=$TEMP_DIR/output_dir/
 convert::check_artifacts_generated "kompose -f $KOMPOSE_ROOT/script/test/fixtures/redis-example/docker-compose.yml convert -o $dst -j" "${dst}redis-deployment.json" "${dst}redis-service.json" "${dst}web-deployment.json" "${dst}web-service.json"
 # Behavior with -o <non-existent-dirname>/<filename>
 convert::check_artifacts_generated "kompose -f $KOMPOSE_ROOT/script/test/fixtures/redis-example/docker-compose.yml convert -o $TEMP_DIR/output_dir2/output_file -j" "$TEMP_DIR/output_dir2/output_file"
+# Behavior with --output-format kustomize
+dst=$TEMP_DIR/output_kustomize/
+convert::check_artifacts_generated "kompose -f $KOMPOSE_ROOT/script/test/fixtures/kustomize/compose.yaml -f $KOMPOSE_ROOT/script/test/fixtures/kustomize/compose.prod.yaml convert -o $dst --output-format kustomize" "${dst}base/kustomization.yaml" "${dst}base/web-deployment.yaml" "${dst}overlays/prod/kustomization.yaml" "${dst}overlays/prod/web-deployment-patch.yaml" "${dst}overlays/prod/worker-deployment.yaml"
+# Behavior with -o <archive>
+convert::check_artifacts_generated "kompose -f $KOMPOSE_ROOT/script/test/fixtures/redis-example/docker-compose.yml convert -o $TEMP_DIR/output_archive/manifests.tar.gz" "$TEMP_DIR/output_archive/manifests.tar.gz"
+convert::check_artifacts_generated "kompose -f $KOMPOSE_ROOT/script/test/fixtures/redis-example/docker-compose.yml convert -o $TEMP_DIR/output_archive/chart.zip -c" "$TEMP_DIR/output_archive/chart.zip"
+# Behavior with --numbered-files
+dst=$TEMP_DIR/output_numbered/
+convert::check_artifacts_generated "kompose -f $KOMPOSE_ROOT/script/test/fixtures/redis-example/docker-compose.yml convert -o $dst --numbered-files" "${dst}01-redis-service.yaml" "${dst}02-web-service.yaml" "${dst}03-redis-deployment.yaml" "${dst}04-web-deployment.yaml"
+# Behavior with --layout per-service
+dst=$TEMP_DIR/output_per_service/
+convert::check_artifacts_generated "kompose -f $KOMPOSE_ROOT/script/test/fixtures/redis-example/docker-compose.yml convert -o $dst --layout per-service" "${dst}kustomization.yaml" "${dst}redis/kustomization.yaml" "${dst}redis/redis-deployment.yaml" "${dst}web/kustomization.yaml" "${dst}web/web-service.yaml"
+convert::expect_failure "kompose -f $KOMPOSE_ROOT/script/test/fixtures/redis-example/docker-compose.yml convert -o $TEMP_DIR/out.yaml --layout per-service"
+# Behavior with --validate
+convert::check_artifacts_generated "kompose -f $KOMPOSE_ROOT/script/test/fixtures/redis-example/docker-compose.yml convert -o $TEMP_DIR/output_validate/ --validate" "$TEMP_DIR/output_validate/web-deployment.yaml"
+convert::expect_warning "kompose -f $KOMPOSE_ROOT/script/test/fixtures/single-file-output/docker-compose.yaml convert --stdout --validate" "metadata.name: Invalid value: \"front_end\""
+convert::expect_warning "kompose -f $KOMPOSE_ROOT/script/test/fixtures/cronjob/docker-compose.yaml convert --stdout --validate --min-kube-version 1.20" "batch/v1 CronJob is served from Kubernetes 1.21"
+# Behavior with --merge
+convert::check_artifacts_generated "kompose -f $KOMPOSE_ROOT/script/test/fixtures/redis-example/docker-compose.yml convert -o $TEMP_DIR/output_merge/ --merge" "$TEMP_DIR/output_merge/.kompose-state.json" "$TEMP_DIR/output_merge/web-deployment.yaml"
+# kompose up and kompose down work on the objects of the cluster and write no file
+convert::expect_failure "kompose -f $KOMPOSE_ROOT/script/test/fixtures/redis-example/docker-compose.yml up --stdout"
+convert::expect_failure "kompose -f $KOMPOSE_ROOT/script/test/fixtures/redis-example/docker-compose.yml down -o $TEMP_DIR/output_down/"
+# Behavior of kompose diff with the manifests written above
+convert::expect_cmd_success "kompose -f $KOMPOSE_ROOT/script/test/fixtures/redis-example/docker-compose.yml diff -o $dst"
+convert::expect_failure "kompose -f $KOMPOSE_ROOT/script/test/fixtures/single-file-output/docker-compose.yaml diff -o $dst"
+# Behavior of kompose reverse with the manifests of a fixture
+convert::check_artifacts_generated "kompose -f $KOMPOSE_ROOT/script/test/fixtures/env/output-k8s.yaml reverse -o $TEMP_DIR/reverse-compose.yaml" "$TEMP_DIR/reverse-compose.yaml"
+convert::expect_failure "kompose reverse"
+# Behavior with --input-format
+convert::check_artifacts_generated "kompose -f $KOMPOSE_ROOT/script/test/fixtures/env/output-k8s.yaml convert -o $TEMP_DIR/output_input_format/ --input-format kubernetes" "$TEMP_DIR/output_input_format/namenode-deployment.yaml"
+convert::expect_failure "kompose -f $KOMPOSE_ROOT/script/test/fixtures/redis-example/docker-compose.yml convert --stdout --input-format descriptor"
+# Behavior with --patch
+convert::check_artifacts_generated "kompose -f $KOMPOSE_ROOT/script/test/fixtures/redis-example/docker-compose.yml convert -o $TEMP_DIR/output_patch/ --patch $KOMPOSE_ROOT/script/test/fixtures/patch/patches" "$TEMP_DIR/output_patch/web-deployment.yaml"
+convert::expect_failure "kompose -f $KOMPOSE_ROOT/script/test/fixtures/redis-example/docker-compose.yml convert --stdout --patch $KOMPOSE_ROOT/script/test/fixtures/patch/missing"
+# Behavior with --plugin
+convert::check_artifacts_generated "kompose -f $KOMPOSE_ROOT/script/test/fixtures/redis-example/docker-compose.yml convert -o $TEMP_DIR/output_plugin/ --plugin $KOMPOSE_ROOT/script/test/fixtures/plugin/image-mirror" "$TEMP_DIR/output_plugin/web-deployment.yaml"
+convert::expect_failure "kompose -f $KOMPOSE_ROOT/script/test/fixtures/redis-example/docker-compose.yml convert --stdout --plugin $KOMPOSE_ROOT/script/test/fixtures/plugin/missing"
+# Behavior with --label and --annotation
+convert::expect_cmd_success "kompose -f $KOMPOSE_ROOT/script/test/fixtures/redis-example/docker-compose.yml convert --stdout --label team=shop --annotation owner=shop@example.com"
+convert::expect_failure "kompose -f $KOMPOSE_ROOT/script/test/fixtures/redis-example/docker-compose.yml convert --stdout --label io.kompose.service=web"
+# The flags of a provider are rejected with the other providers
+convert::expect_failure "kompose --provider openshift -f $KOMPOSE_ROOT/script/test/fixtures/redis-example/docker-compose.yml convert --stdout --expose-mode gateway"
+convert::expect_failure "kompose --provider nomad -f $KOMPOSE_ROOT/script/test/fixtures/redis-example/docker-compose.yml convert --stdout"
 
 #TEST the pvc-request-size command parameter
 convert::check_artifacts_generated "kompose -f $KOMPOSE_ROOT/script/test/fixtures/pvc-request-size/docker-compose.yml convert -o $TEMP_DIR/output_dir2/output-k8s.json -j --pvc-request-size=300Mi" "$TEMP_DIR/output_dir2/output-k8s.json"
@@ -196,6 +239,44 @@ ocp_output="$KOMPOSE_ROOT/script/test/fixtures/statefulset/output-os.yaml"
 convert::expect_success_and_warning "$k8s_cmd" "$k8s_output"
 convert::expect_success "$ocp_cmd" "$ocp_output"
 
+# test cronjob
+k8s_cmd="kompose -f $KOMPOSE_ROOT/script/test/fixtures/cronjob/docker-compose.yaml convert --stdout --with-kompose-annotation=false"
+os_cmd="kompose --provider=openshift -f $KOMPOSE_ROOT/script/test/fixtures/cronjob/docker-compose.yaml convert --stdout --with-kompose-annotation=false"
+k8s_output="$KOMPOSE_ROOT/script/test/fixtures/cronjob/output-k8s.yaml"
+os_output="$KOMPOSE_ROOT/script/test/fixtures/cronjob/output-os.yaml"
+convert::expect_success_and_warning "$k8s_cmd" "$k8s_output"
+convert::expect_success_and_warning "$os_cmd" "$os_output"
+
+# test horizontal pod autoscaler
+k8s_cmd="kompose -f $KOMPOSE_ROOT/script/test/fixtures/hpa/docker-compose.yaml convert --stdout --with-kompose-annotation=false"
+k8s_output="$KOMPOSE_ROOT/script/test/fixtures/hpa/output-k8s.yaml"
+convert::expect_success "$k8s_cmd" "$k8s_output"
+
+# test pod disruption budgets
+k8s_cmd="kompose -f $KOMPOSE_ROOT/script/test/fixtures/pdb/docker-compose.yaml convert --stdout --with-kompose-annotation=false --generate-pod-disruption-budgets"
+k8s_output="$KOMPOSE_ROOT/script/test/fixtures/pdb/output-k8s.yaml"
+convert::expect_success_and_warning "$k8s_cmd" "$k8s_output"
+
+# test gateway api httproutes
+k8s_cmd="kompose -f $KOMPOSE_ROOT/script/test/fixtures/gateway/docker-compose.yaml convert --stdout --with-kompose-annotation=false --expose-mode gateway --gateway infra/public"
+k8s_output="$KOMPOSE_ROOT/script/test/fixtures/gateway/output-k8s.yaml"
+convert::expect_success "$k8s_cmd" "$k8s_output"
+
+# test argo rollouts controller
+k8s_cmd="kompose -f $KOMPOSE_ROOT/script/test/fixtures/rollout/docker-compose.yaml convert --stdout --with-kompose-annotation=false --controller rollout"
+k8s_output="$KOMPOSE_ROOT/script/test/fixtures/rollout/output-k8s.yaml"
+convert::expect_success_and_warning "$k8s_cmd" "$k8s_output"
+
+# test knative provider
+knative_cmd="kompose --provider knative -f $KOMPOSE_ROOT/script/test/fixtures/knative/docker-compose.yaml convert --stdout --with-kompose-annotation=false"
+knative_output="$KOMPOSE_ROOT/script/test/fixtures/knative/output-knative.yaml"
+convert::expect_success_and_warning "$knative_cmd" "$knative_output"
+
+# test openshift template output
+os_cmd="kompose --provider=openshift -f $KOMPOSE_ROOT/script/test/fixtures/openshift-template/docker-compose.yaml convert --stdout --with-kompose-annotation=false --output-format template"
+os_output="$KOMPOSE_ROOT/script/test/fixtures/openshift-template/output-os-template.yaml"
+convert::expect_success "$os_cmd" "$os_output"
+
 # test specifying volume type using service label
 k8s_cmd="kompose -f $KOMPOSE_ROOT/script/test/fixtures/multiple-type-volumes/docker-compose.yaml convert --stdout --with-kompose-annotation=false"
 os_cmd="kompose  --provider=openshift -f  $KOMPOSE_ROOT/script/test/fixtures/multiple-type-volumes/docker-compose.yaml  convert --stdout --with-kompose-annotation=false"
@@ -217,6 +298,14 @@ k8s_cmd="kompose -f $KOMPOSE_ROOT/script/test/fixtures/single-file-output/docker
 k8s_output="$KOMPOSE_ROOT/script/test/fixtures/single-file-output/output-k8s.yaml"
 convert::expect_success_and_warning "$k8s_cmd" "$k8s_output"
 
+# Test single file output as a v1 List
+k8s_cmd="kompose -f $KOMPOSE_ROOT/script/test/fixtures/single-file-output/docker-compose.yaml convert --stdout --with-kompose-annotation=false --output-format yaml-list"
+k8s_output="$KOMPOSE_ROOT/script/test/fixtures/single-file-output/output-k8s-list.yaml"
+convert::expect_success_and_warning "$k8s_cmd" "$k8s_output"
+k8s_cmd="kompose -f $KOMPOSE_ROOT/script/test/fixtures/single-file-output/docker-compose.yaml convert --stdout --with-kompose-annotation=false -j"
+k8s_output="$KOMPOSE_ROOT/script/test/fixtures/single-file-output/output-k8s-list.json"
+convert::expect_success_and_warning "$k8s_cmd" "$k8s_output"
+
 # Test host port and protocol feature
 k8s_cmd="kompose -f $KOMPOSE_ROOT/script/test/fixtures/host-port-protocol/docker-compose.yaml convert --stdout --with-kompose-annotation=false"
 os_cmd="kompose --provider=openshift -f $KOMPOSE_ROOT/script/test/fixtures/host-port-protocol/docker-compose.yaml convert --stdout --with-kompose-annotation=false"